@@ -0,0 +1,63 @@
+package app
+
+import (
+	"database/sql"
+	"metaedu-marketplace/config"
+	"metaedu-marketplace/db"
+
+	"github.com/go-redis/redis"
+)
+
+// App holds the clients every subcommand starts from, the rest is created by the command that needs it
+type App struct {
+	Config       *config.Config
+	DB           *sql.DB
+	Repositories *Repositories
+
+	redisClient *redis.Client
+}
+
+func New(cfg *config.Config) (*App, error) {
+	dbClient, err := config.CreateDBClient(cfg.Postgres)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{Config: cfg, DB: dbClient, Repositories: NewRepositories(dbClient)}, nil
+}
+
+// Redis connects on first use, the migrate and user commands never need it
+func (a *App) Redis() *redis.Client {
+	if a.redisClient == nil {
+		a.redisClient = config.CreateRedisClient(a.Config.Redis)
+	}
+
+	return a.redisClient
+}
+
+// Close releases Redis before Postgres, nothing may use the app afterwards
+func (a *App) Close() error {
+	var err error
+
+	if a.redisClient != nil {
+		err = a.redisClient.Close()
+	}
+
+	if dbErr := a.DB.Close(); dbErr != nil && err == nil {
+		err = dbErr
+	}
+
+	return err
+}
+
+// CheckSchema fails when the database is not at the version embedded in the binary
+func (a *App) CheckSchema() error {
+	migrator, err := db.NewMigrator(a.DB)
+
+	if err != nil {
+		return err
+	}
+
+	return migrator.CheckCurrent()
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+type stopHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// Lifecycle waits for SIGINT or SIGTERM and stops what the command started, last started first
+type Lifecycle struct {
+	shutdownTimeout time.Duration
+	drainDelay      time.Duration
+	ready           int32
+	hooks           []stopHook
+}
+
+func NewLifecycle(shutdownTimeout time.Duration, drainDelay time.Duration) *Lifecycle {
+	return &Lifecycle{shutdownTimeout: shutdownTimeout, drainDelay: drainDelay}
+}
+
+// OnStop registers a component in start order
+func (l *Lifecycle) OnStop(name string, stop func(ctx context.Context) error) {
+	l.hooks = append(l.hooks, stopHook{name, stop})
+}
+
+func (l *Lifecycle) SetReady(ready bool) {
+	var value int32
+
+	if ready {
+		value = 1
+	}
+
+	atomic.StoreInt32(&l.ready, value)
+}
+
+func (l *Lifecycle) IsReady() bool {
+	return atomic.LoadInt32(&l.ready) == 1
+}
+
+// Wait blocks until a signal arrives or a component fails, then shuts down within the shutdown timeout
+func (l *Lifecycle) Wait(failed <-chan error) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var err error
+
+	select {
+	case received := <-signals:
+		fmt.Println("Received ", received, ", shutting down...")
+	case err = <-failed:
+		fmt.Println("Shutting down after error: ", err)
+	}
+
+	return l.Shutdown(err)
+}
+
+// Shutdown flips readiness off, waits the drain delay and runs the stop hooks in reverse order
+func (l *Lifecycle) Shutdown(cause error) error {
+	l.SetReady(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.shutdownTimeout)
+	defer cancel()
+
+	// Nothing is listening anymore when a component failed, so there is nothing to drain
+	if cause == nil && l.drainDelay > 0 {
+		select {
+		case <-time.After(l.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := cause
+
+	for i := len(l.hooks) - 1; i >= 0; i-- {
+		hook := l.hooks[i]
+
+		fmt.Println("Stopping ", hook.name, "...")
+
+		if stopErr := hook.stop(ctx); stopErr != nil {
+			fmt.Println("Stopping failed, component: ", hook.name, ", error: ", stopErr)
+
+			if err == nil {
+				err = fmt.Errorf("stopping %s: %w", hook.name, stopErr)
+			}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"database/sql"
+	"metaedu-marketplace/repositories"
+)
+
+// Repositories is built once per process and shared by the API, the worker and the admin commands
+type Repositories struct {
+	Audit          *repositories.AuditRepository
+	Collection     *repositories.CollectionRepository
+	Favorite       *repositories.FavoriteRepository
+	FeeSchedule    *repositories.FeeScheduleRepository
+	Follow         *repositories.FollowRepository
+	Fraction       *repositories.FractionRepository
+	FractionBuyout *repositories.FractionBuyoutRepository
+	FractionShare  *repositories.FractionShareRepository
+	MintVoucher    *repositories.MintVoucherRepository
+	Moderation     *repositories.ModerationRepository
+	Notification   *repositories.NotificationRepository
+	Ownership      *repositories.OwnershipRepository
+	PriceHistory   *repositories.PriceHistoryRepository
+	Ranking        *repositories.RankingRepository
+	Rental         *repositories.RentalRepository
+	RentalEvent    *repositories.RentalEventRepository
+	Statistic      *repositories.StatisticRepository
+	Token          *repositories.TokenRepository
+	TokenCategory  *repositories.TokenCategoryRepository
+	Transaction    *repositories.TransactionRepository
+	TransactionFee *repositories.TransactionFeeRepository
+	Trash          *repositories.TrashRepository
+	User           *repositories.UserRepository
+	Version        *repositories.VersionRepository
+	View           *repositories.ViewRepository
+	Watchlist      *repositories.WatchlistRepository
+	Webhook        *repositories.WebhookRepository
+}
+
+func NewRepositories(db *sql.DB) *Repositories {
+	return &Repositories{
+		Audit:          repositories.NewAuditRepository(db),
+		Collection:     repositories.NewCollectionRepository(db),
+		Favorite:       repositories.NewFavoriteRepository(db),
+		FeeSchedule:    repositories.NewFeeScheduleRepository(db),
+		Follow:         repositories.NewFollowRepository(db),
+		Fraction:       repositories.NewFractionRepository(db),
+		FractionBuyout: repositories.NewFractionBuyoutRepository(db),
+		FractionShare:  repositories.NewFractionShareRepository(db),
+		MintVoucher:    repositories.NewMintVoucherRepository(db),
+		Moderation:     repositories.NewModerationRepository(db),
+		Notification:   repositories.NewNotificationRepository(db),
+		Ownership:      repositories.NewOwnershipRepository(db),
+		PriceHistory:   repositories.NewPriceHistoryRepository(db),
+		Ranking:        repositories.NewRankingRepository(db),
+		Rental:         repositories.NewRentalRepository(db),
+		RentalEvent:    repositories.NewRentalEventRepository(db),
+		Statistic:      repositories.NewStatisticRepository(db),
+		Token:          repositories.NewTokenRepository(db),
+		TokenCategory:  repositories.NewTokenCategoryRepository(db),
+		Transaction:    repositories.NewTransactionRepository(db),
+		TransactionFee: repositories.NewTransactionFeeRepository(db),
+		Trash:          repositories.NewTrashRepository(db),
+		User:           repositories.NewUserRepository(db),
+		Version:        repositories.NewVersionRepository(db),
+		View:           repositories.NewViewRepository(db),
+		Watchlist:      repositories.NewWatchlistRepository(db),
+		Webhook:        repositories.NewWebhookRepository(db),
+	}
+}
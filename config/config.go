@@ -0,0 +1,134 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// Config is everything the binary reads from outside, see Load for where each value comes from.
+//
+// Field tags:
+//
+//	env       environment variable, also the name printed in errors
+//	key       name in the YAML/TOML file, fields are nested under their section key
+//	default   value used when no source sets the field
+//	required  commands that refuse to start without the field, * for every command
+//	secret    redacted when printed and also read from files
+//	min       lowest accepted value, a duration like 1s for time.Duration fields
+type Config struct {
+	App         AppConfig         `key:"app"`
+	Server      ServerConfig      `key:"server"`
+	Postgres    PostgresConfig    `key:"postgres"`
+	Redis       RedisConfig       `key:"redis"`
+	JWT         JWTConfig         `key:"jwt"`
+	Web3Storage Web3StorageConfig `key:"web3_storage"`
+	Chain       ChainConfig       `key:"chain"`
+	SMTP        SMTPConfig        `key:"smtp"`
+	Worker      WorkerConfig      `key:"worker"`
+}
+
+type AppConfig struct {
+	Environment             string        `env:"NODE_ENV" key:"environment" default:"development"`
+	URL                     string        `env:"APP_URL" key:"url" required:"serve"`
+	SoftDeleteRetentionDays int           `env:"SOFT_DELETE_RETENTION_DAYS" key:"soft_delete_retention_days" default:"30" min:"1"`
+	ViewWindow              time.Duration `env:"VIEW_WINDOW" key:"view_window" default:"30m" min:"1s"`
+	ShutdownTimeout         time.Duration `env:"SHUTDOWN_TIMEOUT" key:"shutdown_timeout" default:"30s" min:"1s"`
+	// How long a fraction buyout stays open after the last confirmed offer before the highest one settles
+	FractionBuyoutWindow time.Duration `env:"FRACTION_BUYOUT_WINDOW" key:"fraction_buyout_window" default:"24h" min:"1m"`
+}
+
+type ServerConfig struct {
+	Port              string        `env:"PORT" key:"port" required:"serve"`
+	MaxUploadSize     int64         `env:"MAX_UPLOAD_SIZE" key:"max_upload_size" default:"10485760" min:"1"`
+	ReadHeaderTimeout time.Duration `env:"READ_HEADER_TIMEOUT" key:"read_header_timeout" default:"10s" min:"1s"`
+	RequestTimeout    time.Duration `env:"REQUEST_TIMEOUT" key:"request_timeout" default:"15s" min:"1s"`
+	// How long readiness fails before the server stops accepting, so the load balancer moves traffic first
+	ReadinessDrainDelay time.Duration `env:"READINESS_DRAIN_DELAY" key:"readiness_drain_delay" default:"5s" min:"0s"`
+	// Comma separated origins of the web apps allowed to open the event websocket, besides the app url
+	AllowedOrigins string `env:"ALLOWED_ORIGINS" key:"allowed_origins"`
+}
+
+type PostgresConfig struct {
+	Host            string        `env:"POSTGRES_HOST" key:"host" required:"*"`
+	Port            string        `env:"POSTGRES_PORT" key:"port" default:"5432"`
+	Username        string        `env:"POSTGRES_USERNAME" key:"username" required:"*"`
+	Password        string        `env:"POSTGRES_PASSWORD" key:"password" secret:"true"`
+	Database        string        `env:"POSTGRES_DB" key:"database" required:"*"`
+	SSLMode         string        `env:"POSTGRES_SSL_MODE" key:"ssl_mode" default:"disable"`
+	MaxOpenConns    int           `env:"POSTGRES_MAX_OPEN_CONNS" key:"max_open_conns" default:"25" min:"1"`
+	MaxIdleConns    int           `env:"POSTGRES_MAX_IDLE_CONNS" key:"max_idle_conns" default:"5" min:"0"`
+	ConnMaxLifetime time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME" key:"conn_max_lifetime" default:"30m" min:"0s"`
+	ConnectTimeout  time.Duration `env:"POSTGRES_CONNECT_TIMEOUT" key:"connect_timeout" default:"10s" min:"1s"`
+}
+
+type RedisConfig struct {
+	Host         string        `env:"REDIS_HOST" key:"host" required:"serve,worker,reindex"`
+	Port         string        `env:"REDIS_PORT" key:"port" default:"6379"`
+	Password     string        `env:"REDIS_PASSWORD" key:"password" secret:"true"`
+	DB           int           `env:"REDIS_DB" key:"db" default:"0" min:"0"`
+	PoolSize     int           `env:"REDIS_POOL_SIZE" key:"pool_size" default:"10" min:"1"`
+	DialTimeout  time.Duration `env:"REDIS_DIAL_TIMEOUT" key:"dial_timeout" default:"5s" min:"100ms"`
+	ReadTimeout  time.Duration `env:"REDIS_READ_TIMEOUT" key:"read_timeout" default:"3s" min:"100ms"`
+	WriteTimeout time.Duration `env:"REDIS_WRITE_TIMEOUT" key:"write_timeout" default:"3s" min:"100ms"`
+}
+
+type JWTConfig struct {
+	SecretKey            string        `env:"JWT_HMAC_SECRET_KEY" key:"secret_key" required:"serve" secret:"true"`
+	Issuer               string        `env:"JWT_ISSUER" key:"issuer" required:"serve"`
+	TTL                  time.Duration `env:"JWT_TTL" key:"ttl" default:"600h" min:"1m"`
+	EmailVerificationTTL time.Duration `env:"EMAIL_VERIFICATION_TTL" key:"email_verification_ttl" default:"24h" min:"1m"`
+}
+
+type Web3StorageConfig struct {
+	Token string `env:"WEB3_STORAGE_TOKEN" key:"token" required:"serve" secret:"true"`
+}
+
+type ChainConfig struct {
+	ID                         int64         `env:"CHAIN_ID" key:"id" required:"serve"`
+	MarketplaceContractAddress string        `env:"MARKETPLACE_CONTRACT_ADDRESS" key:"marketplace_contract_address" required:"serve"`
+	RPCURL                     string        `env:"RPC_URL" key:"rpc_url" required:"worker"`
+	RPCTimeout                 time.Duration `env:"RPC_TIMEOUT" key:"rpc_timeout" default:"10s" min:"1s"`
+}
+
+type SMTPConfig struct {
+	Host     string `env:"SMTP_HOST" key:"host" required:"serve,worker,reindex"`
+	Port     string `env:"SMTP_PORT" key:"port" default:"25"`
+	Username string `env:"SMTP_USERNAME" key:"username"`
+	Password string `env:"SMTP_PASSWORD" key:"password" secret:"true"`
+	From     string `env:"SMTP_FROM" key:"from" required:"serve,worker,reindex"`
+}
+
+// WorkerConfig is the job schedule, every worker replica campaigns for the lock and only the leader runs the jobs
+type WorkerConfig struct {
+	Concurrency             int           `env:"WORKER_CONCURRENCY" key:"concurrency" default:"8" min:"1"`
+	LeaderElectionInterval  time.Duration `env:"WORKER_LEADER_ELECTION_INTERVAL" key:"leader_election_interval" default:"10s" min:"1s"`
+	ConfirmationInterval    time.Duration `env:"WORKER_CONFIRMATION_INTERVAL" key:"confirmation_interval" default:"5s" min:"1s"`
+	RentalExpiryInterval    time.Duration `env:"WORKER_RENTAL_EXPIRY_INTERVAL" key:"rental_expiry_interval" default:"1m" min:"1s"`
+	WebhookInterval         time.Duration `env:"WORKER_WEBHOOK_INTERVAL" key:"webhook_interval" default:"30s" min:"1s"`
+	CollectionStatsInterval time.Duration `env:"WORKER_COLLECTION_STATS_INTERVAL" key:"collection_stats_interval" default:"5m" min:"1s"`
+	StatisticsInterval      time.Duration `env:"WORKER_STATISTICS_INTERVAL" key:"statistics_interval" default:"10m" min:"1s"`
+	RankingInterval         time.Duration `env:"WORKER_RANKING_INTERVAL" key:"ranking_interval" default:"1m" min:"1s"`
+	ViewFlushInterval       time.Duration `env:"WORKER_VIEW_FLUSH_INTERVAL" key:"view_flush_interval" default:"1m" min:"1s"`
+	TrendingInterval        time.Duration `env:"WORKER_TRENDING_INTERVAL" key:"trending_interval" default:"5m" min:"1s"`
+	PurgeInterval           time.Duration `env:"WORKER_PURGE_INTERVAL" key:"purge_interval" default:"1h" min:"1s"`
+	BuyoutInterval          time.Duration `env:"WORKER_BUYOUT_INTERVAL" key:"buyout_interval" default:"1m" min:"1s"`
+	EmailInterval           time.Duration `env:"WORKER_EMAIL_INTERVAL" key:"email_interval" default:"30s" min:"1s"`
+}
+
+// AllowedOriginList is the app url followed by the allowed origins
+func (c Config) AllowedOriginList() []string {
+	origins := []string{c.App.URL}
+
+	for _, origin := range strings.Split(c.Server.AllowedOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+
+	return origins
+}
+
+// SoftDeleteRetention is how long deleted rows are kept before the worker purges them
+func (c AppConfig) SoftDeleteRetention() time.Duration {
+	return time.Duration(c.SoftDeleteRetentionDays) * 24 * time.Hour
+}
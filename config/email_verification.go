@@ -0,0 +1,12 @@
+package config
+
+import (
+	"fmt"
+	"metaedu-marketplace/utils"
+)
+
+func CreateEmailVerifier(config JWTConfig, appUrl string) *utils.EmailVerifier {
+	fmt.Println("Initialize email verifier...")
+
+	return utils.NewEmailVerifier(config.SecretKey, appUrl, config.EmailVerificationTTL)
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const DefaultSecretsDir = "/run/secrets"
+
+// Source tells Load where to look, tests fill it instead of touching the process environment
+type Source struct {
+	// YAML or TOML file picked by extension, optional
+	File string
+	// Directory with one file per secret named after the lowercase variable, like Docker secrets
+	SecretsDir string
+	// Defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+}
+
+// Errors collects every problem so a broken deployment is fixed in one go
+type Errors []string
+
+func (e Errors) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+type visitFunc func(section string, field reflect.StructField, value reflect.Value)
+
+func visit(config *Config, fn visitFunc) {
+	sections := reflect.ValueOf(config).Elem()
+
+	for i := 0; i < sections.NumField(); i++ {
+		sectionKey := sections.Type().Field(i).Tag.Get("key")
+		section := sections.Field(i)
+
+		for j := 0; j < section.NumField(); j++ {
+			fn(sectionKey, section.Type().Field(j), section.Field(j))
+		}
+	}
+}
+
+func setValue(value reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+
+		value.SetInt(number)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(flag)
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+func readFile(path string) (map[string]map[string]any, error) {
+	content, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]map[string]any{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	default:
+		return nil, fmt.Errorf("config file %s must be .yaml, .yml or .toml", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// Load builds the config from, lowest first: tag defaults, the config file, secret files and the environment.
+// A secret is read from the file named by VARIABLE_FILE or from the secrets directory.
+func Load(source Source) (*Config, error) {
+	config := &Config{}
+
+	var problems Errors
+
+	lookupEnv := source.LookupEnv
+
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	var fileValues map[string]map[string]any
+
+	if source.File != "" {
+		var err error
+		fileValues, err = readFile(source.File)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	visit(config, func(section string, field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("env")
+
+		set := func(raw string, origin string) {
+			// An empty number left over from .env.example counts as unset
+			if strings.TrimSpace(raw) == "" && value.Kind() != reflect.String {
+				return
+			}
+
+			if err := setValue(value, raw); err != nil {
+				problems = append(problems, fmt.Sprintf("%s from %s: %v", name, origin, err))
+			}
+		}
+
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			set(defaultValue, "default")
+		}
+
+		if fileValue, ok := fileValues[section][field.Tag.Get("key")]; ok {
+			set(fmt.Sprint(fileValue), source.File)
+		}
+
+		if field.Tag.Get("secret") == "true" && source.SecretsDir != "" {
+			path := filepath.Join(source.SecretsDir, strings.ToLower(name))
+
+			if content, err := os.ReadFile(path); err == nil {
+				set(string(content), path)
+			}
+		}
+
+		if path, ok := lookupEnv(name + "_FILE"); ok && path != "" {
+			content, err := os.ReadFile(path)
+
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s_FILE: %v", name, err))
+			} else {
+				set(string(content), path)
+			}
+		}
+
+		if raw, ok := lookupEnv(name); ok {
+			set(raw, "environment")
+		}
+	})
+
+	if len(problems) > 0 {
+		return config, problems
+	}
+
+	return config, nil
+}
+
+// LoadFor loads the config and validates it for the command, parse and validation problems are reported together
+func LoadFor(source Source, command string) (*Config, error) {
+	config, err := Load(source)
+
+	if config == nil {
+		return nil, err
+	}
+
+	var problems Errors
+
+	if loadProblems, ok := err.(Errors); ok {
+		problems = append(problems, loadProblems...)
+	}
+
+	if validateProblems, ok := config.Validate(command).(Errors); ok {
+		problems = append(problems, validateProblems...)
+	}
+
+	if len(problems) > 0 {
+		return config, problems
+	}
+
+	return config, nil
+}
+
+// parseMinimum reads durations like 1s for time.Duration fields, a bare number would mean nanoseconds
+func parseMinimum(value reflect.Value, min string) (int64, error) {
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(min)
+		return int64(duration), err
+	}
+
+	return strconv.ParseInt(min, 10, 64)
+}
+
+func isRequired(required string, command string) bool {
+	for _, name := range strings.Split(required, ",") {
+		if name == "*" || name == command {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks what the command needs and reports every missing or out of range value at once
+func (c *Config) Validate(command string) error {
+	var problems Errors
+
+	visit(c, func(section string, field reflect.StructField, value reflect.Value) {
+		name := field.Tag.Get("env")
+
+		if isRequired(field.Tag.Get("required"), command) && value.IsZero() {
+			problems = append(problems, fmt.Sprintf("No %s - set the %s environment var or %s.%s in the config file", name, name, section, field.Tag.Get("key")))
+			return
+		}
+
+		if min, ok := field.Tag.Lookup("min"); ok {
+			minimum, err := parseMinimum(value, min)
+
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s has an invalid min tag %q: %v", name, min, err))
+			} else if value.Int() < minimum {
+				problems = append(problems, fmt.Sprintf("%s must be at least %s", name, min))
+			}
+		}
+	})
+
+	if len(problems) > 0 {
+		return problems
+	}
+
+	return nil
+}
+
+// String prints every value by variable name with the secrets redacted
+func (c Config) String() string {
+	var builder strings.Builder
+
+	visit(&c, func(section string, field reflect.StructField, value reflect.Value) {
+		display := fmt.Sprint(value.Interface())
+
+		if field.Tag.Get("secret") == "true" && display != "" {
+			display = "[redacted]"
+		}
+
+		fmt.Fprintf(&builder, "%s=%s\n", field.Tag.Get("env"), display)
+	})
+
+	return builder.String()
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func lookupEnv(variables map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := variables[key]
+		return value, ok
+	}
+}
+
+// Everything serve and the worker require, so tests only set what they check
+func baseEnv() map[string]string {
+	return map[string]string{
+		"APP_URL":                      "http://localhost:8000",
+		"PORT":                         "8000",
+		"POSTGRES_HOST":                "localhost",
+		"POSTGRES_USERNAME":            "admin",
+		"POSTGRES_DB":                  "metaedu",
+		"REDIS_HOST":                   "localhost",
+		"JWT_HMAC_SECRET_KEY":          "secret",
+		"JWT_ISSUER":                   "metaedu",
+		"WEB3_STORAGE_TOKEN":           "token",
+		"CHAIN_ID":                     "1",
+		"MARKETPLACE_CONTRACT_ADDRESS": "0x0",
+		"RPC_URL":                      "http://localhost:8545",
+		"SMTP_HOST":                    "localhost",
+		"SMTP_FROM":                    "no-reply@metaedu.local",
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		check   func(config *Config) bool
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+			check: func(c *Config) bool {
+				return c.Postgres.Port == "5432" && c.App.ViewWindow == 30*time.Minute && c.Worker.Concurrency == 8
+			},
+		},
+		{
+			name: "environment overrides defaults",
+			env:  map[string]string{"POSTGRES_PORT": "6543", "VIEW_WINDOW": "5m", "REDIS_DB": "2"},
+			check: func(c *Config) bool {
+				return c.Postgres.Port == "6543" && c.App.ViewWindow == 5*time.Minute && c.Redis.DB == 2
+			},
+		},
+		{
+			name:  "empty number counts as unset",
+			env:   map[string]string{"CHAIN_ID": "", "REDIS_DB": " "},
+			check: func(c *Config) bool { return c.Chain.ID == 0 && c.Redis.DB == 0 },
+		},
+		{
+			name:    "invalid number",
+			env:     map[string]string{"REDIS_DB": "two"},
+			wantErr: `REDIS_DB from environment: "two" is not a number`,
+		},
+		{
+			name:    "invalid duration",
+			env:     map[string]string{"VIEW_WINDOW": "soon"},
+			wantErr: "VIEW_WINDOW from environment",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config, err := Load(Source{LookupEnv: lookupEnv(test.env)})
+
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("Load() error = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if !test.check(config) {
+				t.Errorf("Load() = %+v", config)
+			}
+		})
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "config.yaml")
+	secretsDir := filepath.Join(dir, "secrets")
+	passwordFile := filepath.Join(dir, "redis-password")
+
+	writeFile(t, file, "postgres:\n  port: 7000\n  database: from_file\nredis:\n  password: from_file\n")
+	writeFile(t, filepath.Join(secretsDir, "postgres_password"), "from_secrets_dir\n")
+	writeFile(t, passwordFile, "from_variable_file")
+
+	env := map[string]string{"POSTGRES_DB": "from_env", "REDIS_PASSWORD_FILE": passwordFile}
+
+	config, err := Load(Source{File: file, SecretsDir: secretsDir, LookupEnv: lookupEnv(env)})
+
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.Postgres.Port != "7000" {
+		t.Errorf("Postgres.Port = %q, want the file value", config.Postgres.Port)
+	}
+
+	if config.Postgres.Database != "from_env" {
+		t.Errorf("Postgres.Database = %q, want the environment to win over the file", config.Postgres.Database)
+	}
+
+	if config.Postgres.Password != "from_secrets_dir" {
+		t.Errorf("Postgres.Password = %q, want the trimmed secrets dir value", config.Postgres.Password)
+	}
+
+	if config.Redis.Password != "from_variable_file" {
+		t.Errorf("Redis.Password = %q, want REDIS_PASSWORD_FILE to win over the file", config.Redis.Password)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "valid serve", command: "serve"},
+		{name: "valid worker", command: "worker"},
+		{name: "migrate only needs postgres", command: "migrate", env: map[string]string{"APP_URL": "", "REDIS_HOST": ""}},
+		{name: "missing required", command: "serve", env: map[string]string{"APP_URL": ""}, wantErr: "No APP_URL"},
+		{name: "required by another command", command: "migrate", env: map[string]string{"RPC_URL": ""}},
+		{name: "number under min", command: "serve", env: map[string]string{"POSTGRES_MAX_OPEN_CONNS": "0"}, wantErr: "POSTGRES_MAX_OPEN_CONNS must be at least 1"},
+		{name: "sub second view window", command: "serve", env: map[string]string{"VIEW_WINDOW": "500ms"}, wantErr: "VIEW_WINDOW must be at least 1s"},
+		{name: "nanosecond interval", command: "worker", env: map[string]string{"WORKER_CONFIRMATION_INTERVAL": "1ns"}, wantErr: "WORKER_CONFIRMATION_INTERVAL must be at least 1s"},
+		{name: "nanosecond rpc timeout", command: "worker", env: map[string]string{"RPC_TIMEOUT": "1ns"}, wantErr: "RPC_TIMEOUT must be at least 1s"},
+		{name: "zero drain delay", command: "serve", env: map[string]string{"READINESS_DRAIN_DELAY": "0s"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := baseEnv()
+
+			for key, value := range test.env {
+				env[key] = value
+			}
+
+			_, err := LoadFor(Source{LookupEnv: lookupEnv(env)}, test.command)
+
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadFor() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("LoadFor() error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	_, err := LoadFor(Source{LookupEnv: lookupEnv(map[string]string{"REDIS_DB": "x"})}, "serve")
+
+	problems, ok := err.(Errors)
+
+	if !ok {
+		t.Fatalf("LoadFor() error = %v, want Errors", err)
+	}
+
+	// The parse error plus every field serve requires
+	if len(problems) < 10 {
+		t.Errorf("LoadFor() reported %d problems, want all of them: %v", len(problems), problems)
+	}
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	config, err := Load(Source{LookupEnv: lookupEnv(baseEnv())})
+
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	printed := config.String()
+
+	if strings.Contains(printed, "=secret\n") || strings.Contains(printed, "=token\n") {
+		t.Errorf("String() leaks a secret:\n%s", printed)
+	}
+
+	if !strings.Contains(printed, "JWT_HMAC_SECRET_KEY=[redacted]\n") || !strings.Contains(printed, "POSTGRES_HOST=localhost\n") {
+		t.Errorf("String() = \n%s", printed)
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,12 @@
+package config
+
+import (
+	"fmt"
+	"metaedu-marketplace/utils"
+)
+
+func CreateMailer(config SMTPConfig) utils.Mailer {
+	fmt.Println("Initialize mailer...")
+
+	return utils.NewSmtpMailer(config.Host, config.Port, config.Username, config.Password, config.From)
+}
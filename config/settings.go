@@ -0,0 +1,30 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// LoadEnvFile reads KEY=value pairs from a dotenv file, variables already set in the environment win
+func LoadEnvFile(path string, required bool) error {
+	err := godotenv.Load(path)
+
+	if err != nil && (required || !errors.Is(err, fs.ErrNotExist)) {
+		return err
+	}
+
+	return nil
+}
+
+// ApplyFlags copies the flags given on the command line over the environment, so flags win over both
+func ApplyFlags(flags *flag.FlagSet, variables map[string]string) {
+	flags.Visit(func(f *flag.Flag) {
+		if variable, ok := variables[f.Name]; ok {
+			os.Setenv(variable, f.Value.String())
+		}
+	})
+}
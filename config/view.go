@@ -0,0 +1,14 @@
+package config
+
+import (
+	"fmt"
+	"metaedu-marketplace/utils"
+
+	"github.com/go-redis/redis"
+)
+
+func CreateViewTracker(redisClient *redis.Client, config AppConfig) *utils.ViewTracker {
+	fmt.Println("Initialize view tracker...")
+
+	return utils.NewViewTracker(redisClient, config.ViewWindow)
+}
@@ -0,0 +1,12 @@
+package config
+
+import (
+	"fmt"
+	"metaedu-marketplace/utils"
+)
+
+func CreateVoucherVerifier(config ChainConfig) *utils.VoucherVerifier {
+	fmt.Println("Initialize voucher verifier...")
+
+	return utils.NewVoucherVerifier(config.ID, config.MarketplaceContractAddress)
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditController struct {
+	repository *repositories.AuditRepository
+}
+
+func NewAuditController(repository *repositories.AuditRepository) *AuditController {
+	return &AuditController{repository}
+}
+
+func (ac *AuditController) GetAuditLogList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	var actorID *uuid.UUID
+	var from *time.Time
+	var to *time.Time
+
+	if ctx.Query("actor_id") != "" {
+		id, err := uuid.Parse(ctx.Query("actor_id"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Actor id is not valid"})
+			return
+		}
+
+		actorID = &id
+	}
+
+	if ctx.Query("from") != "" {
+		value, err := time.Parse(time.RFC3339, ctx.Query("from"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "From is not valid"})
+			return
+		}
+
+		from = &value
+	}
+
+	if ctx.Query("to") != "" {
+		value, err := time.Parse(time.RFC3339, ctx.Query("to"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "To is not valid"})
+			return
+		}
+
+		to = &value
+	}
+
+	resourceType := ctx.Query("resource_type")
+	resourceID := ctx.Query("resource_id")
+	requestID := ctx.Query("request_id")
+
+	auditLogs, err := ac.repository.GetAuditLogList(ctx.Request.Context(), offset, limit, actorID, &resourceType, &resourceID, &requestID, from, to)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"audit_logs": auditLogs}})
+}
+
+func (ac *AuditController) GetAuditLogData(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	auditLog, err := ac.repository.GetAuditLogData(ctx.Request.Context(), id)
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Audit log not found"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"audit_log": auditLog}})
+}
+
+func (ac *AuditController) VerifyAuditChain(ctx *gin.Context) {
+	verification, err := ac.repository.VerifyAuditChain(ctx.Request.Context())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"verification": verification}})
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"database/sql"
 	"net/http"
+	"strings"
 	"time"
 
 	models "metaedu-marketplace/models"
@@ -44,17 +45,19 @@ func (ac *AuthenticationController) SignUp(ctx *gin.Context) {
 	user.Nonce = nonce
 	user.Status = "active"
 	user.Role = "user"
+	user.Verified = false
+	user.EmailVerifiedAt = sql.NullTime{}
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	user.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	existingUser := ac.repository.GetUserByAddress(user.Address)
+	existingUser := ac.repository.GetUserByAddress(ctx.Request.Context(), user.Address)
 
 	if (existingUser != models.User{}) {
 		ctx.JSON(http.StatusCreated, gin.H{"status": "failed", "error": "User has been registered"})
 		return
 	}
 
-	userID := ac.repository.InsertUser(user)
+	userID := ac.repository.InsertUser(ctx.Request.Context(), user)
 
 	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"userId": userID}})
 }
@@ -62,7 +65,7 @@ func (ac *AuthenticationController) SignUp(ctx *gin.Context) {
 func (ac *AuthenticationController) GetUserNonce(ctx *gin.Context) {
 	address := ctx.Param("address")
 
-	existingUser := ac.repository.GetUserByAddress(address)
+	existingUser := ac.repository.GetUserByAddress(ctx.Request.Context(), address)
 
 	if (existingUser == models.User{}) {
 		ctx.JSON(http.StatusOK, gin.H{"status": "failed", "error": "User doesn't exist"})
@@ -80,7 +83,7 @@ func (ac *AuthenticationController) SignIn(ctx *gin.Context) {
 		return
 	}
 
-	user := ac.repository.GetUserByAddress(requestBody.Address)
+	user := ac.repository.GetUserByAddress(ctx.Request.Context(), requestBody.Address)
 
 	if (user == models.User{}) {
 		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Address not found"})
@@ -92,7 +95,8 @@ func (ac *AuthenticationController) SignIn(ctx *gin.Context) {
 		return
 	}
 
-	err := utils.Verify(user.Address, user.Nonce, requestBody.Sig)
+	// Any linked address may sign in, so verify against the address that signed
+	err := utils.Verify(strings.ToLower(requestBody.Address), user.Nonce, requestBody.Sig)
 
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Signature is not valid"})
@@ -108,7 +112,7 @@ func (ac *AuthenticationController) SignIn(ctx *gin.Context) {
 
 	user.Nonce = updatedNonce
 
-	err = ac.repository.UpdateUser(user.ID, user)
+	err = ac.repository.UpdateUser(ctx.Request.Context(), user.ID, user)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "Failed to update user nonce"})
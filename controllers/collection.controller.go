@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,8 +10,10 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
+	"metaedu-marketplace/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
@@ -21,14 +22,17 @@ import (
 )
 
 type CollectionController struct {
-	repository            *repositories.CollectionRepository
-	transactionRepository *repositories.TransactionRepository
-	web3StorageClient     w3s.Client
-	redisClient           *redis.Client
+	repository             *repositories.CollectionRepository
+	transactionRepository  *repositories.TransactionRepository
+	priceHistoryRepository *repositories.PriceHistoryRepository
+	moderationRepository   *repositories.ModerationRepository
+	viewTracker            *utils.ViewTracker
+	web3StorageClient      w3s.Client
+	redisClient            *redis.Client
 }
 
-func NewCollectionController(repository *repositories.CollectionRepository, transactionRepository *repositories.TransactionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *CollectionController {
-	return &CollectionController{repository, transactionRepository, web3StorageClient, redisClient}
+func NewCollectionController(repository *repositories.CollectionRepository, transactionRepository *repositories.TransactionRepository, priceHistoryRepository *repositories.PriceHistoryRepository, moderationRepository *repositories.ModerationRepository, viewTracker *utils.ViewTracker, web3StorageClient w3s.Client, redisClient *redis.Client) *CollectionController {
+	return &CollectionController{repository, transactionRepository, priceHistoryRepository, moderationRepository, viewTracker, web3StorageClient, redisClient}
 }
 
 func (ac *CollectionController) InsertCollection(ctx *gin.Context) {
@@ -73,7 +77,7 @@ func (ac *CollectionController) InsertCollection(ctx *gin.Context) {
 
 	thumbnailFileName := path.Base(uploadedThumbnail.Filename)
 
-	thumbnailCid, err := ac.web3StorageClient.Put(context.Background(), thumbnailFile)
+	thumbnailCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), thumbnailFile)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
@@ -96,7 +100,7 @@ func (ac *CollectionController) InsertCollection(ctx *gin.Context) {
 
 	coverFileName := path.Base(uploadedCover.Filename)
 
-	coverCid, err := ac.web3StorageClient.Put(context.Background(), coverFile)
+	coverCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), coverFile)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
@@ -139,7 +143,7 @@ func (ac *CollectionController) InsertCollection(ctx *gin.Context) {
 	collection.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	collection.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	collectionId, err := ac.repository.InsertCollection(collection)
+	collectionId, err := ac.repository.InsertCollection(ctx.Request.Context(), collection)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -223,7 +227,7 @@ func (ac *CollectionController) GetCollectionList(ctx *gin.Context) {
 		return
 	}
 
-	collections, err = ac.repository.GetCollectionList(offset, limit, keyword, creatorID, &status, orderBy, orderOption)
+	collections, err = ac.repository.GetCollectionList(ctx.Request.Context(), offset, limit, keyword, creatorID, &status, true, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -280,9 +284,12 @@ func (ac *CollectionController) GetCollectionData(ctx *gin.Context) {
 			return
 		}
 
-		collection.Views = sql.NullInt64{Int64: collection.Views.Int64 + 1, Valid: true}
+		if helpers.IsCollectionHidden(collection) && !isAdmin(ctx) {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+			return
+		}
 
-		err = ac.repository.UpdateCollection(collection.ID, collection)
+		_, err = ac.viewTracker.Track(utils.ViewTypeCollection, collection.ID, getViewer(ctx))
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -293,7 +300,7 @@ func (ac *CollectionController) GetCollectionData(ctx *gin.Context) {
 		return
 	}
 
-	collection, err = ac.repository.GetCollectionData(id)
+	collection, err = ac.repository.GetCollectionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -319,9 +326,12 @@ func (ac *CollectionController) GetCollectionData(ctx *gin.Context) {
 		return
 	}
 
-	collection.Views = sql.NullInt64{Int64: collection.Views.Int64 + 1, Valid: true}
+	if helpers.IsCollectionHidden(collection) && !isAdmin(ctx) {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+		return
+	}
 
-	err = ac.repository.UpdateCollection(collection.ID, collection)
+	_, err = ac.viewTracker.Track(utils.ViewTypeCollection, collection.ID, getViewer(ctx))
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -346,7 +356,7 @@ func (ac *CollectionController) UpdateCollection(ctx *gin.Context) {
 		return
 	}
 
-	collection, err := ac.repository.GetCollectionData(id)
+	collection, err := ac.repository.GetCollectionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -390,7 +400,7 @@ func (ac *CollectionController) UpdateCollection(ctx *gin.Context) {
 	collection.CreatorID = creatorID
 	collection.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.repository.UpdateCollection(collection.ID, collection)
+	err = ac.repository.UpdateCollection(ctx.Request.Context(), collection.ID, collection)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -441,7 +451,29 @@ func (ac *CollectionController) DeleteCollection(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteCollection(id)
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	err = ac.repository.DeleteCollection(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	_, err = ac.moderationRepository.InsertModerationAction(ctx.Request.Context(), models.ModerationAction{
+		ModeratorID: user.(models.User).ID,
+		SubjectType: helpers.ModerationSubjectCollection,
+		SubjectID:   id,
+		Action:      helpers.ModerationActionDelete,
+		Reason:      sql.NullString{String: reason, Valid: reason != ""},
+	})
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -539,7 +571,7 @@ func (ac *CollectionController) GetCollectionTransactionList(ctx *gin.Context) {
 	}
 
 	// Get transaction list data from repository
-	transactions, err = ac.transactionRepository.GetTransactionListByCollection(offset, limit, id, &status, orderBy, orderOption)
+	transactions, err = ac.transactionRepository.GetTransactionListByCollection(ctx.Request.Context(), offset, limit, id, &status, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -563,3 +595,74 @@ func (ac *CollectionController) GetCollectionTransactionList(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"transactions": transactions}})
 }
+
+func (ac *CollectionController) GetCollectionStats(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	interval := ctx.DefaultQuery("interval", "1d")
+
+	if !helpers.IsValidStatInterval(interval) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Interval is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "30"))
+
+	if err != nil || limit < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Limit is not valid"})
+		return
+	}
+
+	var collectionStats []models.CollectionStat
+
+	cacheKey := fmt.Sprintf("collection-stats-%s-%s-%d", id, interval, limit)
+	cache, err := ac.redisClient.Get(cacheKey).Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if cache != "" && cache != "null" {
+		err := json.Unmarshal([]byte(cache), &collectionStats)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"interval": interval, "stats": collectionStats}})
+		return
+	}
+
+	// Return the last buckets up to the open one
+	from := helpers.GetStatBucket(interval, time.Now()).Add(-time.Duration(limit-1) * helpers.StatIntervals[interval])
+
+	collectionStats, err = ac.priceHistoryRepository.GetCollectionStatList(ctx.Request.Context(), id, interval, from)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	cacheBytes, err := json.Marshal(collectionStats)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.redisClient.Set(cacheKey, cacheBytes, 0).Err()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"interval": interval, "stats": collectionStats}})
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"metaedu-marketplace/events"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+type EventController struct {
+	hub      *events.Hub
+	upgrader websocket.Upgrader
+}
+
+func NewEventController(hub *events.Hub, allowedOrigins []string) *EventController {
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			return helpers.IsAllowedOrigin(r.Header.Get("Origin"), allowedOrigins)
+		},
+	}
+
+	return &EventController{hub, upgrader}
+}
+
+// getTopics validates the requested topics, user topics are only open to their own user
+func (ac *EventController) getTopics(ctx *gin.Context) ([]string, bool) {
+	topicsParams := ctx.DefaultQuery("topics", events.GlobalTopic)
+
+	var userTopic string
+	user, isExist := ctx.Get("user")
+
+	if isExist {
+		userTopic = events.UserTopic(user.(models.User).ID)
+	}
+
+	var topics []string
+
+	for _, topic := range strings.Split(topicsParams, ",") {
+		topic = strings.TrimSpace(topic)
+
+		if topic == "" {
+			continue
+		}
+
+		if topic == "user:me" && userTopic != "" {
+			topic = userTopic
+		}
+
+		if strings.HasPrefix(topic, "user:") && topic != userTopic {
+			ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "User has no access to topic " + topic})
+			return topics, false
+		}
+
+		if topic != events.GlobalTopic && !strings.HasPrefix(topic, "user:") && !strings.HasPrefix(topic, "token:") && !strings.HasPrefix(topic, "collection:") {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Topic is not valid"})
+			return topics, false
+		}
+
+		topics = append(topics, topic)
+	}
+
+	return topics, true
+}
+
+func (ac *EventController) StreamEvents(ctx *gin.Context) {
+	topics, isValid := ac.getTopics(ctx)
+
+	if !isValid {
+		return
+	}
+
+	subscriber := ac.hub.Subscribe(topics)
+	defer ac.hub.Unsubscribe(subscriber)
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-subscriber.Events:
+			ctx.SSEvent(event.Type, event)
+			return true
+		case <-ticker.C:
+			ctx.SSEvent("ping", time.Now())
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		case <-ac.hub.Done():
+			return false
+		}
+	})
+}
+
+func (ac *EventController) SubscribeEvents(ctx *gin.Context) {
+	topics, isValid := ac.getTopics(ctx)
+
+	if !isValid {
+		return
+	}
+
+	conn, err := ac.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	subscriber := ac.hub.Subscribe(topics)
+	defer ac.hub.Unsubscribe(subscriber)
+
+	// Read until the client closes the connection
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-subscriber.Events:
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-ac.hub.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"), time.Now().Add(time.Second))
+			return
+		}
+	}
+}
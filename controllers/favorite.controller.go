@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+type FavoriteController struct {
+	favoriteRepository   *repositories.FavoriteRepository
+	tokenRepository      *repositories.TokenRepository
+	collectionRepository *repositories.CollectionRepository
+	redisClient          *redis.Client
+}
+
+func NewFavoriteController(favoriteRepository *repositories.FavoriteRepository, tokenRepository *repositories.TokenRepository, collectionRepository *repositories.CollectionRepository, redisClient *redis.Client) *FavoriteController {
+	return &FavoriteController{favoriteRepository, tokenRepository, collectionRepository, redisClient}
+}
+
+func (ac *FavoriteController) GetFavoriteList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	favoriteType := ctx.Query("type")
+
+	if favoriteType != "" && favoriteType != "token" && favoriteType != "collection" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	favorites, err := ac.favoriteRepository.GetFavoriteList(ctx.Request.Context(), offset, limit, user.(models.User).ID, &favoriteType)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"favorites": favorites}})
+}
+
+func (ac *FavoriteController) InsertFavorite(ctx *gin.Context) {
+	favorite, isValid := ac.getFavorite(ctx)
+
+	if !isValid {
+		return
+	}
+
+	isInserted, err := ac.favoriteRepository.InsertFavorite(ctx.Request.Context(), favorite)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isInserted {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Item has been favorited"})
+		return
+	}
+
+	ac.removeFavoriteCache(ctx.Param("type"))
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Item has been favorited"})
+}
+
+func (ac *FavoriteController) DeleteFavorite(ctx *gin.Context) {
+	favorite, isValid := ac.getFavorite(ctx)
+
+	if !isValid {
+		return
+	}
+
+	isDeleted, err := ac.favoriteRepository.DeleteFavorite(ctx.Request.Context(), favorite)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isDeleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Favorite not found"})
+		return
+	}
+
+	ac.removeFavoriteCache(ctx.Param("type"))
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Item has been unfavorited"})
+}
+
+// Build the favorite of the current user from the type and id params
+func (ac *FavoriteController) getFavorite(ctx *gin.Context) (models.Favorite, bool) {
+	var favorite models.Favorite
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return favorite, false
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return favorite, false
+	}
+
+	favorite.UserID = user.(models.User).ID
+
+	switch ctx.Param("type") {
+	case "token":
+		token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return favorite, false
+		}
+
+		if token.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+			return favorite, false
+		}
+
+		favorite.TokenID = token.ID
+	case "collection":
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return favorite, false
+		}
+
+		if collection.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+			return favorite, false
+		}
+
+		favorite.CollectionID = collection.ID
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return favorite, false
+	}
+
+	return favorite, true
+}
+
+// Cached token and collection data carry the favorite count
+func (ac *FavoriteController) removeFavoriteCache(favoriteType string) {
+	keys, err := ac.redisClient.Keys(favoriteType + "-*").Result()
+
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		ac.redisClient.Del(key)
+	}
+}
@@ -0,0 +1,465 @@
+package controllers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+type FeeScheduleController struct {
+	repository      *repositories.FeeScheduleRepository
+	tokenRepository *repositories.TokenRepository
+	redisClient     *redis.Client
+}
+
+func NewFeeScheduleController(repository *repositories.FeeScheduleRepository, tokenRepository *repositories.TokenRepository, redisClient *redis.Client) *FeeScheduleController {
+	return &FeeScheduleController{repository, tokenRepository, redisClient}
+}
+
+func (ac *FeeScheduleController) bindFeeSchedule(ctx *gin.Context, feeSchedule *models.FeeSchedule) error {
+	var err error
+
+	feeSchedule.Title = ctx.DefaultPostForm("title", feeSchedule.Title)
+
+	if feeSchedule.Title == "" {
+		return fmt.Errorf("Title is required")
+	}
+
+	// Validate transaction type
+	feeSchedule.TransactionType = ctx.DefaultPostForm("transaction_type", feeSchedule.TransactionType)
+
+	if !helpers.IsValidFeeTransactionType(feeSchedule.TransactionType) {
+		return fmt.Errorf("Transaction type is not valid")
+	}
+
+	// Validate fee type
+	feeSchedule.FeeType = ctx.DefaultPostForm("fee_type", feeSchedule.FeeType)
+
+	if !helpers.IsValidFeeType(feeSchedule.FeeType) {
+		return fmt.Errorf("Fee type is not valid")
+	}
+
+	// Validate value
+	valueParams := ctx.PostForm("value")
+
+	if valueParams != "" {
+		feeSchedule.Value, err = strconv.ParseFloat(valueParams, 64)
+
+		if err != nil {
+			return fmt.Errorf("Value is not valid")
+		}
+	}
+
+	if feeSchedule.Value < 0 {
+		return fmt.Errorf("Value must be greater than 0 or equal")
+	}
+
+	if feeSchedule.FeeType == helpers.FeeTypePercentage && feeSchedule.Value > 100 {
+		return fmt.Errorf("Percentage value must be lower than 100 or equal")
+	}
+
+	// Validate category
+	categoryIDParams := ctx.PostForm("category_id")
+
+	if categoryIDParams != "" {
+		feeSchedule.CategoryID, err = uuid.Parse(categoryIDParams)
+
+		if err != nil {
+			return fmt.Errorf("Category id is not valid")
+		}
+	}
+
+	// Validate collection
+	collectionIDParams := ctx.PostForm("collection_id")
+
+	if collectionIDParams != "" {
+		feeSchedule.CollectionID, err = uuid.Parse(collectionIDParams)
+
+		if err != nil {
+			return fmt.Errorf("Collection id is not valid")
+		}
+	}
+
+	// Validate recipient
+	recipientIDParams := ctx.PostForm("recipient_id")
+
+	if recipientIDParams != "" {
+		feeSchedule.RecipientID, err = uuid.Parse(recipientIDParams)
+
+		if err != nil {
+			return fmt.Errorf("Recipient id is not valid")
+		}
+	}
+
+	// Validate promotion window
+	promotionParams := ctx.PostForm("promotion")
+
+	if promotionParams != "" {
+		feeSchedule.Promotion = promotionParams == "true"
+	}
+
+	startsAtParams := ctx.PostForm("starts_at")
+
+	if startsAtParams != "" {
+		startsAt, err := time.Parse(time.RFC3339, startsAtParams)
+
+		if err != nil {
+			return fmt.Errorf("Starts at is not valid")
+		}
+
+		feeSchedule.StartsAt = sql.NullTime{Time: startsAt, Valid: true}
+	}
+
+	endsAtParams := ctx.PostForm("ends_at")
+
+	if endsAtParams != "" {
+		endsAt, err := time.Parse(time.RFC3339, endsAtParams)
+
+		if err != nil {
+			return fmt.Errorf("Ends at is not valid")
+		}
+
+		feeSchedule.EndsAt = sql.NullTime{Time: endsAt, Valid: true}
+	}
+
+	if feeSchedule.StartsAt.Valid && feeSchedule.EndsAt.Valid && !feeSchedule.EndsAt.Time.After(feeSchedule.StartsAt.Time) {
+		return fmt.Errorf("Ends at must be after starts at")
+	}
+
+	if feeSchedule.Promotion && !feeSchedule.EndsAt.Valid {
+		return fmt.Errorf("Promotion requires ends at")
+	}
+
+	// Validate status
+	feeSchedule.Status = ctx.DefaultPostForm("status", feeSchedule.Status)
+
+	if feeSchedule.Status != "active" && feeSchedule.Status != "inactive" {
+		return fmt.Errorf("Status is not valid")
+	}
+
+	return nil
+}
+
+func (ac *FeeScheduleController) removeFeeScheduleCache() error {
+	keys, err := ac.redisClient.Keys("fee-schedule-*").Result()
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err = ac.redisClient.Del(key).Err()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ac *FeeScheduleController) InsertFeeSchedule(ctx *gin.Context) {
+	var feeSchedule models.FeeSchedule
+
+	feeSchedule.Status = "active"
+
+	err := ac.bindFeeSchedule(ctx, &feeSchedule)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	feeSchedule.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	feeSchedule.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	feeScheduleID, err := ac.repository.InsertFeeSchedule(ctx.Request.Context(), feeSchedule)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeFeeScheduleCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"fee_schedule_id": feeScheduleID}})
+}
+
+func (ac *FeeScheduleController) GetFeeScheduleList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	transactionType := ctx.DefaultQuery("transaction_type", "")
+	status := ctx.DefaultQuery("status", "active")
+	categoryIDParams := ctx.DefaultQuery("category_id", "")
+	collectionIDParams := ctx.DefaultQuery("collection_id", "")
+	orderBy := ctx.DefaultQuery("order_by", "created_at")
+	orderOption := ctx.DefaultQuery("order_option", "ASC")
+
+	var categoryID *uuid.UUID
+
+	if categoryIDParams != "" {
+		categoryIDConversion, err := uuid.Parse(categoryIDParams)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Category id is not valid"})
+			return
+		}
+
+		categoryID = &categoryIDConversion
+	}
+
+	var collectionID *uuid.UUID
+
+	if collectionIDParams != "" {
+		collectionIDConversion, err := uuid.Parse(collectionIDParams)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Collection id is not valid"})
+			return
+		}
+
+		collectionID = &collectionIDConversion
+	}
+
+	var feeSchedules []models.FeeSchedule
+
+	cacheKey := fmt.Sprintf("fee-schedule-list-%d-%d-%s-%s-%s-%s-%s-%s", offset, limit, transactionType, categoryIDParams, collectionIDParams, status, orderBy, orderOption)
+	cache, err := ac.redisClient.Get(cacheKey).Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if cache != "" && cache != "null" {
+		err := json.Unmarshal([]byte(cache), &feeSchedules)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"fee_schedules": feeSchedules}})
+		return
+	}
+
+	feeSchedules, err = ac.repository.GetFeeScheduleList(ctx.Request.Context(), offset, limit, &transactionType, categoryID, collectionID, &status, orderBy, orderOption)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	cacheBytes, err := json.Marshal(feeSchedules)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.redisClient.Set(cacheKey, cacheBytes, 0).Err()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"fee_schedules": feeSchedules}})
+}
+
+func (ac *FeeScheduleController) GetFeeScheduleData(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+
+	if idParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id parameter is required"})
+		return
+	}
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	feeSchedule, err := ac.repository.GetFeeScheduleData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if feeSchedule.Title == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Fee schedule not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"fee_schedule": feeSchedule}})
+}
+
+func (ac *FeeScheduleController) GetFeeQuote(ctx *gin.Context) {
+	// Validate transaction type
+	transactionType := ctx.Query("transaction_type")
+
+	if !helpers.IsValidFeeTransactionType(transactionType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Transaction type is not valid"})
+		return
+	}
+
+	// Validate amount
+	amount, err := strconv.ParseFloat(ctx.DefaultQuery("amount", "0"), 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if amount <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount must be greater than 0"})
+		return
+	}
+
+	// Validate token
+	tokenID, err := uuid.Parse(ctx.Query("token_id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token id is not valid"})
+		return
+	}
+
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), tokenID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if token.Uri == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+		return
+	}
+
+	feeSchedules, err := ac.repository.GetApplicableFeeScheduleList(ctx.Request.Context(), transactionType, token.CategoryID, token.CollectionID, time.Now())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	fees, totalFee := helpers.CalculateFees(amount, feeSchedules)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"fees": fees, "total_fee": totalFee, "total_amount": amount + totalFee}})
+}
+
+func (ac *FeeScheduleController) UpdateFeeSchedule(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+
+	if idParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id parameter is required"})
+		return
+	}
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	feeSchedule, err := ac.repository.GetFeeScheduleData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if feeSchedule.Title == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Fee schedule not found"})
+		return
+	}
+
+	err = ac.bindFeeSchedule(ctx, &feeSchedule)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	feeSchedule.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.repository.UpdateFeeSchedule(ctx.Request.Context(), feeSchedule.ID, feeSchedule)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeFeeScheduleCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Fee schedule has been updated"})
+}
+
+func (ac *FeeScheduleController) DeleteFeeSchedule(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+
+	if idParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id parameter is required"})
+		return
+	}
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.repository.DeleteFeeSchedule(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeFeeScheduleCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Fee schedule has been deleted"})
+}
@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+// Feeds are assembled on read, so they are only cached for a short time
+const feedCacheDuration = time.Minute
+
+type FollowController struct {
+	followRepository     *repositories.FollowRepository
+	userRepository       *repositories.UserRepository
+	collectionRepository *repositories.CollectionRepository
+	redisClient          *redis.Client
+}
+
+func NewFollowController(followRepository *repositories.FollowRepository, userRepository *repositories.UserRepository, collectionRepository *repositories.CollectionRepository, redisClient *redis.Client) *FollowController {
+	return &FollowController{followRepository, userRepository, collectionRepository, redisClient}
+}
+
+func (ac *FollowController) GetFollowList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	follows, err := ac.followRepository.GetFollowList(ctx.Request.Context(), offset, limit, user.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"follows": follows}})
+}
+
+func (ac *FollowController) InsertFollow(ctx *gin.Context) {
+	follow, isValid := ac.getFollow(ctx)
+
+	if !isValid {
+		return
+	}
+
+	isInserted, err := ac.followRepository.InsertFollow(ctx.Request.Context(), follow)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isInserted {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Already followed"})
+		return
+	}
+
+	ac.removeFeedCache(follow.FollowerID)
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "message": "Followed"})
+}
+
+func (ac *FollowController) DeleteFollow(ctx *gin.Context) {
+	follow, isValid := ac.getFollow(ctx)
+
+	if !isValid {
+		return
+	}
+
+	isDeleted, err := ac.followRepository.DeleteFollow(ctx.Request.Context(), follow)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isDeleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Follow not found"})
+		return
+	}
+
+	ac.removeFeedCache(follow.FollowerID)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Unfollowed"})
+}
+
+func (ac *FollowController) GetFeed(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	var feedItems []models.FeedItem
+
+	cacheKey := fmt.Sprintf("feed-%s-%d-%d", user.(models.User).ID, offset, limit)
+	cache, err := ac.redisClient.Get(cacheKey).Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if cache != "" && cache != "null" {
+		err := json.Unmarshal([]byte(cache), &feedItems)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"feed": feedItems}})
+		return
+	}
+
+	feedItems, err = ac.followRepository.GetFeed(ctx.Request.Context(), offset, limit, user.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	cacheBytes, err := json.Marshal(feedItems)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.redisClient.Set(cacheKey, cacheBytes, feedCacheDuration).Err()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"feed": feedItems}})
+}
+
+// Build the follow of the current user from the type and id params
+func (ac *FollowController) getFollow(ctx *gin.Context) (models.Follow, bool) {
+	var follow models.Follow
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return follow, false
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return follow, false
+	}
+
+	follow.FollowerID = user.(models.User).ID
+
+	switch ctx.Param("type") {
+	case "user":
+		if id == follow.FollowerID {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "User can not follow themself"})
+			return follow, false
+		}
+
+		followedUser, err := ac.userRepository.GetUserByID(ctx.Request.Context(), id)
+
+		if err != nil || followedUser.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "User not found"})
+			return follow, false
+		}
+
+		follow.UserID = followedUser.ID
+	case "collection":
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return follow, false
+		}
+
+		if collection.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+			return follow, false
+		}
+
+		follow.CollectionID = collection.ID
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return follow, false
+	}
+
+	return follow, true
+}
+
+func (ac *FollowController) removeFeedCache(userID uuid.UUID) {
+	keys, err := ac.redisClient.Keys(fmt.Sprintf("feed-%s-*", userID)).Result()
+
+	if err != nil {
+		return
+	}
+
+	for _, key := range keys {
+		ac.redisClient.Del(key)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
 
@@ -18,17 +19,22 @@ import (
 )
 
 type FractionController struct {
-	repository          *repositories.FractionRepository
-	tokenRepository     *repositories.TokenRepository
-	ownershipRepository *repositories.OwnershipRepository
-	rentalRepository    *repositories.RentalRepository
-	userRepository      *repositories.UserRepository
-	web3StorageClient   w3s.Client
-	redisClient         *redis.Client
+	repository               *repositories.FractionRepository
+	tokenRepository          *repositories.TokenRepository
+	ownershipRepository      *repositories.OwnershipRepository
+	rentalRepository         *repositories.RentalRepository
+	userRepository           *repositories.UserRepository
+	feeScheduleRepository    *repositories.FeeScheduleRepository
+	transactionFeeRepository *repositories.TransactionFeeRepository
+	fractionShareRepository  *repositories.FractionShareRepository
+	fractionBuyoutRepository *repositories.FractionBuyoutRepository
+	versionRepository        *repositories.VersionRepository
+	web3StorageClient        w3s.Client
+	redisClient              *redis.Client
 }
 
-func NewFractionController(repository *repositories.FractionRepository, tokenRepository *repositories.TokenRepository, ownershipRepository *repositories.OwnershipRepository, rentalRepository *repositories.RentalRepository, userRepository *repositories.UserRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *FractionController {
-	return &FractionController{repository, tokenRepository, ownershipRepository, rentalRepository, userRepository, web3StorageClient, redisClient}
+func NewFractionController(repository *repositories.FractionRepository, tokenRepository *repositories.TokenRepository, ownershipRepository *repositories.OwnershipRepository, rentalRepository *repositories.RentalRepository, userRepository *repositories.UserRepository, feeScheduleRepository *repositories.FeeScheduleRepository, transactionFeeRepository *repositories.TransactionFeeRepository, fractionShareRepository *repositories.FractionShareRepository, fractionBuyoutRepository *repositories.FractionBuyoutRepository, versionRepository *repositories.VersionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *FractionController {
+	return &FractionController{repository, tokenRepository, ownershipRepository, rentalRepository, userRepository, feeScheduleRepository, transactionFeeRepository, fractionShareRepository, fractionBuyoutRepository, versionRepository, web3StorageClient, redisClient}
 }
 
 func (ac *FractionController) InsertFraction(ctx *gin.Context) {
@@ -90,39 +96,107 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 		return
 	}
 
-	// Default status for query data
-	status := "active"
+	// Validate reserve price
+	reservePrice, err := strconv.ParseFloat(ctx.DefaultPostForm("reserve_price", strconv.FormatFloat(price, 'f', -1, 64)), 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if reservePrice < price {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Reserve price must be greater than price or equal"})
+		return
+	}
+
+	// Validate amount, what the owner paid for the fraction fees
+	amountParams := ctx.PostForm("amount")
+
+	if amountParams == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is required"})
+		return
+	}
+
+	amount, err := strconv.ParseFloat(amountParams, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if amount < 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount must be greater than 0 or equal"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	// Get ownership data of parent token
+	oldOwnership, err := ac.ownershipRepository.GetOwnershipData(ctx.Request.Context(), ownershipID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if oldOwnership.TokenID != tokenSourceID || oldOwnership.UserID != user.(models.User).ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only owner can fractionalize the token"})
+		return
+	}
 
 	// Check if token is still in rental period
-	rentals, err := ac.rentalRepository.GetRentalList(100, 0, "", nil, nil, nil, nil, nil, nil, &tokenSourceID, &status, "created_at", "DESC")
+	activeRentalCount, err := ac.rentalRepository.GetActiveRentalCount(ctx.Request.Context(), tokenSourceID, time.Now())
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	for _, rental := range rentals {
-		if rental.Timestamp.Time.After(time.Now()) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Item is still in rental period"})
-			return
-		}
+	if activeRentalCount > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Item is still in rental period"})
+		return
 	}
 
 	// Get last token index
-	tokenIndex, err := ac.tokenRepository.GetLastTokenIndex()
+	tokenIndex, err := ac.tokenRepository.GetLastTokenIndex(ctx.Request.Context())
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
 	}
 
-	tokenSource, err := ac.tokenRepository.GetTokenData(tokenSourceID)
+	tokenSource, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), tokenSourceID)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
+	if tokenSource.Locked {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token is locked in a fraction vault"})
+		return
+	}
+
+	// Get marketplace fees
+	feeSchedules, err := ac.feeScheduleRepository.GetApplicableFeeScheduleList(ctx.Request.Context(), "fraction", tokenSource.CategoryID, tokenSource.CollectionID, time.Now())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	fees, totalFee := helpers.CalculateFees(price*float64(supply), feeSchedules)
+
+	if amount < totalFee {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than fees"})
+		return
+	}
+
 	var tokenFraction models.Token
 
 	tokenFraction.SourceID = tokenSourceID
@@ -145,7 +219,7 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 	tokenFraction.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	tokenFraction.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	tokenFractionUpdated, err := ac.tokenRepository.InsertToken(tokenFraction, nil)
+	tokenFractionUpdated, err := ac.tokenRepository.InsertToken(ctx.Request.Context(), tokenFraction, nil)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -161,12 +235,8 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 		return
 	}
 
-	tokenSource.PreviousID = tokenSource.ID
-	tokenSource.FractionID = tokenFractionUpdated.ID
-	tokenSource.Status = "waiting_confirmation"
-	tokenSource.TransactionHash = transactionHash
-
-	_, err = ac.tokenRepository.InsertToken(tokenSource, nil)
+	// Source token points at the fraction once the mint is confirmed
+	_, err = ac.versionRepository.InsertPendingChange(ctx.Request.Context(), helpers.VersionResourceToken, tokenSource.ID, models.TokenChange{FractionID: &tokenFractionUpdated.ID}, transactionHash)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -182,22 +252,21 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 	}
 
 	// Get system user
-	systemUser, err := ac.userRepository.GetSystemUser()
-
-	// Get ownership data of parent token
-	oldOwnership, err := ac.ownershipRepository.GetOwnershipData(ownershipID)
+	systemUser, err := ac.userRepository.GetSystemUser(ctx.Request.Context())
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	oldOwnership.UserID = systemUser.ID
-	oldOwnership.PreviousID = oldOwnership.ID
-	oldOwnership.Status = "waiting_confirmation"
-	oldOwnership.TransactionHash = transactionHash
+	// Parent token is held by the vault
+	isAvailable := false
 
-	_, err = ac.ownershipRepository.InsertOwnership(oldOwnership)
+	_, err = ac.versionRepository.InsertPendingChange(ctx.Request.Context(), helpers.VersionResourceOwnership, oldOwnership.ID, models.OwnershipChange{
+		UserID:           &systemUser.ID,
+		AvailableForSale: &isAvailable,
+		AvailableForRent: &isAvailable,
+	}, transactionHash)
 
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err})
@@ -216,7 +285,7 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 	var newOwnership models.Ownership
 
 	newOwnership.TokenID = tokenFractionUpdated.ID
-	newOwnership.UserID = tokenFraction.CreatorID
+	newOwnership.UserID = user.(models.User).ID
 	newOwnership.Quantity = supply
 	newOwnership.SalePrice = price
 	newOwnership.RentCost = oldOwnership.RentCost
@@ -227,7 +296,7 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 	newOwnership.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	newOwnership.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	ownershipIDResult, err := ac.ownershipRepository.InsertOwnership(newOwnership)
+	ownershipIDResult, err := ac.ownershipRepository.InsertOwnership(ctx.Request.Context(), newOwnership)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -246,19 +315,46 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 	var fraction models.Fraction
 
 	fraction.TokenParentID = tokenSourceID
-	fraction.TokenFractionID = tokenFraction.ID
+	fraction.TokenFractionID = tokenFractionUpdated.ID
+	fraction.OwnerID = user.(models.User).ID
+	fraction.OwnershipID = ownershipID
+	fraction.TotalShares = supply
+	fraction.SharePrice = price
+	fraction.ReservePrice = reservePrice
 	fraction.Status = "waiting_confirmation"
 	fraction.TransactionHash = transactionHash
 	fraction.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	fraction.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	fractionId, err := ac.repository.InsertFraction(fraction)
+	fractionId, err := ac.repository.InsertFraction(ctx.Request.Context(), fraction)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
+	// Record fraction fees
+	for index := range fees {
+		fees[index].FractionID, err = uuid.Parse(fractionId)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+
+		fees[index].Status = "waiting_confirmation"
+		fees[index].TransactionHash = transactionHash
+		fees[index].UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		fees[index].CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		_, err = ac.transactionFeeRepository.InsertTransactionFee(ctx.Request.Context(), fees[index])
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+	}
+
 	// Remove fraction cache
 	cacheKey = "fraction-list-*"
 	keys, err = ac.redisClient.Keys(cacheKey).Result()
@@ -309,7 +405,7 @@ func (ac *FractionController) InsertFraction(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"fraction_id": fractionId, "ownership_id": ownershipIDResult, "token_id": tokenFractionUpdated.ID}})
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"fraction_id": fractionId, "ownership_id": ownershipIDResult, "token_id": tokenFractionUpdated.ID, "fees": fees}})
 }
 
 func (ac *FractionController) GetFractionList(ctx *gin.Context) {
@@ -371,7 +467,7 @@ func (ac *FractionController) GetFractionList(ctx *gin.Context) {
 		return
 	}
 
-	fractions, err = ac.repository.GetFractionList(offset, limit, keyword, creatorID, &creator, &status, orderBy, orderOption)
+	fractions, err = ac.repository.GetFractionList(ctx.Request.Context(), offset, limit, keyword, creatorID, &creator, &status, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -432,7 +528,7 @@ func (ac *FractionController) GetFractionData(ctx *gin.Context) {
 		return
 	}
 
-	fraction, err = ac.repository.GetFractionData(id)
+	fraction, err = ac.repository.GetFractionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -476,7 +572,7 @@ func (ac *FractionController) UpdateFraction(ctx *gin.Context) {
 		return
 	}
 
-	fraction, err := ac.repository.GetFractionData(id)
+	fraction, err := ac.repository.GetFractionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -516,7 +612,7 @@ func (ac *FractionController) UpdateFraction(ctx *gin.Context) {
 	fraction.TokenFractionID = tokenFractionId
 	fraction.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.repository.UpdateFraction(fraction.ID, fraction)
+	err = ac.repository.UpdateFraction(ctx.Request.Context(), fraction.ID, fraction)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -567,7 +663,7 @@ func (ac *FractionController) DeleteFraction(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteFraction(id)
+	err = ac.repository.DeleteFraction(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -601,3 +697,207 @@ func (ac *FractionController) DeleteFraction(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Fraction has been deleted"})
 }
+
+func (ac *FractionController) getActiveFraction(ctx *gin.Context) (models.Fraction, bool) {
+	var fraction models.Fraction
+
+	idParam := ctx.Param("id")
+
+	if idParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id parameter is required"})
+		return fraction, false
+	}
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return fraction, false
+	}
+
+	fraction, err = ac.repository.GetFractionData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return fraction, false
+	}
+
+	if fraction.Status == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Fraction not found"})
+		return fraction, false
+	}
+
+	return fraction, true
+}
+
+func (ac *FractionController) GetFractionShareList(ctx *gin.Context) {
+	fraction, isFound := ac.getActiveFraction(ctx)
+
+	if !isFound {
+		return
+	}
+
+	balances, err := ac.fractionShareRepository.GetFractionShareBalanceList(ctx.Request.Context(), fraction.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"total_shares": fraction.TotalShares, "shares": balances}})
+}
+
+func (ac *FractionController) RedeemFraction(ctx *gin.Context) {
+	ac.insertFractionBuyout(ctx, helpers.FractionBuyoutTypeRedemption)
+}
+
+func (ac *FractionController) BuyoutFraction(ctx *gin.Context) {
+	ac.insertFractionBuyout(ctx, helpers.FractionBuyoutTypeBuyout)
+}
+
+func (ac *FractionController) insertFractionBuyout(ctx *gin.Context, buyoutType string) {
+	fraction, isFound := ac.getActiveFraction(ctx)
+
+	if !isFound {
+		return
+	}
+
+	if fraction.Status != "active" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Fraction vault is not active"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	// Validate transaction hash
+	transactionHash := ctx.DefaultPostForm("transaction_hash", "")
+
+	if transactionHash == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Transaction hash is required"})
+		return
+	}
+
+	balances, err := ac.fractionShareRepository.GetFractionShareBalanceList(ctx.Request.Context(), fraction.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	balance := helpers.GetFractionShareBalance(balances, user.(models.User).ID)
+
+	var buyout models.FractionBuyout
+
+	if buyoutType == helpers.FractionBuyoutTypeRedemption {
+		if balance < fraction.TotalShares {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Redemption requires all shares"})
+			return
+		}
+	} else {
+		// Validate price per share
+		pricePerShare, err := strconv.ParseFloat(ctx.DefaultPostForm("price_per_share", "0"), 64)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+
+		if pricePerShare < fraction.ReservePrice {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Price per share is lower than reserve price"})
+			return
+		}
+
+		// Validate amount
+		amount, err := strconv.ParseFloat(ctx.DefaultPostForm("amount", "0"), 64)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+
+		if amount < pricePerShare*float64(fraction.TotalShares-balance) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than the price of remaining shares"})
+			return
+		}
+
+		// Only a higher offer than the open ones can be placed, the highest covered offer settles when the window closes
+		for _, status := range []string{"waiting_confirmation", "confirmed"} {
+			openBuyouts, err := ac.fractionBuyoutRepository.GetFractionBuyoutList(ctx.Request.Context(), 0, 1, &fraction.ID, &status, "price_per_share", "DESC")
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+				return
+			}
+
+			if len(openBuyouts) > 0 && pricePerShare <= openBuyouts[0].PricePerShare {
+				ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Price per share must be higher than the current offer"})
+				return
+			}
+		}
+
+		buyout.PricePerShare = pricePerShare
+		buyout.Amount = amount
+	}
+
+	buyout.FractionID = fraction.ID
+	buyout.UserID = user.(models.User).ID
+	buyout.Type = buyoutType
+	buyout.Status = "waiting_confirmation"
+	buyout.TransactionHash = transactionHash
+	buyout.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	buyout.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	buyoutID, err := ac.fractionBuyoutRepository.InsertFractionBuyout(ctx.Request.Context(), buyout)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"buyout_id": buyoutID}})
+}
+
+func (ac *FractionController) GetFractionBuyoutList(ctx *gin.Context) {
+	fraction, isFound := ac.getActiveFraction(ctx)
+
+	if !isFound {
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	status := ctx.DefaultQuery("status", "")
+
+	buyouts, err := ac.fractionBuyoutRepository.GetFractionBuyoutList(ctx.Request.Context(), offset, limit, &fraction.ID, &status, "created_at", "DESC")
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	payouts, err := ac.fractionBuyoutRepository.GetFractionPayoutList(ctx.Request.Context(), 0, 1000, &fraction.ID, nil)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"buyouts": buyouts, "payouts": payouts}})
+}
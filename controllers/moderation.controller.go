@@ -0,0 +1,373 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+type ModerationController struct {
+	repository  *repositories.ModerationRepository
+	redisClient *redis.Client
+}
+
+func NewModerationController(repository *repositories.ModerationRepository, redisClient *redis.Client) *ModerationController {
+	return &ModerationController{repository, redisClient}
+}
+
+func isAdmin(ctx *gin.Context) bool {
+	user, isExist := ctx.Get("user")
+
+	return isExist && user.(models.User).Role == "admin"
+}
+
+// Moderation changes what every public list returns
+func removeModerationCache(redisClient *redis.Client, subjectType string, subjectID uuid.UUID) {
+	cacheKeys := []string{"token-*", "collection-*", "trending-list-*", "ranking-list-*", "feed-*", fmt.Sprintf("user-data-%s", subjectID)}
+
+	for _, cacheKey := range cacheKeys {
+		keys, err := redisClient.Keys(cacheKey).Result()
+
+		if err != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			redisClient.Del(key)
+		}
+	}
+}
+
+func (ac *ModerationController) InsertReport(ctx *gin.Context) {
+	var report models.Report
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	report.ReporterID = user.(models.User).ID
+
+	// Validate subject
+	report.SubjectType = ctx.PostForm("subject_type")
+
+	if !helpers.IsValidModerationSubject(report.SubjectType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Subject type is not valid"})
+		return
+	}
+
+	subjectID, err := uuid.Parse(ctx.PostForm("subject_id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Subject id is not valid"})
+		return
+	}
+
+	report.SubjectID = subjectID
+
+	if report.SubjectType == helpers.ModerationSubjectUser && report.SubjectID == report.ReporterID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "You cannot report yourself"})
+		return
+	}
+
+	// Validate reason
+	report.Reason = ctx.PostForm("reason")
+
+	if !helpers.IsValidReportReason(report.Reason) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Reason is not valid"})
+		return
+	}
+
+	description := ctx.PostForm("description")
+	report.Description = sql.NullString{String: description, Valid: description != ""}
+
+	moderationStatus, err := ac.repository.GetModerationStatus(ctx.Request.Context(), report.SubjectType, report.SubjectID)
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Subject not found"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	id, err := ac.repository.InsertReport(ctx.Request.Context(), report)
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusConflict, gin.H{"status": "failed", "error": "You have already reported this subject"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Flag the subject for review once enough users reported it
+	if moderationStatus == helpers.ModerationStatusVisible {
+		count, err := ac.repository.GetOpenReportCount(ctx.Request.Context(), report.SubjectType, report.SubjectID)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if count >= helpers.ReportFlagThreshold {
+			_, err = ac.repository.UpdateModerationStatus(ctx.Request.Context(), models.ModerationAction{
+				SubjectType: report.SubjectType,
+				SubjectID:   report.SubjectID,
+				Action:      helpers.ModerationActionFlag,
+				NewStatus:   sql.NullString{String: helpers.ModerationStatusFlagged, Valid: true},
+				Reason:      sql.NullString{String: fmt.Sprintf("Reached %d open reports", count), Valid: true},
+			})
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+				return
+			}
+
+			removeModerationCache(ac.redisClient, report.SubjectType, report.SubjectID)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"id": id}})
+}
+
+func (ac *ModerationController) GetReportList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	status := ctx.DefaultQuery("status", helpers.ReportStatusOpen)
+	subjectType := ctx.Query("subject_type")
+
+	var subjectID *uuid.UUID
+
+	if ctx.Query("subject_id") != "" {
+		id, err := uuid.Parse(ctx.Query("subject_id"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Subject id is not valid"})
+			return
+		}
+
+		subjectID = &id
+	}
+
+	reports, err := ac.repository.GetReportList(ctx.Request.Context(), offset, limit, &status, &subjectType, subjectID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"reports": reports}})
+}
+
+// UpdateReport resolves or dismisses a single report, the subject is changed with UpdateModerationStatus
+func (ac *ModerationController) UpdateReport(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	status := ctx.PostForm("status")
+
+	if status != helpers.ReportStatusResolved && status != helpers.ReportStatusDismissed {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Status is not valid"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	report, err := ac.repository.GetReportData(ctx.Request.Context(), id)
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Report not found"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	action := helpers.ModerationActionResolveReport
+
+	if status == helpers.ReportStatusDismissed {
+		action = helpers.ModerationActionDismissReport
+	}
+
+	reason := ctx.PostForm("reason")
+
+	moderationAction, err := ac.repository.UpdateReportStatus(ctx.Request.Context(), models.ModerationAction{
+		ModeratorID:    user.(models.User).ID,
+		SubjectType:    report.SubjectType,
+		SubjectID:      report.SubjectID,
+		ReportID:       report.ID,
+		Action:         action,
+		PreviousStatus: sql.NullString{String: report.Status, Valid: true},
+		NewStatus:      sql.NullString{String: status, Valid: true},
+		Reason:         sql.NullString{String: reason, Valid: reason != ""},
+	}, status)
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusConflict, gin.H{"status": "failed", "error": "Report is already closed"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"action": moderationAction}})
+}
+
+// UpdateModerationStatus flags, hides, bans or restores a token, collection or user
+func (ac *ModerationController) UpdateModerationStatus(ctx *gin.Context) {
+	subjectType := ctx.Param("type")
+
+	if !helpers.IsValidModerationSubject(subjectType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Subject type is not valid"})
+		return
+	}
+
+	subjectID, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	status := ctx.PostForm("status")
+
+	if !helpers.IsValidModerationStatus(subjectType, status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Status is not valid"})
+		return
+	}
+
+	reason := ctx.PostForm("reason")
+
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Reason is required"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	if subjectType == helpers.ModerationSubjectUser && subjectID == user.(models.User).ID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "You cannot moderate yourself"})
+		return
+	}
+
+	moderationAction, err := ac.repository.UpdateModerationStatus(ctx.Request.Context(), models.ModerationAction{
+		ModeratorID: user.(models.User).ID,
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Action:      helpers.GetModerationAction(status),
+		NewStatus:   sql.NullString{String: status, Valid: true},
+		Reason:      sql.NullString{String: reason, Valid: true},
+	})
+
+	if err == sql.ErrNoRows {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Subject not found"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	removeModerationCache(ac.redisClient, subjectType, subjectID)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"action": moderationAction}})
+}
+
+func (ac *ModerationController) GetModerationActionList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	subjectType := ctx.Query("subject_type")
+
+	var subjectID *uuid.UUID
+	var moderatorID *uuid.UUID
+
+	if ctx.Query("subject_id") != "" {
+		id, err := uuid.Parse(ctx.Query("subject_id"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Subject id is not valid"})
+			return
+		}
+
+		subjectID = &id
+	}
+
+	if ctx.Query("moderator_id") != "" {
+		id, err := uuid.Parse(ctx.Query("moderator_id"))
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Moderator id is not valid"})
+			return
+		}
+
+		moderatorID = &id
+	}
+
+	moderationActions, err := ac.repository.GetModerationActionList(ctx.Request.Context(), offset, limit, &subjectType, subjectID, moderatorID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"actions": moderationActions}})
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationController struct {
+	repository *repositories.NotificationRepository
+}
+
+func NewNotificationController(repository *repositories.NotificationRepository) *NotificationController {
+	return &NotificationController{repository}
+}
+
+func (ac *NotificationController) GetNotificationList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	unread := ctx.DefaultQuery("unread", "false") == "true"
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	notifications, err := ac.repository.GetNotificationList(ctx.Request.Context(), offset, limit, user.(models.User).ID, unread)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	unreadCount, err := ac.repository.GetUnreadNotificationCount(ctx.Request.Context(), user.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"notifications": notifications, "unread_count": unreadCount}})
+}
+
+func (ac *NotificationController) ReadNotification(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	count, err := ac.repository.ReadNotification(ctx.Request.Context(), user.(models.User).ID, &id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if count == 0 {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Unread notification not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (ac *NotificationController) ReadAllNotifications(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	count, err := ac.repository.ReadNotification(ctx.Request.Context(), user.(models.User).ID, nil)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"count": count}})
+}
+
+func (ac *NotificationController) GetNotificationPreferenceList(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	savedPreferences, err := ac.repository.GetNotificationPreferenceList(ctx.Request.Context(), user.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Types without a saved preference have both channels enabled
+	var notificationPreferences []models.NotificationPreference
+
+	for _, notificationType := range helpers.NotificationTypes {
+		notificationPreference := models.NotificationPreference{UserID: user.(models.User).ID, Type: notificationType, InApp: true, Email: true}
+
+		for _, savedPreference := range savedPreferences {
+			if savedPreference.Type == notificationType {
+				notificationPreference = savedPreference
+			}
+		}
+
+		notificationPreferences = append(notificationPreferences, notificationPreference)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"notification_preferences": notificationPreferences}})
+}
+
+func (ac *NotificationController) UpdateNotificationPreference(ctx *gin.Context) {
+	// Validate type
+	notificationType := ctx.PostForm("type")
+
+	if !helpers.IsValidNotificationType(notificationType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Notification type is not valid"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	notificationPreference, err := ac.repository.GetNotificationPreference(ctx.Request.Context(), user.(models.User).ID, notificationType)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Validate channels
+	inAppParams := ctx.PostForm("in_app")
+
+	if inAppParams != "" {
+		notificationPreference.InApp = inAppParams == "true"
+	}
+
+	emailParams := ctx.PostForm("email")
+
+	if emailParams != "" {
+		notificationPreference.Email = emailParams == "true"
+	}
+
+	notificationPreference.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.repository.UpsertNotificationPreference(ctx.Request.Context(), notificationPreference)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"notification_preference": notificationPreference}})
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
 
@@ -21,12 +22,13 @@ type OwnershipController struct {
 	repository        *repositories.OwnershipRepository
 	tokenRepository   *repositories.TokenRepository
 	rentalRepository  *repositories.RentalRepository
+	versionRepository *repositories.VersionRepository
 	web3StorageClient w3s.Client
 	redisClient       *redis.Client
 }
 
-func NewOwnershipController(repository *repositories.OwnershipRepository, tokenRepository *repositories.TokenRepository, rentalRepository *repositories.RentalRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *OwnershipController {
-	return &OwnershipController{repository, tokenRepository, rentalRepository, web3StorageClient, redisClient}
+func NewOwnershipController(repository *repositories.OwnershipRepository, tokenRepository *repositories.TokenRepository, rentalRepository *repositories.RentalRepository, versionRepository *repositories.VersionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *OwnershipController {
+	return &OwnershipController{repository, tokenRepository, rentalRepository, versionRepository, web3StorageClient, redisClient}
 }
 
 func (ac *OwnershipController) InsertOwnership(ctx *gin.Context) {
@@ -137,7 +139,7 @@ func (ac *OwnershipController) InsertOwnership(ctx *gin.Context) {
 		return
 	}
 
-	ownershipId, err := ac.repository.InsertOwnership(ownership)
+	ownershipId, err := ac.repository.InsertOwnership(ctx.Request.Context(), ownership)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -235,7 +237,7 @@ func (ac *OwnershipController) GetOwnershipList(ctx *gin.Context) {
 
 	var ownerships []models.Ownership
 
-	cacheKey := fmt.Sprintf("ownership-list-%d-%d-%s-%s-%s-%s-%s-%s-%s", offset, limit, keyword, userIDParams, user, creatorIDParams, creator, tokenIDParams, orderBy, orderOption)
+	cacheKey := fmt.Sprintf("ownership-list-%d-%d-%s-%s-%s-%s-%s-%s-%s-%s", offset, limit, keyword, userIDParams, user, creatorIDParams, creator, tokenIDParams, orderBy, orderOption)
 	cache, err := ac.redisClient.Get(cacheKey).Result()
 
 	if err != nil && err.Error() != "redis: nil" {
@@ -255,7 +257,7 @@ func (ac *OwnershipController) GetOwnershipList(ctx *gin.Context) {
 		return
 	}
 
-	ownerships, err = ac.repository.GetOwnershipList(offset, limit, keyword, userID, &user, creatorID, &creator, tokenID, "active", orderBy, orderOption)
+	ownerships, err = ac.repository.GetOwnershipList(ctx.Request.Context(), offset, limit, keyword, userID, &user, creatorID, &creator, tokenID, "active", orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -316,7 +318,7 @@ func (ac *OwnershipController) GetOwnershipData(ctx *gin.Context) {
 		return
 	}
 
-	ownership, err = ac.repository.GetOwnershipData(id)
+	ownership, err = ac.repository.GetOwnershipData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -368,14 +370,7 @@ func (ac *OwnershipController) UpdateOwnership(ctx *gin.Context) {
 		return
 	}
 
-	ownership, err := ac.repository.GetOwnershipData(id)
-
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
-		return
-	}
-
-	token, err := ac.tokenRepository.GetTokenData(id)
+	ownership, err := ac.repository.GetOwnershipData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -398,6 +393,23 @@ func (ac *OwnershipController) UpdateOwnership(ctx *gin.Context) {
 		return
 	}
 
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), tokenID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if token.Uri == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+		return
+	}
+
+	if token.Locked {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Token is locked in a fraction vault"})
+		return
+	}
+
 	// Validate user
 	userIDParams := ctx.PostForm("user_id")
 	var userID uuid.UUID
@@ -414,6 +426,9 @@ func (ac *OwnershipController) UpdateOwnership(ctx *gin.Context) {
 		return
 	}
 
+	availableForSale := ctx.DefaultPostForm("available_for_sale", "false")
+	availableForRent := ctx.DefaultPostForm("available_for_rent", "false")
+
 	// Validate sale price
 	salePrice, err := strconv.ParseFloat(ctx.DefaultPostForm("sale_price", "0"), 64)
 
@@ -430,7 +445,7 @@ func (ac *OwnershipController) UpdateOwnership(ctx *gin.Context) {
 	// Validate rent cost
 	rentCost, err := strconv.ParseFloat(ctx.DefaultPostForm("rent_cost", "0"), 64)
 
-	if token.Supply == 1 {
+	if availableForRent == "true" {
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 			return
@@ -442,38 +457,28 @@ func (ac *OwnershipController) UpdateOwnership(ctx *gin.Context) {
 		}
 	}
 
-	status := "active"
-
-	// Check if token is still in rental period
-	rentals, err := ac.rentalRepository.GetRentalList(100, 0, "", nil, nil, nil, nil, nil, nil, &tokenID, &status, "created_at", "DESC")
-
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+	// Rented units can not change owner
+	if ownership.RentedQuantity > 0 && userID != ownership.UserID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Item is still in rental period"})
 		return
 	}
 
-	for _, rental := range rentals {
-		if rental.Timestamp.Time.After(time.Now()) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Item is still in rental period"})
-			return
-		}
+	if tokenID != ownership.TokenID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token id can not be changed"})
+		return
 	}
 
-	availableForSale := ctx.DefaultPostForm("available_for_sale", "false")
-	availableForRent := ctx.DefaultPostForm("available_for_rent", "false")
-
-	ownership.PreviousID = ownership.ID
-	ownership.UserID = userID
-	ownership.TokenID = tokenID
-	ownership.SalePrice = salePrice
-	ownership.RentCost = rentCost
-	ownership.AvailableForSale = availableForSale == "true"
-	ownership.AvailableForRent = availableForRent == "true"
-	ownership.Status = "waiting_confirmation"
-	ownership.TransactionHash = transactionHash
-	ownership.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	isAvailableForSale := availableForSale == "true"
+	isAvailableForRent := availableForRent == "true"
 
-	_, err = ac.repository.InsertOwnership(ownership)
+	// Applied once the transaction is confirmed
+	_, err = ac.versionRepository.InsertPendingChange(ctx.Request.Context(), helpers.VersionResourceOwnership, ownership.ID, models.OwnershipChange{
+		UserID:           &userID,
+		SalePrice:        &salePrice,
+		RentCost:         &rentCost,
+		AvailableForSale: &isAvailableForSale,
+		AvailableForRent: &isAvailableForRent,
+	}, transactionHash)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -523,7 +528,7 @@ func (ac *OwnershipController) DeleteOwnership(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteOwnership(id)
+	err = ac.repository.DeleteOwnership(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+)
+
+type RankingController struct {
+	repository  *repositories.RankingRepository
+	redisClient *redis.Client
+}
+
+func NewRankingController(repository *repositories.RankingRepository, redisClient *redis.Client) *RankingController {
+	return &RankingController{repository, redisClient}
+}
+
+func (ac *RankingController) GetRankingList(ctx *gin.Context) {
+	rankingType := ctx.Param("type")
+
+	if !helpers.IsValidRankingType(rankingType) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	window := ctx.DefaultQuery("window", "24h")
+
+	if !helpers.IsValidRankingWindow(window) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Window is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	if err != nil || limit < 1 || limit > helpers.RankingSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Limit is not valid"})
+		return
+	}
+
+	var rankings []models.Ranking
+
+	cacheKey := fmt.Sprintf("ranking-list-%s-%s-%d", rankingType, window, limit)
+	cache, err := ac.redisClient.Get(cacheKey).Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if cache != "" && cache != "null" {
+		err := json.Unmarshal([]byte(cache), &rankings)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"type": rankingType, "window": window, "rankings": rankings}})
+		return
+	}
+
+	rankings, err = ac.repository.GetRankingList(ctx.Request.Context(), rankingType, window, limit)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	cacheBytes, err := json.Marshal(rankings)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.redisClient.Set(cacheKey, cacheBytes, 0).Err()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"type": rankingType, "window": window, "rankings": rankings}})
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
 
@@ -18,13 +19,18 @@ import (
 )
 
 type RentalController struct {
-	repository        *repositories.RentalRepository
-	web3StorageClient w3s.Client
-	redisClient       *redis.Client
+	repository            *repositories.RentalRepository
+	tokenRepository       *repositories.TokenRepository
+	ownershipRepository   *repositories.OwnershipRepository
+	rentalEventRepository *repositories.RentalEventRepository
+	feeScheduleRepository *repositories.FeeScheduleRepository
+	versionRepository     *repositories.VersionRepository
+	web3StorageClient     w3s.Client
+	redisClient           *redis.Client
 }
 
-func NewRentalController(repository *repositories.RentalRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *RentalController {
-	return &RentalController{repository, web3StorageClient, redisClient}
+func NewRentalController(repository *repositories.RentalRepository, tokenRepository *repositories.TokenRepository, ownershipRepository *repositories.OwnershipRepository, rentalEventRepository *repositories.RentalEventRepository, feeScheduleRepository *repositories.FeeScheduleRepository, versionRepository *repositories.VersionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *RentalController {
+	return &RentalController{repository, tokenRepository, ownershipRepository, rentalEventRepository, feeScheduleRepository, versionRepository, web3StorageClient, redisClient}
 }
 
 func (ac *RentalController) InsertRental(ctx *gin.Context) {
@@ -53,19 +59,11 @@ func (ac *RentalController) InsertRental(ctx *gin.Context) {
 		return
 	}
 
-	// Validate user
-	userIDParams := ctx.PostForm("user_id")
-	var userID uuid.UUID
-
-	if userIDParams != "" {
-		userID, err = uuid.Parse(userIDParams)
+	// The renter is always the authenticated user
+	user, isExist := ctx.Get("user")
 
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "User id is not valid"})
-			return
-		}
-	} else {
-		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "User id is required"})
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": "User data is not valid"})
 		return
 	}
 
@@ -111,27 +109,115 @@ func (ac *RentalController) InsertRental(ctx *gin.Context) {
 
 	days, err := strconv.Atoi(daysParams)
 
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	// Validate quantity
+	quantity, err := strconv.Atoi(ctx.DefaultPostForm("quantity", "1"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if quantity < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity must be greater than 1 or equal"})
+		return
+	}
+
+	if days < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Days must be greater than 1 or equal"})
+		return
+	}
+
+	// Validate amount
+	amountParams := ctx.PostForm("amount")
+
+	if amountParams == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is required"})
+		return
+	}
+
+	amount, err := strconv.ParseFloat(amountParams, 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	// The rent is priced from the ownership, so a refund on return is based on what was paid
+	ownership, err := ac.ownershipRepository.GetOwnershipData(ctx.Request.Context(), ownershipID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if ownership.ID != ownershipID || ownership.TokenID != tokenID || ownership.UserID != ownerID {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Ownership is not valid"})
+		return
+	}
+
+	if !ownership.AvailableForRent {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token is not available for rent"})
+		return
+	}
+
+	if quantity > ownership.Quantity-ownership.RentedQuantity {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
+		return
+	}
+
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), tokenID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	// Get marketplace fees
+	feeSchedules, err := ac.feeScheduleRepository.GetApplicableFeeScheduleList(ctx.Request.Context(), "rent", token.CategoryID, token.CollectionID, time.Now())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	rentCost := ownership.RentCost * float64(days) * float64(quantity)
+	fees, totalFee := helpers.CalculateFees(rentCost, feeSchedules)
+
+	if amount < rentCost+totalFee {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than rent cost and fees"})
+		return
+	}
+
 	var rental models.Rental
 
-	rental.UserID = userID
+	rental.UserID = user.(models.User).ID
 	rental.OwnerID = ownerID
 	rental.TokenID = tokenID
 	rental.OwnershipID = ownershipID
-	rental.Timestamp = sql.NullTime{Time: time.Now().Add(time.Duration(1e9 * 3600 * 24 * days)), Valid: true}
-	rental.Status = "waiting_confirmation"
+	rental.Quantity = quantity
+	rental.Timestamp = sql.NullTime{Time: helpers.GetRentalEndTime(time.Now(), days), Valid: true}
+	rental.Days = days
+	rental.Amount = rentCost
+	rental.Status = helpers.RentalStatusPending
 	rental.TransactionHash = transactionHash
-	rental.UpdatedAt = sql.NullTime{}
-	rental.CreatedAt = sql.NullTime{}
+	rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	rental.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// Checked again under the ownership lock, a concurrent rental may have taken the units since
+	rentalId, isAvailable, err := ac.repository.InsertRental(ctx.Request.Context(), rental)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	rentalId, err := ac.repository.InsertRental(rental)
-
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+	if !isAvailable {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
 		return
 	}
 
@@ -153,7 +239,7 @@ func (ac *RentalController) InsertRental(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"rental_id": rentalId}})
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"rental_id": rentalId, "fees": fees}})
 }
 
 func (ac *RentalController) GetRentalList(ctx *gin.Context) {
@@ -178,12 +264,13 @@ func (ac *RentalController) GetRentalList(ctx *gin.Context) {
 	creator := ctx.DefaultQuery("creator", "")
 	creatorIDParams := ctx.DefaultQuery("creator_id", "")
 	tokenIDParams := ctx.DefaultQuery("token_id", "")
+	status := ctx.DefaultQuery("status", helpers.RentalStatusActive)
 
 	keyword := ctx.DefaultQuery("keyword", "")
 	orderBy := ctx.DefaultQuery("order_by", "created_at")
 	orderOption := ctx.DefaultQuery("order_option", "ASC")
 
-	if (userIDParams == "" && ownerIDParams == "" && ownerIDParams == "") && (user == "" && owner == "" && creator == "") {
+	if (userIDParams == "" && ownerIDParams == "" && tokenIDParams == "") && (user == "" && owner == "" && creator == "") {
 		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "User id/user/owner id/owner/token id/creator are required"})
 		return
 	}
@@ -242,7 +329,7 @@ func (ac *RentalController) GetRentalList(ctx *gin.Context) {
 
 	var rentals []models.Rental
 
-	cacheKey := fmt.Sprintf("rental-list-%d-%d-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s", offset, limit, keyword, userIDParams, user, ownerIDParams, owner, creatorIDParams, creator, tokenIDParams, orderBy, orderOption, userID)
+	cacheKey := fmt.Sprintf("rental-list-%d-%d-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s", offset, limit, keyword, userIDParams, user, ownerIDParams, owner, creatorIDParams, creator, tokenIDParams, status, orderBy, orderOption)
 	cache, err := ac.redisClient.Get(cacheKey).Result()
 
 	if err != nil && err.Error() != "redis: nil" {
@@ -262,9 +349,7 @@ func (ac *RentalController) GetRentalList(ctx *gin.Context) {
 		return
 	}
 
-	status := "active"
-
-	rentals, err = ac.repository.GetRentalList(offset, limit, keyword, userID, &user, ownerID, &owner, creatorID, &creator, tokenID, &status, orderBy, orderOption)
+	rentals, err = ac.repository.GetRentalList(ctx.Request.Context(), offset, limit, keyword, userID, &user, ownerID, &owner, creatorID, &creator, tokenID, &status, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -325,7 +410,7 @@ func (ac *RentalController) GetRentalData(ctx *gin.Context) {
 		return
 	}
 
-	rental, err = ac.repository.GetRentalData(id)
+	rental, err = ac.repository.GetRentalData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -369,7 +454,7 @@ func (ac *RentalController) UpdateRental(ctx *gin.Context) {
 		return
 	}
 
-	rental, err := ac.repository.GetRentalData(id)
+	rental, err := ac.repository.GetRentalData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -443,15 +528,20 @@ func (ac *RentalController) UpdateRental(ctx *gin.Context) {
 	rental.TokenID = tokenID
 	rental.OwnershipID = ownershipID
 	// rental.Timestamp = sql.NullTime{}.Time .Now().Add(time.Duration(1e9 * 3600 * 24 * days))
-	rental.UpdatedAt = sql.NullTime{}
+	rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.repository.UpdateRental(rental.ID, rental)
+	isAvailable, err := ac.repository.UpdateAvailableRental(ctx.Request.Context(), rental)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
 	}
 
+	if !isAvailable {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Quantity is higher than available units"})
+		return
+	}
+
 	cacheKey := fmt.Sprintf("rental-data-%s", id)
 	err = ac.redisClient.Del(cacheKey, idParam).Err()
 
@@ -495,7 +585,7 @@ func (ac *RentalController) DeleteRental(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteRental(id)
+	err = ac.repository.DeleteRental(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -529,3 +619,364 @@ func (ac *RentalController) DeleteRental(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Rental has been deleted"})
 }
+
+func (ac *RentalController) removeRentalCache() error {
+	keys, err := ac.redisClient.Keys("rental-*").Result()
+
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err = ac.redisClient.Del(key).Err()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ac *RentalController) getRental(ctx *gin.Context) (models.Rental, bool) {
+	var rental models.Rental
+
+	idParam := ctx.Param("id")
+
+	if idParam == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id parameter is required"})
+		return rental, false
+	}
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return rental, false
+	}
+
+	rental, err = ac.repository.GetRentalData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return rental, false
+	}
+
+	if rental.Status == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Rental not found"})
+		return rental, false
+	}
+
+	return rental, true
+}
+
+func (ac *RentalController) ExtendRental(ctx *gin.Context) {
+	rental, isFound := ac.getRental(ctx)
+
+	if !isFound {
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	if rental.UserID != user.(models.User).ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only renter can extend the rental"})
+		return
+	}
+
+	if !helpers.IsValidRentalTransition(rental.Status, helpers.RentalStatusExtended) || !rental.Timestamp.Time.After(time.Now()) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Rental can not be extended"})
+		return
+	}
+
+	// Validate transaction hash
+	transactionHash := ctx.DefaultPostForm("transaction_hash", "")
+
+	if transactionHash == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Transaction hash is required"})
+		return
+	}
+
+	// Validate days
+	days, err := strconv.Atoi(ctx.DefaultPostForm("days", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if days < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Days must be greater than 1 or equal"})
+		return
+	}
+
+	// Validate amount
+	amount, err := strconv.ParseFloat(ctx.DefaultPostForm("amount", "0"), 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	ownership, err := ac.ownershipRepository.GetOwnershipData(ctx.Request.Context(), rental.OwnershipID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if !ownership.AvailableForRent {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token is not available for rent"})
+		return
+	}
+
+	if amount < ownership.RentCost*float64(days)*float64(rental.Quantity) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than rent cost"})
+		return
+	}
+
+	// The extension is added to the rental once the transaction is confirmed
+	timestamp := helpers.GetRentalEndTime(rental.Timestamp.Time, days)
+	status := helpers.RentalStatusExtended
+
+	changeID, err := ac.versionRepository.InsertPendingChange(ctx.Request.Context(), helpers.VersionResourceRental, rental.ID, models.RentalChange{
+		Timestamp:    &timestamp,
+		ExtendDays:   &days,
+		ExtendAmount: &amount,
+		Status:       &status,
+	}, transactionHash)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeRentalCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"change_id": changeID, "timestamp": timestamp}})
+}
+
+func (ac *RentalController) ReturnRental(ctx *gin.Context) {
+	rental, isFound := ac.getRental(ctx)
+
+	if !isFound {
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	if rental.UserID != user.(models.User).ID && rental.OwnerID != user.(models.User).ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only renter or owner can return the rental"})
+		return
+	}
+
+	if !helpers.IsValidRentalTransition(rental.Status, helpers.RentalStatusReturned) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Rental can not be returned"})
+		return
+	}
+
+	fromStatus := rental.Status
+
+	rental.RefundAmount = helpers.CalculateRentalRefund(rental.Amount, rental.StartedAt.Time, rental.Timestamp.Time, time.Now())
+	rental.EndedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	rental.Status = helpers.RentalStatusReturned
+	rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	var refund models.RentalRefund
+
+	refund.RentalID = rental.ID
+	refund.UserID = rental.UserID
+	refund.OwnerID = rental.OwnerID
+	refund.Amount = rental.RefundAmount
+	refund.Status = "pending"
+	refund.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	refund.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	isReturned, err := ac.repository.ReturnRental(ctx.Request.Context(), rental, refund)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// The rental expired or was returned by the other party in the meantime
+	if !isReturned {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Rental can not be returned"})
+		return
+	}
+
+	_, err = ac.rentalEventRepository.InsertRentalEvent(ctx.Request.Context(), helpers.NewRentalEvent(rental, fromStatus, rental.Status, user.(models.User).ID, fmt.Sprintf("Returned early with refund %v", rental.RefundAmount)))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeRentalCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"rental": rental, "refund_amount": rental.RefundAmount}})
+}
+
+func (ac *RentalController) CancelRental(ctx *gin.Context) {
+	rental, isFound := ac.getRental(ctx)
+
+	if !isFound {
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	if rental.UserID != user.(models.User).ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only renter can cancel the rental"})
+		return
+	}
+
+	if !helpers.IsValidRentalTransition(rental.Status, helpers.RentalStatusCancelled) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Only pending rental can be cancelled"})
+		return
+	}
+
+	fromStatus := rental.Status
+
+	rental.EndedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	rental.Status = helpers.RentalStatusCancelled
+	rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err := ac.repository.UpdateRental(ctx.Request.Context(), rental.ID, rental)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	_, err = ac.rentalEventRepository.InsertRentalEvent(ctx.Request.Context(), helpers.NewRentalEvent(rental, fromStatus, rental.Status, user.(models.User).ID, "Cancelled by renter"))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.removeRentalCache()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Rental has been cancelled"})
+}
+
+func (ac *RentalController) GetRentalEventList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	var rentalID *uuid.UUID
+
+	rentalIDParams := ctx.DefaultQuery("rental_id", "")
+
+	if rentalIDParams != "" {
+		rentalIDConversion, err := uuid.Parse(rentalIDParams)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Rental id is not valid"})
+			return
+		}
+
+		rentalID = &rentalIDConversion
+	}
+
+	userID := user.(models.User).ID
+
+	rentalEvents, err := ac.rentalEventRepository.GetRentalEventList(ctx.Request.Context(), offset, limit, rentalID, &userID, "DESC")
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"rental_events": rentalEvents}})
+}
+
+func (ac *RentalController) GetRentalRefundList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	userID := user.(models.User).ID
+
+	// Renters see what they get back, owners what they owe
+	var rentalRefunds []models.RentalRefund
+
+	if ctx.DefaultQuery("as", "renter") == "owner" {
+		rentalRefunds, err = ac.repository.GetRentalRefundList(ctx.Request.Context(), offset, limit, nil, &userID)
+	} else {
+		rentalRefunds, err = ac.repository.GetRentalRefundList(ctx.Request.Context(), offset, limit, &userID, nil)
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"rental_refunds": rentalRefunds}})
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"net/http"
+
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+)
+
+type StatisticController struct {
+	repository  *repositories.StatisticRepository
+	redisClient *redis.Client
+}
+
+func NewStatisticController(repository *repositories.StatisticRepository, redisClient *redis.Client) *StatisticController {
+	return &StatisticController{repository, redisClient}
+}
+
+func (ac *StatisticController) GetStatisticDiscrepancyList(ctx *gin.Context) {
+	statisticDiscrepancies, err := ac.repository.GetStatisticDiscrepancyList(ctx.Request.Context())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"discrepancies": statisticDiscrepancies}})
+}
+
+func (ac *StatisticController) ReconcileStatistics(ctx *gin.Context) {
+	statisticDiscrepancies, err := ac.repository.GetStatisticDiscrepancyList(ctx.Request.Context())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	tokenCount, err := ac.repository.ReconcileTokenStatistics(ctx.Request.Context(), nil)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	collectionCount, err := ac.repository.ReconcileCollectionStatistics(ctx.Request.Context(), nil)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Remove token and collection cache
+	for _, cacheKey := range []string{"token-*", "collection-*"} {
+		keys, err := ac.redisClient.Keys(cacheKey).Result()
+
+		if err != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			ac.redisClient.Del(key)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"discrepancies": statisticDiscrepancies, "repaired_tokens": tokenCount, "repaired_collections": collectionCount}})
+}
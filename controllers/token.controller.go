@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"context"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
@@ -12,9 +11,11 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/events"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
+	"metaedu-marketplace/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
@@ -23,16 +24,21 @@ import (
 )
 
 type TokenController struct {
-	tokenRepository       *repositories.TokenRepository
-	ownershipRepository   *repositories.OwnershipRepository
-	collectionRepository  *repositories.CollectionRepository
-	transactionRepository *repositories.TransactionRepository
-	web3StorageClient     w3s.Client
-	redisClient           *redis.Client
+	tokenRepository        *repositories.TokenRepository
+	ownershipRepository    *repositories.OwnershipRepository
+	collectionRepository   *repositories.CollectionRepository
+	transactionRepository  *repositories.TransactionRepository
+	mintVoucherRepository  *repositories.MintVoucherRepository
+	priceHistoryRepository *repositories.PriceHistoryRepository
+	moderationRepository   *repositories.ModerationRepository
+	voucherVerifier        *utils.VoucherVerifier
+	viewTracker            *utils.ViewTracker
+	web3StorageClient      w3s.Client
+	redisClient            *redis.Client
 }
 
-func NewTokenController(tokenRepository *repositories.TokenRepository, ownershipRepository *repositories.OwnershipRepository, collectionRepository *repositories.CollectionRepository, transactionRepository *repositories.TransactionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *TokenController {
-	return &TokenController{tokenRepository, ownershipRepository, collectionRepository, transactionRepository, web3StorageClient, redisClient}
+func NewTokenController(tokenRepository *repositories.TokenRepository, ownershipRepository *repositories.OwnershipRepository, collectionRepository *repositories.CollectionRepository, transactionRepository *repositories.TransactionRepository, mintVoucherRepository *repositories.MintVoucherRepository, priceHistoryRepository *repositories.PriceHistoryRepository, moderationRepository *repositories.ModerationRepository, voucherVerifier *utils.VoucherVerifier, viewTracker *utils.ViewTracker, web3StorageClient w3s.Client, redisClient *redis.Client) *TokenController {
+	return &TokenController{tokenRepository, ownershipRepository, collectionRepository, transactionRepository, mintVoucherRepository, priceHistoryRepository, moderationRepository, voucherVerifier, viewTracker, web3StorageClient, redisClient}
 }
 
 func (ac *TokenController) InsertToken(ctx *gin.Context) {
@@ -139,6 +145,9 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 		return
 	}
 
+	// Lazy tokens are listed before they are minted
+	lazy := ctx.DefaultPostForm("lazy", "false") == "true"
+
 	// Check if user is exist in request
 	user, isExist := ctx.Get("user")
 
@@ -172,7 +181,7 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 			return
 		}
 
-		collection, err := ac.collectionRepository.GetCollectionData(collectionID)
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), collectionID)
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err})
@@ -183,18 +192,6 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "User has no access to add token to this collection"})
 			return
 		}
-
-		collection.PreviousID = collection.ID
-		collection.NumberOfItems = sql.NullInt64{Int64: collection.NumberOfItems.Int64 + 1, Valid: true}
-		collection.Status = sql.NullString{String: "waiting_confirmation", Valid: true}
-		collection.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-
-		_, err = ac.collectionRepository.InsertCollection(collection)
-
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err})
-			return
-		}
 	}
 
 	// Validate fraction
@@ -217,7 +214,7 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 		return
 	}
 
-	imageCid, err := ac.web3StorageClient.Put(context.Background(), imageFile)
+	imageCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), imageFile)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
@@ -231,7 +228,7 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 		return
 	}
 
-	tokenIndex, err := ac.tokenRepository.GetLastTokenIndex()
+	tokenIndex, err := ac.tokenRepository.GetLastTokenIndex(ctx.Request.Context())
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -253,6 +250,10 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 	token.CreatorID = user.(models.User).ID
 	token.Attributes = attributes
 	token.Status = "waiting_confirmation"
+
+	if lazy {
+		token.Status = "lazy"
+	}
 	token.TransactionHash = ""
 	token.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	token.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
@@ -301,7 +302,7 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 
 	uriFileName := path.Base(tmpUriFileName)
 
-	uriCid, err := ac.web3StorageClient.Put(context.Background(), uriFile)
+	uriCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), uriFile)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
@@ -317,7 +318,7 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 
 	token.Uri = uriUrl
 
-	token, err = ac.tokenRepository.InsertToken(token, attributesBytes)
+	token, err = ac.tokenRepository.InsertToken(ctx.Request.Context(), token, attributesBytes)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -353,10 +354,14 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 	ownership.AvailableForSale = false
 	ownership.Status = "waiting_confirmation"
 	ownership.TransactionHash = ""
+
+	if lazy {
+		ownership.Status = "lazy"
+	}
 	ownership.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	ownership.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	ownershipIDResult, err := ac.ownershipRepository.InsertOwnership(ownership)
+	ownershipIDResult, err := ac.ownershipRepository.InsertOwnership(ctx.Request.Context(), ownership)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -397,6 +402,17 @@ func (ac *TokenController) InsertToken(ctx *gin.Context) {
 		}
 	}
 
+	eventType := "token.pending"
+	topics := []string{events.UserTopic(token.CreatorID), events.TokenTopic(token.ID), events.CollectionTopic(token.CollectionID)}
+
+	// A lazy token is listed right away, a pending one only concerns its creator until it is mined
+	if lazy {
+		eventType = "token.lazy"
+		topics = append(topics, events.GlobalTopic)
+	}
+
+	events.Publish(ac.redisClient, eventType, gin.H{"token_id": token.ID, "title": token.Title}, topics...)
+
 	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"token": token, "ownership_id": ownershipIDResult}})
 }
 
@@ -482,9 +498,17 @@ func (ac *TokenController) GetTokenList(ctx *gin.Context) {
 	orderBy := ctx.DefaultQuery("order_by", "created_at")
 	orderOption := ctx.DefaultQuery("order_option", "ASC")
 
+	// Validate status
+	status := ctx.DefaultQuery("status", "active")
+
+	if status != "active" && status != "lazy" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Status is not valid"})
+		return
+	}
+
 	var tokens []models.Token
 
-	cacheKey := fmt.Sprintf("token-list-%d-%d-%s-%s-%s-%d-%d-%s-%s-%s-%s", offset, limit, keyword, orderBy, orderOption, minPrice, maxPrice, categoryIDParams, collectionIDParams, creatorIDParams, creator)
+	cacheKey := fmt.Sprintf("token-list-%d-%d-%s-%s-%s-%d-%d-%s-%s-%s-%s-%s", offset, limit, keyword, orderBy, orderOption, minPrice, maxPrice, categoryIDParams, collectionIDParams, creatorIDParams, creator, status)
 	cache, err := ac.redisClient.Get(cacheKey).Result()
 
 	if err != nil && err.Error() != "redis: nil" {
@@ -504,9 +528,7 @@ func (ac *TokenController) GetTokenList(ctx *gin.Context) {
 		return
 	}
 
-	status := "active"
-
-	tokens, err = ac.tokenRepository.GetTokenList(offset, limit, keyword, categoryID, collectionID, creatorID, &creator, minPrice, maxPrice, &status, orderBy, orderOption)
+	tokens, err = ac.tokenRepository.GetTokenList(ctx.Request.Context(), offset, limit, keyword, categoryID, collectionID, creatorID, &creator, minPrice, maxPrice, &status, true, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -563,23 +585,12 @@ func (ac *TokenController) GetTokenData(ctx *gin.Context) {
 			return
 		}
 
-		token.Views = token.Views + 1
-
-		err = ac.tokenRepository.UpdateToken(token.ID, token)
-
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
-			return
-		}
-
-		cacheBytes, err := json.Marshal(token)
-
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		if helpers.IsTokenHidden(token) && !isAdmin(ctx) {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
 			return
 		}
 
-		err = ac.redisClient.Set(cacheKey, cacheBytes, 0).Err()
+		_, err = ac.viewTracker.Track(utils.ViewTypeToken, token.ID, getViewer(ctx))
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -590,7 +601,7 @@ func (ac *TokenController) GetTokenData(ctx *gin.Context) {
 		return
 	}
 
-	token, err = ac.tokenRepository.GetTokenData(id)
+	token, err = ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -616,9 +627,12 @@ func (ac *TokenController) GetTokenData(ctx *gin.Context) {
 		return
 	}
 
-	token.Views = token.Views + 1
+	if helpers.IsTokenHidden(token) && !isAdmin(ctx) {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+		return
+	}
 
-	err = ac.tokenRepository.UpdateToken(token.ID, token)
+	_, err = ac.viewTracker.Track(utils.ViewTypeToken, token.ID, getViewer(ctx))
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -660,7 +674,7 @@ func (ac *TokenController) UpdateToken(ctx *gin.Context) {
 		return
 	}
 
-	token, err := ac.tokenRepository.GetTokenData(id)
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -689,7 +703,7 @@ func (ac *TokenController) UpdateToken(ctx *gin.Context) {
 	token.TransactionHash = transactionHash
 	token.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.tokenRepository.UpdateToken(token.ID, token)
+	err = ac.tokenRepository.UpdateToken(ctx.Request.Context(), token.ID, token)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -697,31 +711,38 @@ func (ac *TokenController) UpdateToken(ctx *gin.Context) {
 	}
 
 	// Get ownership data
-	ownership, err := ac.ownershipRepository.GetOwnershipByTokenAndUser(token.ID, user.(models.User).ID)
+	ownership, err := ac.ownershipRepository.GetOwnershipByTokenAndUser(ctx.Request.Context(), token.ID, user.(models.User).ID)
 
 	// Update ownership transaction hash
 	ownership.TransactionHash = transactionHash
 
-	err = ac.ownershipRepository.UpdateOwnership(ownership.ID, ownership)
+	err = ac.ownershipRepository.UpdateOwnership(ctx.Request.Context(), ownership.ID, ownership)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	// Get collection data
+	// A collection waiting for the same mint is confirmed with the token
 	if token.CollectionID != helpers.GetEmptyUUID() {
-		collection, err := ac.collectionRepository.GetPendingCollectionData(token.CollectionID)
-
-		// Update ownership transaction hash
-		collection.TransactionHash = sql.NullString{String: transactionHash, Valid: true}
-
-		err = ac.collectionRepository.UpdateCollection(collection.ID, collection)
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), token.CollectionID)
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 			return
 		}
+
+		if collection.Status.String == "waiting_confirmation" && collection.TransactionHash.String == "" {
+			// Update collection transaction hash
+			collection.TransactionHash = sql.NullString{String: transactionHash, Valid: true}
+
+			err = ac.collectionRepository.UpdateCollection(ctx.Request.Context(), collection.ID, collection)
+
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+				return
+			}
+		}
 	}
 
 	// Remove token cache
@@ -794,7 +815,29 @@ func (ac *TokenController) DeleteToken(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.tokenRepository.DeleteToken(id)
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	err = ac.tokenRepository.DeleteToken(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	reason := ctx.Query("reason")
+
+	_, err = ac.moderationRepository.InsertModerationAction(ctx.Request.Context(), models.ModerationAction{
+		ModeratorID: user.(models.User).ID,
+		SubjectType: helpers.ModerationSubjectToken,
+		SubjectID:   id,
+		Action:      helpers.ModerationActionDelete,
+		Reason:      sql.NullString{String: reason, Valid: reason != ""},
+	})
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -892,7 +935,7 @@ func (ac *TokenController) GetTokenTransactionList(ctx *gin.Context) {
 	}
 
 	// Get transaction list data from repository
-	transactions, err = ac.transactionRepository.GetTransactionListByToken(limit, offset, id, &status, orderBy, orderOption)
+	transactions, err = ac.transactionRepository.GetTransactionListByToken(ctx.Request.Context(), limit, offset, id, &status, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -916,3 +959,270 @@ func (ac *TokenController) GetTokenTransactionList(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"transactions": transactions}})
 }
+
+func (ac *TokenController) InsertMintVoucher(ctx *gin.Context) {
+	// Validate id
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Token id is not valid"})
+		return
+	}
+
+	// Validate price
+	price, err := strconv.ParseFloat(ctx.DefaultPostForm("price", "0"), 64)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if price <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Price must be greater than 0"})
+		return
+	}
+
+	// Validate royalty in basis points
+	royalty, err := strconv.Atoi(ctx.DefaultPostForm("royalty", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	if royalty < 0 || royalty > 10000 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Royalty must be between 0 and 10000"})
+		return
+	}
+
+	// Validate nonce
+	nonce := ctx.PostForm("nonce")
+
+	if _, err := strconv.ParseUint(nonce, 10, 64); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Nonce is not valid"})
+		return
+	}
+
+	// Validate signature
+	signature := ctx.PostForm("signature")
+
+	if signature == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Signature is required"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if token.Uri == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+		return
+	}
+
+	if token.CreatorID != user.(models.User).ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "User has no access to sign this token"})
+		return
+	}
+
+	if token.Status != "lazy" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Token is not lazy minted"})
+		return
+	}
+
+	existingVoucher, err := ac.mintVoucherRepository.GetMintVoucherByTokenID(ctx.Request.Context(), token.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if existingVoucher.Status != "" && existingVoucher.Status != "pending" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Voucher is already redeemed"})
+		return
+	}
+
+	isNonceUsed, err := ac.mintVoucherRepository.IsMintVoucherNonceUsed(ctx.Request.Context(), token.CreatorID, nonce, token.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if isNonceUsed {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Nonce is already used by another voucher"})
+		return
+	}
+
+	var voucher models.MintVoucher
+
+	voucher.TokenID = token.ID
+	voucher.CreatorID = token.CreatorID
+	voucher.TokenIndex = token.TokenIndex
+	voucher.Uri = token.Uri
+	voucher.Supply = token.Supply
+	voucher.Price = price
+	voucher.Royalty = royalty
+	voucher.Nonce = nonce
+	voucher.Signature = signature
+	voucher.Status = "pending"
+	voucher.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// Verify creator signature
+	err = ac.voucherVerifier.Verify(user.(models.User).Address, utils.MintVoucherMessage{
+		TokenIndex: voucher.TokenIndex,
+		Uri:        voucher.Uri,
+		Supply:     voucher.Supply,
+		Price:      utils.ToWei(voucher.Price),
+		Royalty:    voucher.Royalty,
+		Nonce:      voucher.Nonce,
+	}, signature)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Voucher signature is not valid"})
+		return
+	}
+
+	var voucherID string
+
+	// Signing again replaces the pending voucher, so a creator can change the price before anyone redeems it
+	if existingVoucher.Status == "pending" {
+		isUpdated, err := ac.mintVoucherRepository.UpdatePendingMintVoucher(ctx.Request.Context(), existingVoucher.ID, voucher)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if !isUpdated {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Voucher is already redeemed"})
+			return
+		}
+
+		voucherID = existingVoucher.ID.String()
+	} else {
+		voucherID, err = ac.mintVoucherRepository.InsertMintVoucher(ctx.Request.Context(), voucher)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+	}
+
+	// List the creator ownership at the voucher price
+	ownership, err := ac.ownershipRepository.GetOwnershipByTokenAndUser(ctx.Request.Context(), token.ID, token.CreatorID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ownership.SalePrice = price
+	ownership.AvailableForSale = true
+	ownership.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.ownershipRepository.UpdateOwnership(ctx.Request.Context(), ownership.ID, ownership)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ac.removeTokenCache()
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"voucher_id": voucherID}})
+}
+
+func (ac *TokenController) GetMintVoucher(ctx *gin.Context) {
+	// Validate id
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Token id is not valid"})
+		return
+	}
+
+	voucher, err := ac.mintVoucherRepository.GetMintVoucherByTokenID(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if voucher.Status == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Voucher not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"voucher": voucher}})
+}
+
+func (ac *TokenController) removeTokenCache() {
+	for _, cacheKey := range []string{"token-*", "ownership-*"} {
+		keys, err := ac.redisClient.Keys(cacheKey).Result()
+
+		if err != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			ac.redisClient.Del(key)
+		}
+	}
+}
+
+func (ac *TokenController) GetTokenPriceHistory(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+
+	if err != nil || days < 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Days is not valid"})
+		return
+	}
+
+	pricePointType := ctx.Query("type")
+
+	if pricePointType != "" && pricePointType != helpers.PricePointTypeSale && pricePointType != helpers.PricePointTypeRent {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	pricePoints, err := ac.priceHistoryRepository.GetPricePointList(ctx.Request.Context(), offset, limit, id, &pricePointType, time.Now().AddDate(0, 0, -days))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"price_history": pricePoints}})
+}
@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -52,7 +51,7 @@ func (ac *TokenCategoryController) InsertTokenCategory(ctx *gin.Context) {
 
 	iconFileName := path.Base(uploadedIcon.Filename)
 
-	iconCid, err := ac.web3StorageClient.Put(context.Background(), iconFile)
+	iconCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), iconFile)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
@@ -80,7 +79,7 @@ func (ac *TokenCategoryController) InsertTokenCategory(ctx *gin.Context) {
 		return
 	}
 
-	tokenCategoryId, err := ac.repository.InsertTokenCategory(tokenCategory)
+	tokenCategoryId, err := ac.repository.InsertTokenCategory(ctx.Request.Context(), tokenCategory)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
@@ -150,7 +149,7 @@ func (ac *TokenCategoryController) GetTokenCategoryList(ctx *gin.Context) {
 		return
 	}
 
-	tokenCategories, err = ac.repository.GetTokenCategoryList(offset, limit, keyword, &status, orderBy, orderOption)
+	tokenCategories, err = ac.repository.GetTokenCategoryList(ctx.Request.Context(), offset, limit, keyword, &status, orderBy, orderOption)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -211,7 +210,7 @@ func (ac *TokenCategoryController) GetTokenCategoryData(ctx *gin.Context) {
 		return
 	}
 
-	tokenCategory, err = ac.repository.GetTokenCategoryData(id)
+	tokenCategory, err = ac.repository.GetTokenCategoryData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -255,7 +254,7 @@ func (ac *TokenCategoryController) UpdateTokenCategory(ctx *gin.Context) {
 		return
 	}
 
-	tokenCategory, err := ac.repository.GetTokenCategoryData(id)
+	tokenCategory, err := ac.repository.GetTokenCategoryData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -266,7 +265,7 @@ func (ac *TokenCategoryController) UpdateTokenCategory(ctx *gin.Context) {
 	tokenCategory.Description = ctx.DefaultPostForm("description", tokenCategory.Description)
 	tokenCategory.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.repository.UpdateTokenCategory(tokenCategory.ID, tokenCategory)
+	err = ac.repository.UpdateTokenCategory(ctx.Request.Context(), tokenCategory.ID, tokenCategory)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -316,7 +315,7 @@ func (ac *TokenCategoryController) DeleteTokenCategory(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteTokenCategory(id)
+	err = ac.repository.DeleteTokenCategory(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
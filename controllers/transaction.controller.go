@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"metaedu-marketplace/events"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
@@ -19,17 +20,21 @@ import (
 )
 
 type TransactionController struct {
-	repository           *repositories.TransactionRepository
-	tokenRepository      *repositories.TokenRepository
-	collectionRepository *repositories.CollectionRepository
-	ownershipRepository  *repositories.OwnershipRepository
-	rentalRepository     *repositories.RentalRepository
-	web3StorageClient    w3s.Client
-	redisClient          *redis.Client
+	repository               *repositories.TransactionRepository
+	tokenRepository          *repositories.TokenRepository
+	collectionRepository     *repositories.CollectionRepository
+	ownershipRepository      *repositories.OwnershipRepository
+	rentalRepository         *repositories.RentalRepository
+	feeScheduleRepository    *repositories.FeeScheduleRepository
+	transactionFeeRepository *repositories.TransactionFeeRepository
+	mintVoucherRepository    *repositories.MintVoucherRepository
+	versionRepository        *repositories.VersionRepository
+	web3StorageClient        w3s.Client
+	redisClient              *redis.Client
 }
 
-func NewTransactionController(repository *repositories.TransactionRepository, tokenRepository *repositories.TokenRepository, collectionRepository *repositories.CollectionRepository, ownershipRepository *repositories.OwnershipRepository, rentalRepository *repositories.RentalRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *TransactionController {
-	return &TransactionController{repository, tokenRepository, collectionRepository, ownershipRepository, rentalRepository, web3StorageClient, redisClient}
+func NewTransactionController(repository *repositories.TransactionRepository, tokenRepository *repositories.TokenRepository, collectionRepository *repositories.CollectionRepository, ownershipRepository *repositories.OwnershipRepository, rentalRepository *repositories.RentalRepository, feeScheduleRepository *repositories.FeeScheduleRepository, transactionFeeRepository *repositories.TransactionFeeRepository, mintVoucherRepository *repositories.MintVoucherRepository, versionRepository *repositories.VersionRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *TransactionController {
+	return &TransactionController{repository, tokenRepository, collectionRepository, ownershipRepository, rentalRepository, feeScheduleRepository, transactionFeeRepository, mintVoucherRepository, versionRepository, web3StorageClient, redisClient}
 }
 
 func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
@@ -148,27 +153,51 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		return
 	}
 
-	status := "active"
-
-	// Check if token is still in rental period
-	rentals, err := ac.rentalRepository.GetRentalList(100, 0, "", nil, nil, nil, nil, nil, nil, &tokenID, &status, "created_at", "DESC")
+	token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), tokenID)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	for _, rental := range rentals {
-		if rental.Timestamp.Time.After(time.Now()) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Item still in rental period"})
+	if token.Locked {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token is locked in a fraction vault"})
+		return
+	}
+
+	// Lazy tokens are minted by redeeming the creator voucher on purchase
+	if token.Status == "lazy" {
+		if transactionType != "purchase" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Lazy token can only be purchased"})
+			return
+		}
+
+		voucher, err := ac.mintVoucherRepository.GetMintVoucherByTokenID(ctx.Request.Context(), token.ID)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+
+		if voucher.Status != "pending" {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Token has no mint voucher"})
 			return
 		}
 	}
 
+	// Get marketplace fees
+	feeSchedules, err := ac.feeScheduleRepository.GetApplicableFeeScheduleList(ctx.Request.Context(), transactionType, token.CategoryID, token.CollectionID, time.Now())
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
 	var transaction models.Transaction
+	var fees []models.TransactionFee
 
 	if transactionType == "purchase" {
-		ownership, err := ac.ownershipRepository.GetOwnershipData(ownershipID)
+		ownership, err := ac.ownershipRepository.GetOwnershipData(ctx.Request.Context(), ownershipID)
 
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err})
@@ -180,30 +209,47 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 			return
 		}
 
-		if quantity > ownership.Quantity {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is not valid"})
+		// Rented units can not be sold, checked again with the unconfirmed sales under the ownership lock
+		if quantity > ownership.Quantity-ownership.RentedQuantity {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
+			return
+		}
+
+		// Units and fraction shares move from the owner to the buyer on confirmation, so neither side comes from the client
+		if userFromID != helpers.GetEmptyUUID() && userFromID != ownership.UserID {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "User from id is not the owner"})
 			return
 		}
 
-		if amount < ownership.SalePrice*float64(quantity) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than sale price"})
+		if userToID != helpers.GetEmptyUUID() && userToID != user.(models.User).ID {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "User to id is not the buyer"})
 			return
 		}
 
-		ownership.PreviousID = ownership.ID
-		ownership.Quantity = ownership.Quantity - quantity
-		ownership.Status = "waiting_confirmation"
-		ownership.TransactionHash = transactionHash
+		userFromID = ownership.UserID
+		userToID = user.(models.User).ID
 
-		_, err = ac.ownershipRepository.InsertOwnership(ownership)
+		var totalFee float64
+		fees, totalFee = helpers.CalculateFees(ownership.SalePrice*float64(quantity), feeSchedules)
+
+		if amount < ownership.SalePrice*float64(quantity)+totalFee {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than sale price and fees"})
+			return
+		}
+
+		isAvailable, err := ac.ownershipRepository.InsertPendingSale(ctx.Request.Context(), ownership.ID, quantity, transactionHash)
 
 		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			return
+		}
+
+		if !isAvailable {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
 			return
 		}
 
 		var newOwnership models.Ownership
-		newOwnership.PreviousID = uuid.UUID{}
 		newOwnership.UserID = user.(models.User).ID
 		newOwnership.TokenID = tokenID
 		newOwnership.Quantity = quantity
@@ -214,7 +260,7 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		newOwnership.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 		newOwnership.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-		newOwnershipIDResult, err := ac.ownershipRepository.InsertOwnership(newOwnership)
+		newOwnershipIDResult, err := ac.ownershipRepository.InsertOwnership(ctx.Request.Context(), newOwnership)
 
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err})
@@ -230,11 +276,6 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 
 		transaction.OwnershipID = newOwnershipID
 	} else if transactionType == "rent" {
-		if quantity > 1 {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Maximum quantity for rental is 1"})
-			return
-		}
-
 		// Validate days
 		daysParams := ctx.PostForm("days")
 
@@ -250,15 +291,20 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 			return
 		}
 
-		ownership, err := ac.ownershipRepository.GetOwnershipData(ownershipID)
+		if days < 1 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Days must be greater than 1 or equal"})
+			return
+		}
+
+		ownership, err := ac.ownershipRepository.GetOwnershipData(ctx.Request.Context(), ownershipID)
 
 		if err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err})
 			return
 		}
 
-		if quantity > ownership.Quantity {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is not valid"})
+		if quantity > ownership.Quantity-ownership.RentedQuantity {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
 			return
 		}
 
@@ -267,8 +313,12 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 			return
 		}
 
-		if amount < ownership.RentCost*float64(days) {
-			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than rent cost"})
+		var totalFee float64
+		rentCost := ownership.RentCost * float64(days) * float64(quantity)
+		fees, totalFee = helpers.CalculateFees(rentCost, feeSchedules)
+
+		if amount < rentCost+totalFee {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Amount is lower than rent cost and fees"})
 			return
 		}
 
@@ -278,19 +328,28 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		rental.OwnerID = ownership.UserID
 		rental.TokenID = tokenID
 		rental.OwnershipID = ownershipID
-		rental.Timestamp = sql.NullTime{Time: time.Now().Add(time.Duration(1e9 * 3600 * 24 * days)), Valid: true}
-		rental.Status = "waiting_confirmation"
+		rental.Quantity = quantity
+		rental.Timestamp = sql.NullTime{Time: helpers.GetRentalEndTime(time.Now(), days), Valid: true}
+		rental.Days = days
+		rental.Amount = rentCost
+		rental.Status = helpers.RentalStatusPending
 		rental.TransactionHash = transactionHash
 		rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 		rental.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-		rentalIDResult, err := ac.rentalRepository.InsertRental(rental)
+		// Checked again under the ownership lock, a concurrent rental may have taken the units since
+		rentalIDResult, isAvailable, err := ac.rentalRepository.InsertRental(ctx.Request.Context(), rental)
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 			return
 		}
 
+		if !isAvailable {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": "Quantity is higher than available units"})
+			return
+		}
+
 		rentalID, err := uuid.Parse(rentalIDResult)
 
 		if err != nil {
@@ -304,13 +363,6 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		return
 	}
 
-	token, err := ac.tokenRepository.GetTokenData(tokenID)
-
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
-		return
-	}
-
 	transaction.UserFromID = userFromID
 	transaction.UserToID = userToID
 	transaction.OwnershipID = ownershipID
@@ -328,68 +380,49 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		transaction.CollectionID = token.CollectionID
 	}
 
-	transactionId, err := ac.repository.InsertTransaction(transaction)
+	transactionId, err := ac.repository.InsertTransaction(ctx.Request.Context(), transaction)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 		return
 	}
 
-	cacheKey := "transaction-list-*"
-
-	var keys []string
-	keys, err = ac.redisClient.Keys(cacheKey).Result()
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
-		return
-	}
-
-	for _, key := range keys {
-		err = ac.redisClient.Del(key).Err()
+	// Record fee lines
+	for index := range fees {
+		fees[index].TransactionID, err = uuid.Parse(transactionId)
 
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 			return
 		}
-	}
 
-	// Update token's last price
-	token.PreviousID = token.ID
-	token.NumberOfTransactions = token.NumberOfTransactions + 1
-	token.VolumeTransactions = token.VolumeTransactions + amount
-	token.LastPrice = amount / float64(quantity)
-	token.Status = "waiting_confirmation"
-	token.TransactionHash = transactionHash
-
-	_, err = ac.tokenRepository.InsertToken(token, nil)
-
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
-		return
-	}
+		fees[index].Status = "waiting_confirmation"
+		fees[index].TransactionHash = transactionHash
+		fees[index].UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		fees[index].CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	if helpers.IsValidUUID(token.CollectionID) == true {
-		collection, err := ac.collectionRepository.GetCollectionData(token.CollectionID)
+		_, err = ac.transactionFeeRepository.InsertTransactionFee(ctx.Request.Context(), fees[index])
 
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
 			return
 		}
+	}
 
-		collection.PreviousID = collection.ID
-		collection.NumberOfTransactions = sql.NullInt64{Int64: collection.NumberOfTransactions.Int64 + 1, Valid: true}
-		collection.VolumeTransactions = sql.NullFloat64{Float64: collection.VolumeTransactions.Float64 + amount, Valid: true}
-		collection.Status = sql.NullString{String: "waiting_confirmation", Valid: true}
-		collection.TransactionHash = sql.NullString{String: transactionHash, Valid: true}
+	cacheKey := "transaction-list-*"
 
-		if (amount / float64(quantity)) < collection.Floor.Float64 {
-			collection.Floor = sql.NullFloat64{Float64: amount / float64(quantity), Valid: true}
-		}
+	var keys []string
+	keys, err = ac.redisClient.Keys(cacheKey).Result()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
 
-		_, err = ac.collectionRepository.InsertCollection(collection)
+	for _, key := range keys {
+		err = ac.redisClient.Del(key).Err()
 
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 			return
 		}
 	}
@@ -445,7 +478,9 @@ func (ac *TransactionController) InsertTransaction(ctx *gin.Context) {
 		}
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"transaction_id": transactionId}})
+	events.Publish(ac.redisClient, "transaction.pending", gin.H{"transaction_id": transactionId, "token_id": tokenID, "type": transactionType, "transaction_hash": transactionHash}, events.UserTopic(userFromID), events.UserTopic(userToID), events.TokenTopic(tokenID), events.CollectionTopic(token.CollectionID))
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"transaction_id": transactionId, "fees": fees}})
 }
 
 func (ac *TransactionController) GetTransactionList(ctx *gin.Context) {
@@ -547,11 +582,11 @@ func (ac *TransactionController) GetTransactionList(ctx *gin.Context) {
 	}
 
 	if tokenIDParams != "" {
-		transactions, err = ac.repository.GetTransactionListByToken(offset, limit, tokenID, &status, orderBy, orderOption)
+		transactions, err = ac.repository.GetTransactionListByToken(ctx.Request.Context(), offset, limit, tokenID, &status, orderBy, orderOption)
 	} else if collectionIDParams != "" {
-		transactions, err = ac.repository.GetTransactionListByCollection(offset, limit, collectionID, &status, orderBy, orderOption)
+		transactions, err = ac.repository.GetTransactionListByCollection(ctx.Request.Context(), offset, limit, collectionID, &status, orderBy, orderOption)
 	} else {
-		transactions, err = ac.repository.GetTransactionList(offset, limit, userID, &status, orderBy, orderOption)
+		transactions, err = ac.repository.GetTransactionList(ctx.Request.Context(), offset, limit, userID, &status, orderBy, orderOption)
 	}
 
 	if err != nil {
@@ -613,7 +648,7 @@ func (ac *TransactionController) GetTransactionData(ctx *gin.Context) {
 		return
 	}
 
-	transaction, err = ac.repository.GetTransactionData(id)
+	transaction, err = ac.repository.GetTransactionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -625,6 +660,13 @@ func (ac *TransactionController) GetTransactionData(ctx *gin.Context) {
 		return
 	}
 
+	transaction.Fees, err = ac.transactionFeeRepository.GetTransactionFeeList(ctx.Request.Context(), 0, 100, &transaction.ID, nil, nil, nil, "created_at", "ASC")
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
 	cacheBytes, err := json.Marshal(transaction)
 
 	if err != nil {
@@ -657,7 +699,7 @@ func (ac *TransactionController) UpdateTransaction(ctx *gin.Context) {
 		return
 	}
 
-	transaction, err := ac.repository.GetTransactionData(id)
+	transaction, err := ac.repository.GetTransactionData(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -770,7 +812,7 @@ func (ac *TransactionController) UpdateTransaction(ctx *gin.Context) {
 	transaction.GasFee = gasFee
 	transaction.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.repository.UpdateTransaction(transaction.ID, transaction)
+	err = ac.repository.UpdateTransaction(ctx.Request.Context(), transaction.ID, transaction)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -872,7 +914,7 @@ func (ac *TransactionController) DeleteTransaction(ctx *gin.Context) {
 		return
 	}
 
-	err = ac.repository.DeleteTransaction(id)
+	err = ac.repository.DeleteTransaction(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+type TrashController struct {
+	repository  *repositories.TrashRepository
+	redisClient *redis.Client
+}
+
+func NewTrashController(repository *repositories.TrashRepository, redisClient *redis.Client) *TrashController {
+	return &TrashController{repository, redisClient}
+}
+
+func (ac *TrashController) GetDeletedList(ctx *gin.Context) {
+	recordType := ctx.Param("type")
+	table, isExist := helpers.SoftDeleteTables[recordType]
+
+	if !isExist {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	deletedRecords, err := ac.repository.GetDeletedList(ctx.Request.Context(), recordType, table, offset, limit)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"records": deletedRecords}})
+}
+
+func (ac *TrashController) Restore(ctx *gin.Context) {
+	recordType := ctx.Param("type")
+	table, isExist := helpers.SoftDeleteTables[recordType]
+
+	if !isExist {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	isRestored, err := ac.repository.Restore(ctx.Request.Context(), table, id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isRestored {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Deleted record not found"})
+		return
+	}
+
+	// A restored row shows up again in its own lists and in the derived ones
+	cacheKeys := []string{fmt.Sprintf("%s-*", recordType), "feed-*", "trending-list-*", "ranking-list-*"}
+
+	for _, cacheKey := range cacheKeys {
+		keys, err := ac.redisClient.Keys(cacheKey).Result()
+
+		if err != nil {
+			continue
+		}
+
+		for _, key := range keys {
+			ac.redisClient.Del(key)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"id": id}})
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+	"metaedu-marketplace/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis"
+)
+
+type TrendingController struct {
+	repository  *repositories.ViewRepository
+	redisClient *redis.Client
+}
+
+func NewTrendingController(repository *repositories.ViewRepository, redisClient *redis.Client) *TrendingController {
+	return &TrendingController{repository, redisClient}
+}
+
+// Views are deduplicated per user when signed in, otherwise per client IP
+func getViewer(ctx *gin.Context) string {
+	user, isExist := ctx.Get("user")
+
+	if isExist {
+		return user.(models.User).ID.String()
+	}
+
+	return ctx.ClientIP()
+}
+
+func (ac *TrendingController) GetTrendingList(ctx *gin.Context) {
+	var viewType string
+
+	switch ctx.Param("type") {
+	case "tokens":
+		viewType = utils.ViewTypeToken
+	case "collections":
+		viewType = utils.ViewTypeCollection
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Type is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+
+	if err != nil || limit < 1 || limit > helpers.TrendingSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Limit is not valid"})
+		return
+	}
+
+	var trendings []models.Trending
+
+	cacheKey := fmt.Sprintf("trending-list-%s-%d", viewType, limit)
+	cache, err := ac.redisClient.Get(cacheKey).Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if cache != "" && cache != "null" {
+		err := json.Unmarshal([]byte(cache), &trendings)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"trending": trendings}})
+		return
+	}
+
+	trendings, err = ac.repository.GetTrendingList(ctx.Request.Context(), viewType, limit)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	cacheBytes, err := json.Marshal(trendings)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.redisClient.Set(cacheKey, cacheBytes, 0).Err()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"trending": trendings}})
+}
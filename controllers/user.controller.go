@@ -1,18 +1,22 @@
 package controllers
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"time"
 
+	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
+	"metaedu-marketplace/templates"
+	"metaedu-marketplace/utils"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis"
 	"github.com/google/uuid"
@@ -21,12 +25,14 @@ import (
 
 type UserController struct {
 	userRepository    *repositories.UserRepository
+	emailVerifier     *utils.EmailVerifier
+	mailer            utils.Mailer
 	web3StorageClient w3s.Client
 	redisClient       *redis.Client
 }
 
-func NewUserController(userRepository *repositories.UserRepository, web3StorageClient w3s.Client, redisClient *redis.Client) *UserController {
-	return &UserController{userRepository, web3StorageClient, redisClient}
+func NewUserController(userRepository *repositories.UserRepository, emailVerifier *utils.EmailVerifier, mailer utils.Mailer, web3StorageClient w3s.Client, redisClient *redis.Client) *UserController {
+	return &UserController{userRepository, emailVerifier, mailer, web3StorageClient, redisClient}
 }
 
 func (ac *UserController) GetMyUserData(ctx *gin.Context) {
@@ -73,18 +79,23 @@ func (ac *UserController) GetUserData(ctx *gin.Context) {
 			return
 		}
 
+		if user.ModerationStatus == helpers.ModerationStatusBanned && !isAdmin(ctx) {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "User not found"})
+			return
+		}
+
 		ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"user": user}})
 		return
 	}
 
-	user, err = ac.userRepository.GetUserByID(id)
+	user, err = ac.userRepository.GetUserByID(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 		return
 	}
 
-	if user.Address == "" {
+	if user.Address == "" || (user.ModerationStatus == helpers.ModerationStatusBanned && !isAdmin(ctx)) {
 		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "User not found"})
 		return
 	}
@@ -139,7 +150,7 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 		}
 	}
 
-	user, err := ac.userRepository.GetUserByID(id)
+	user, err := ac.userRepository.GetUserByID(ctx.Request.Context(), id)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -151,6 +162,13 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
+	currentUser, isExist := ctx.Get("user")
+
+	if !isExist || currentUser.(models.User).ID != user.ID {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "User has no access to update this user"})
+		return
+	}
+
 	var photoUrl string
 
 	if uploadedPhoto != nil {
@@ -163,7 +181,7 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 
 		photoFileName := path.Base(uploadedPhoto.Filename)
 
-		photoCid, err := ac.web3StorageClient.Put(context.Background(), photoFile)
+		photoCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), photoFile)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 			return
@@ -190,7 +208,7 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 
 		coverFileName := path.Base(uploadedCover.Filename)
 
-		coverCid, err := ac.web3StorageClient.Put(context.Background(), coverFile)
+		coverCid, err := ac.web3StorageClient.Put(ctx.Request.Context(), coverFile)
 		if err != nil {
 			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
 			return
@@ -206,7 +224,15 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 	}
 
 	user.Name = ctx.DefaultPostForm("name", user.Name)
-	user.Email = ctx.DefaultPostForm("email", user.Email)
+
+	// Changing the email requires a new verification
+	email := ctx.DefaultPostForm("email", user.Email)
+
+	if email != user.Email {
+		user.Email = email
+		user.Verified = false
+		user.EmailVerifiedAt = sql.NullTime{}
+	}
 
 	if photoUrl != "" {
 		user.Photo = photoUrl
@@ -218,7 +244,7 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 
 	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	err = ac.userRepository.UpdateUser(user.ID, user)
+	err = ac.userRepository.UpdateUser(ctx.Request.Context(), user.ID, user)
 
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
@@ -235,3 +261,211 @@ func (ac *UserController) UpdateUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "User has been updated"})
 }
+
+func (ac *UserController) SendEmailVerification(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	if user.(models.User).Email == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Email is required"})
+		return
+	}
+
+	if user.(models.User).Verified {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Email has been verified"})
+		return
+	}
+
+	// Allow one email per minute and five per hour
+	isAllowed, err := ac.redisClient.SetNX(fmt.Sprintf("email-verification-%s", user.(models.User).ID), 1, time.Minute).Result()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isAllowed {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"status": "failed", "error": "Please wait before requesting another verification email"})
+		return
+	}
+
+	countKey := fmt.Sprintf("email-verification-count-%s", user.(models.User).ID)
+	count, err := ac.redisClient.Incr(countKey).Result()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if count == 1 {
+		ac.redisClient.Expire(countKey, time.Hour)
+	}
+
+	if count > 5 {
+		ctx.JSON(http.StatusTooManyRequests, gin.H{"status": "failed", "error": "Too many verification emails, please try again later"})
+		return
+	}
+
+	subject, body, err := templates.RenderEmail("email_verification", templates.EmailData{
+		Name:  user.(models.User).Name,
+		Title: "Verify your email address",
+		Body:  "Please confirm that this email address belongs to your MetaEdu Marketplace account.",
+		Link:  ac.emailVerifier.CreateLink(user.(models.User).ID, user.(models.User).Email),
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	err = ac.mailer.Send(user.(models.User).Email, subject, body)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Verification email has been sent"})
+}
+
+func (ac *UserController) VerifyEmail(ctx *gin.Context) {
+	userID, email, err := ac.emailVerifier.Verify(ctx.Query("token"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	isVerified, err := ac.userRepository.VerifyUserEmail(ctx.Request.Context(), userID, email)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isVerified {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Email has been changed since the link was sent"})
+		return
+	}
+
+	ac.redisClient.Del(fmt.Sprintf("user-data-%s", userID))
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Email has been verified"})
+}
+
+func (ac *UserController) GetMyAddressList(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	userAddresses, err := ac.userRepository.GetUserAddressList(ctx.Request.Context(), user.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"addresses": userAddresses}})
+}
+
+func (ac *UserController) LinkAddress(ctx *gin.Context) {
+	// Validate address
+	address := strings.ToLower(ctx.PostForm("address"))
+
+	if !common.IsHexAddress(address) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Address is not valid"})
+		return
+	}
+
+	// Validate signature of the current nonce by the new address
+	sig := ctx.PostForm("sig")
+
+	if sig == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Signature is required"})
+		return
+	}
+
+	currentUser, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	user, err := ac.userRepository.GetUserByID(ctx.Request.Context(), currentUser.(models.User).ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	existingUser := ac.userRepository.GetUserByAddress(ctx.Request.Context(), address)
+
+	if (existingUser != models.User{}) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Address has been linked to a user"})
+		return
+	}
+
+	err = utils.Verify(address, user.Nonce, sig)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Signature is not valid"})
+		return
+	}
+
+	userAddressID, err := ac.userRepository.InsertUserAddress(ctx.Request.Context(), models.UserAddress{UserID: user.ID, Address: address})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Rotate the nonce so the signature can not be replayed
+	user.Nonce, err = utils.GetNonce()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "Failed to create nonce"})
+		return
+	}
+
+	user.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.userRepository.UpdateUser(ctx.Request.Context(), user.ID, user)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"address_id": userAddressID}})
+}
+
+func (ac *UserController) UnlinkAddress(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	isDeleted, err := ac.userRepository.DeleteUserAddress(ctx.Request.Context(), user.(models.User).ID, ctx.Param("address"))
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isDeleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Linked address not found or is the primary address"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Address has been unlinked"})
+}
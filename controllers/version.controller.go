@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type VersionController struct {
+	repository           *repositories.VersionRepository
+	tokenRepository      *repositories.TokenRepository
+	collectionRepository *repositories.CollectionRepository
+}
+
+func NewVersionController(repository *repositories.VersionRepository, tokenRepository *repositories.TokenRepository, collectionRepository *repositories.CollectionRepository) *VersionController {
+	return &VersionController{repository, tokenRepository, collectionRepository}
+}
+
+// GetResourceHistory serves /{resource}/:id/history, the resource is taken from the route group
+func (ac *VersionController) GetResourceHistory(ctx *gin.Context) {
+	resourceType := helpers.GetAuditResource(ctx.FullPath())
+
+	if _, isExist := helpers.VersionTables[resourceType]; !isExist {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Resource is not valid"})
+		return
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	// Hidden tokens and collections keep their history hidden as well
+	if resourceType == helpers.VersionResourceToken {
+		token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if token.Uri == "" || (helpers.IsTokenHidden(token) && !isAdmin(ctx)) {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+			return
+		}
+	} else if resourceType == helpers.VersionResourceCollection {
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if collection.Title.String == "" || (helpers.IsCollectionHidden(collection) && !isAdmin(ctx)) {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+			return
+		}
+	}
+
+	resourceVersions, err := ac.repository.GetResourceVersionList(ctx.Request.Context(), resourceType, id, offset, limit)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	status := helpers.PendingChangeStatusPending
+	pendingChanges, err := ac.repository.GetPendingChangeList(ctx.Request.Context(), 0, 100, &resourceType, &id, &status)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"versions": resourceVersions, "pending_changes": pendingChanges}})
+}
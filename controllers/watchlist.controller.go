@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WatchlistController struct {
+	watchlistRepository  *repositories.WatchlistRepository
+	tokenRepository      *repositories.TokenRepository
+	collectionRepository *repositories.CollectionRepository
+}
+
+func NewWatchlistController(watchlistRepository *repositories.WatchlistRepository, tokenRepository *repositories.TokenRepository, collectionRepository *repositories.CollectionRepository) *WatchlistController {
+	return &WatchlistController{watchlistRepository, tokenRepository, collectionRepository}
+}
+
+func (ac *WatchlistController) GetWatchlistList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	userID := user.(models.User).ID
+
+	watchlists, err := ac.watchlistRepository.GetWatchlistList(ctx.Request.Context(), offset, limit, &userID, nil, nil)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"watchlists": watchlists}})
+}
+
+func (ac *WatchlistController) UpsertWatchlist(ctx *gin.Context) {
+	var watchlist models.Watchlist
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	watchlist.UserID = user.(models.User).ID
+
+	// Validate target, either a token or a collection
+	tokenID := ctx.PostForm("token_id")
+	collectionID := ctx.PostForm("collection_id")
+
+	if (tokenID == "") == (collectionID == "") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Either token id or collection id is required"})
+		return
+	}
+
+	if tokenID != "" {
+		id, err := uuid.Parse(tokenID)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Token id is not valid"})
+			return
+		}
+
+		token, err := ac.tokenRepository.GetTokenData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if token.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Token not found"})
+			return
+		}
+
+		watchlist.TokenID = token.ID
+	} else {
+		id, err := uuid.Parse(collectionID)
+
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Collection id is not valid"})
+			return
+		}
+
+		collection, err := ac.collectionRepository.GetCollectionData(ctx.Request.Context(), id)
+
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+			return
+		}
+
+		if collection.ID == helpers.GetEmptyUUID() {
+			ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Collection not found"})
+			return
+		}
+
+		watchlist.CollectionID = collection.ID
+	}
+
+	// Validate alert settings
+	var err error
+
+	watchlist.NotifySale, err = strconv.ParseBool(ctx.DefaultPostForm("notify_sale", "true"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Notify sale is not valid"})
+		return
+	}
+
+	watchlist.NotifyRent, err = strconv.ParseBool(ctx.DefaultPostForm("notify_rent", "true"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Notify rent is not valid"})
+		return
+	}
+
+	watchlist.NotifyFloor, err = strconv.ParseBool(ctx.DefaultPostForm("notify_floor", "true"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Notify floor is not valid"})
+		return
+	}
+
+	if priceBelow := ctx.PostForm("price_below"); priceBelow != "" {
+		price, err := strconv.ParseFloat(priceBelow, 64)
+
+		if err != nil || price <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Price below is not valid"})
+			return
+		}
+
+		watchlist.PriceBelow = sql.NullFloat64{Float64: price, Valid: true}
+	}
+
+	id, err := ac.watchlistRepository.UpsertWatchlist(ctx.Request.Context(), watchlist)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"id": id}})
+}
+
+func (ac *WatchlistController) DeleteWatchlist(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	isDeleted, err := ac.watchlistRepository.DeleteWatchlist(ctx.Request.Context(), user.(models.User).ID, id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if !isDeleted {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Watchlist not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Watchlist has been deleted"})
+}
@@ -0,0 +1,296 @@
+package controllers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+	"metaedu-marketplace/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookController struct {
+	repository *repositories.WebhookRepository
+}
+
+func NewWebhookController(repository *repositories.WebhookRepository) *WebhookController {
+	return &WebhookController{repository}
+}
+
+func (ac *WebhookController) bindWebhookEndpoint(ctx *gin.Context, webhookEndpoint *models.WebhookEndpoint) error {
+	// Validate url
+	webhookEndpoint.Url = ctx.DefaultPostForm("url", webhookEndpoint.Url)
+
+	err := utils.ValidateWebhookUrl(webhookEndpoint.Url)
+
+	if err != nil {
+		return err
+	}
+
+	// Validate event types
+	eventTypesParams := ctx.PostForm("event_types")
+
+	if eventTypesParams != "" {
+		webhookEndpoint.EventTypes = []string{}
+
+		for _, eventType := range strings.Split(eventTypesParams, ",") {
+			eventType = strings.TrimSpace(eventType)
+
+			if !helpers.IsValidWebhookEventType(eventType) {
+				return fmt.Errorf("Event type %s is not valid", eventType)
+			}
+
+			webhookEndpoint.EventTypes = append(webhookEndpoint.EventTypes, eventType)
+		}
+	}
+
+	if len(webhookEndpoint.EventTypes) == 0 {
+		return fmt.Errorf("Event types are required")
+	}
+
+	// Validate status
+	webhookEndpoint.Status = ctx.DefaultPostForm("status", webhookEndpoint.Status)
+
+	if webhookEndpoint.Status != "active" && webhookEndpoint.Status != "disabled" {
+		return fmt.Errorf("Status is not valid")
+	}
+
+	return nil
+}
+
+// getWebhookEndpoint returns the endpoint only when it belongs to the current user
+func (ac *WebhookController) getWebhookEndpoint(ctx *gin.Context, idParam string) (models.WebhookEndpoint, bool) {
+	var webhookEndpoint models.WebhookEndpoint
+
+	id, err := uuid.Parse(idParam)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return webhookEndpoint, false
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return webhookEndpoint, false
+	}
+
+	webhookEndpoint, err = ac.repository.GetWebhookEndpointData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return webhookEndpoint, false
+	}
+
+	if webhookEndpoint.Url == "" || webhookEndpoint.UserID != user.(models.User).ID {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Webhook endpoint not found"})
+		return webhookEndpoint, false
+	}
+
+	return webhookEndpoint, true
+}
+
+func (ac *WebhookController) InsertWebhookEndpoint(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	var webhookEndpoint models.WebhookEndpoint
+
+	webhookEndpoint.Status = "active"
+
+	err := ac.bindWebhookEndpoint(ctx, &webhookEndpoint)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	webhookEndpoint.Secret, err = utils.GetWebhookSecret()
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	webhookEndpoint.UserID = user.(models.User).ID
+	webhookEndpoint.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	webhookEndpoint.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	webhookEndpointID, err := ac.repository.InsertWebhookEndpoint(ctx.Request.Context(), webhookEndpoint)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	// Secret is only shown once
+	ctx.JSON(http.StatusCreated, gin.H{"status": "success", "data": gin.H{"webhook_endpoint_id": webhookEndpointID, "secret": webhookEndpoint.Secret}})
+}
+
+func (ac *WebhookController) GetWebhookEndpointList(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": "User data is not valid"})
+		return
+	}
+
+	userID := user.(models.User).ID
+
+	webhookEndpoints, err := ac.repository.GetWebhookEndpointList(ctx.Request.Context(), offset, limit, &userID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"webhook_endpoints": webhookEndpoints}})
+}
+
+func (ac *WebhookController) UpdateWebhookEndpoint(ctx *gin.Context) {
+	webhookEndpoint, isFound := ac.getWebhookEndpoint(ctx, ctx.Param("id"))
+
+	if !isFound {
+		return
+	}
+
+	err := ac.bindWebhookEndpoint(ctx, &webhookEndpoint)
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	webhookEndpoint.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.repository.UpdateWebhookEndpoint(ctx.Request.Context(), webhookEndpoint.ID, webhookEndpoint)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	webhookEndpoint.Secret = ""
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"webhook_endpoint": webhookEndpoint}})
+}
+
+func (ac *WebhookController) DeleteWebhookEndpoint(ctx *gin.Context) {
+	webhookEndpoint, isFound := ac.getWebhookEndpoint(ctx, ctx.Param("id"))
+
+	if !isFound {
+		return
+	}
+
+	err := ac.repository.DeleteWebhookEndpoint(ctx.Request.Context(), webhookEndpoint.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (ac *WebhookController) GetWebhookDeliveryList(ctx *gin.Context) {
+	webhookEndpoint, isFound := ac.getWebhookEndpoint(ctx, ctx.Param("id"))
+
+	if !isFound {
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "25"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	offset, err := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	status := ctx.DefaultQuery("status", "")
+
+	webhookDeliveries, err := ac.repository.GetWebhookDeliveryList(ctx.Request.Context(), offset, limit, webhookEndpoint.ID, &status)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"webhook_deliveries": webhookDeliveries}})
+}
+
+func (ac *WebhookController) ReplayWebhookDelivery(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"status": "failed", "error": "Id is not valid"})
+		return
+	}
+
+	webhookDelivery, err := ac.repository.GetWebhookDeliveryData(ctx.Request.Context(), id)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "failed", "error": err.Error()})
+		return
+	}
+
+	if webhookDelivery.EventType == "" {
+		ctx.JSON(http.StatusNotFound, gin.H{"status": "failed", "error": "Webhook delivery not found"})
+		return
+	}
+
+	_, isFound := ac.getWebhookEndpoint(ctx, webhookDelivery.EndpointID.String())
+
+	if !isFound {
+		return
+	}
+
+	// Queue the delivery again with a fresh retry budget
+	webhookDelivery.Status = helpers.WebhookDeliveryStatusPending
+	webhookDelivery.Attempts = 0
+	webhookDelivery.LastError = ""
+	webhookDelivery.NextAttemptAt = sql.NullTime{Time: time.Now(), Valid: true}
+	webhookDelivery.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	err = ac.repository.UpdateWebhookDelivery(ctx.Request.Context(), webhookDelivery.ID, webhookDelivery)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"status": "error", "error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "success", "data": gin.H{"webhook_delivery": webhookDelivery}})
+}
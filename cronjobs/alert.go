@@ -0,0 +1,85 @@
+package cronjobs
+
+import (
+	"database/sql"
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+)
+
+// evaluateListingAlerts notifies the watchers of a token whose listing has just been confirmed
+func evaluateListingAlerts(previous models.Ownership, current models.Ownership) {
+	wasForSale := previous.Status == "active" && previous.AvailableForSale && previous.Quantity > 0
+	wasForRent := previous.Status == "active" && previous.AvailableForRent && previous.Quantity > 0
+	isForSale := current.Status == "active" && current.AvailableForSale && current.Quantity > 0
+	isForRent := current.Status == "active" && current.AvailableForRent && current.Quantity > 0
+
+	if !isForSale && !isForRent {
+		return
+	}
+
+	watchlists, err := watchlistRepository.GetWatchlistList(ctx, 0, 100000000, nil, &current.TokenID, nil)
+
+	if err != nil {
+		fmt.Println("Getting failed, watchlist of token: ", current.TokenID, ", error: ", err)
+		return
+	}
+
+	if len(watchlists) == 0 {
+		return
+	}
+
+	token, err := tokenRepository.GetTokenData(ctx, current.TokenID)
+
+	if err != nil {
+		fmt.Println("Getting failed, token: ", current.TokenID, ", error: ", err)
+		return
+	}
+
+	data := map[string]interface{}{"token_id": token.ID, "ownership_id": current.ID, "sale_price": current.SalePrice, "rent_cost": current.RentCost}
+
+	for _, watchlist := range watchlists {
+		// Owners are not alerted about their own listing
+		if watchlist.UserID == current.UserID {
+			continue
+		}
+
+		if watchlist.NotifySale && isForSale && !wasForSale {
+			notify(watchlist.UserID, helpers.NotificationTypeWatchlistListed, "Listed for sale", fmt.Sprintf("%s has been listed for sale at %v.", token.Title, current.SalePrice), data)
+		}
+
+		if watchlist.NotifyRent && isForRent && !wasForRent {
+			notify(watchlist.UserID, helpers.NotificationTypeWatchlistListed, "Listed for rent", fmt.Sprintf("%s has been listed for rent at %v.", token.Title, current.RentCost), data)
+		}
+
+		// Only fire when the price crosses the threshold
+		if watchlist.PriceBelow.Valid {
+			isBelow := isForSale && current.SalePrice < watchlist.PriceBelow.Float64
+			wasBelow := wasForSale && previous.SalePrice < watchlist.PriceBelow.Float64
+
+			if isBelow && !wasBelow {
+				notify(watchlist.UserID, helpers.NotificationTypePriceAlert, "Price alert", fmt.Sprintf("%s is listed at %v, below your alert price of %v.", token.Title, current.SalePrice, watchlist.PriceBelow.Float64), data)
+			}
+		}
+	}
+}
+
+// evaluateFloorAlerts notifies the watchers of a collection whose floor price has changed
+func evaluateFloorAlerts(collection models.Collection, previousFloor sql.NullFloat64) {
+	watchlists, err := watchlistRepository.GetWatchlistList(ctx, 0, 100000000, nil, nil, &collection.ID)
+
+	if err != nil {
+		fmt.Println("Getting failed, watchlist of collection: ", collection.ID, ", error: ", err)
+		return
+	}
+
+	data := map[string]interface{}{"collection_id": collection.ID, "previous_floor": previousFloor.Float64, "floor": collection.Floor.Float64}
+
+	for _, watchlist := range watchlists {
+		if !watchlist.NotifyFloor {
+			continue
+		}
+
+		notify(watchlist.UserID, helpers.NotificationTypeFloorChanged, "Floor price changed", fmt.Sprintf("The floor of %s moved from %v to %v.", collection.Title.String, previousFloor.Float64, collection.Floor.Float64), data)
+	}
+}
@@ -0,0 +1,78 @@
+package cronjobs
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Shared by every worker replica, whoever holds it runs the jobs and the others stand by
+const leaderLockID = 7250420532
+
+// How long Stop waits for cancelled jobs to exit and then for the unlock, on top of the shutdown timeout
+const resignTimeout = 5 * time.Second
+
+var (
+	isLeading int32
+
+	// Receives once when the leadership is lost, the worker then exits and comes back as a standby
+	failed = make(chan error, 1)
+)
+
+// Failed reports the errors the worker can not recover from while running
+func Failed() <-chan error {
+	return failed
+}
+
+// campaign keeps or takes the leadership. Losing it cancels the running jobs at once,
+// because the replica that takes over may already be working on the same rows.
+func campaign() {
+	isLeader, err := leader.Campaign(ctx)
+
+	if err != nil {
+		fmt.Println("Leader election failed, error: ", err)
+	}
+
+	var value int32
+
+	if isLeader {
+		value = 1
+	}
+
+	if atomic.SwapInt32(&isLeading, value) == value {
+		return
+	}
+
+	if isLeader {
+		fmt.Println("Became the leader, running the jobs")
+		return
+	}
+
+	fmt.Println("Lost the leadership, cancelling the jobs")
+	cancelJobs()
+
+	select {
+	case failed <- fmt.Errorf("lost the worker leadership"):
+	default:
+	}
+}
+
+// stillLeading is checked between rows, so a replica stops before the next row once the shutdown timeout ran out or its lock session died
+func stillLeading() bool {
+	if ctx.Err() != nil || atomic.LoadInt32(&isLeading) != 1 {
+		return false
+	}
+
+	campaign()
+
+	return atomic.LoadInt32(&isLeading) == 1
+}
+
+// leading skips the job on the standby replicas
+func leading(job func()) func() {
+	return func() {
+		if atomic.LoadInt32(&isLeading) == 1 {
+			job()
+		}
+	}
+}
@@ -1,40 +1,113 @@
-package main
+package cronjobs
 
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
+	"metaedu-marketplace/app"
 	"metaedu-marketplace/config"
+	"metaedu-marketplace/db"
+	"metaedu-marketplace/events"
 	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
-	"os"
+	"metaedu-marketplace/utils"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-co-op/gocron"
 	"github.com/go-redis/redis"
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
 )
 
 var (
-	ctx         = context.Background()
-	rpcUrl      string
+	// Cancelled on shutdown or when the leadership is lost, the running jobs then stop between items
+	ctx, cancelJobs = context.WithCancel(context.Background())
+	scheduler       *gocron.Scheduler
+
 	ethClient   *ethclient.Client
-	dbClient    *sql.DB
 	redisClient *redis.Client
-
-	collectionRepository    *repositories.CollectionRepository
-	fractionRepository      *repositories.FractionRepository
-	ownershipRepository     *repositories.OwnershipRepository
-	rentalRepository        *repositories.RentalRepository
-	tokenRepository         *repositories.TokenRepository
-	tokenCategoryRepository *repositories.TokenCategoryRepository
-	transactionRepository   *repositories.TransactionRepository
-	userRepository          *repositories.UserRepository
+	mailer      utils.Mailer
+	viewTracker *utils.ViewTracker
+
+	softDeleteRetention  time.Duration
+	fractionBuyoutWindow time.Duration
+	rpcTimeout           time.Duration
+	workerConfig         config.WorkerConfig
+	leader               *db.Leader
+
+	collectionRepository     *repositories.CollectionRepository
+	fractionRepository       *repositories.FractionRepository
+	fractionBuyoutRepository *repositories.FractionBuyoutRepository
+	fractionShareRepository  *repositories.FractionShareRepository
+	mintVoucherRepository    *repositories.MintVoucherRepository
+	notificationRepository   *repositories.NotificationRepository
+	ownershipRepository      *repositories.OwnershipRepository
+	priceHistoryRepository   *repositories.PriceHistoryRepository
+	rankingRepository        *repositories.RankingRepository
+	statisticRepository      *repositories.StatisticRepository
+	rentalRepository         *repositories.RentalRepository
+	rentalEventRepository    *repositories.RentalEventRepository
+	tokenRepository          *repositories.TokenRepository
+	tokenCategoryRepository  *repositories.TokenCategoryRepository
+	transactionRepository    *repositories.TransactionRepository
+	transactionFeeRepository *repositories.TransactionFeeRepository
+	trashRepository          *repositories.TrashRepository
+	userRepository           *repositories.UserRepository
+	versionRepository        *repositories.VersionRepository
+	viewRepository           *repositories.ViewRepository
+	watchlistRepository      *repositories.WatchlistRepository
+	webhookRepository        *repositories.WebhookRepository
 )
 
+// getTransactionReceipt bounds each RPC call so one stuck node request cannot hold the whole batch
+func getTransactionReceipt(transactionId common.Hash) (*types.Receipt, error) {
+	callCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	return ethClient.TransactionReceipt(callCtx, transactionId)
+}
+
+type receiptResult struct {
+	receipt *types.Receipt
+	err     error
+}
+
+// fetchReceipts spreads the RPC calls over a bounded pool of goroutines, the results keep the order of the rows
+func fetchReceipts(count int, getTransactionHash func(i int) string) []receiptResult {
+	results := make([]receiptResult, count)
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workerConfig.Concurrency && w < count; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				receipt, err := getTransactionReceipt(common.HexToHash(getTransactionHash(i)))
+				results[i] = receiptResult{receipt, err}
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
 func checkBlock() {
 	status := "waiting_confirmation"
 	monitoringOnly := false
@@ -42,24 +115,25 @@ func checkBlock() {
 	var empty string = ""
 
 	// Get pending tokens
-	tokens, err := tokenRepository.GetTokenList(0, 100000000, "", nil, nil, nil, &empty, 0, 10000000000, &status, "created_at", "ASC")
+	tokens, err := tokenRepository.GetTokenList(ctx, 0, 100000000, "", nil, nil, nil, &empty, 0, 10000000000, &status, false, "created_at", "ASC")
 
 	if err != nil {
 		fmt.Println("Error getting token list: ", err)
 		return
 	}
 
+	// Look the receipts up on the worker pool, the rows are still applied one by one in order
+	tokenReceipts := fetchReceipts(len(tokens), func(i int) string { return tokens[i].TransactionHash })
+
 	// Check all pending tokens
-	for _, token := range tokens {
+	for i, token := range tokens {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(token.TransactionHash)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := tokenReceipts[i].receipt, tokenReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -67,47 +141,29 @@ func checkBlock() {
 		}
 
 		if receipt.Status == 1 {
-			if token.PreviousID != helpers.GetEmptyUUID() {
-
-				oldToken, err := tokenRepository.GetTokenData(token.PreviousID)
-
-				if err != nil {
-					fmt.Println("Failed to get, token: ", token.PreviousID, ", from: ", token.ID, ", error: ", err)
-				}
-
-				oldToken.LastPrice = token.LastPrice
-				oldToken.NumberOfTransactions = token.NumberOfTransactions
-				oldToken.VolumeTransactions = token.VolumeTransactions
-				oldToken.FractionID = token.FractionID
-				oldToken.SourceID = token.SourceID
-				oldToken.Views = token.Views
-
-				err = tokenRepository.UpdateToken(oldToken.ID, oldToken)
-
-				if err != nil {
-					fmt.Println("Updating failed, token: ", token.PreviousID, ", from: ", token.ID, ", error: ", err)
-				}
+			token.Status = "active"
 
-				err = tokenRepository.DeleteToken(token.ID)
+			err = tokenRepository.UpdateToken(ctx, token.ID, token)
 
-				if err != nil {
-					fmt.Println("Deleting failed, token: ", token.PreviousID, ", from: ", token.ID, ", error: ", err)
-				}
+			if err != nil {
+				fmt.Println("Updating failed, token: ", token.ID, ", error: ", err)
 			} else {
-				token.Status = "active"
+				recordVersion(helpers.VersionResourceToken, token.ID, token.TransactionHash)
+			}
 
-				err = tokenRepository.UpdateToken(token.ID, token)
+			refreshStatistics(token.ID)
 
-				if err != nil {
-					fmt.Println("Updating failed, token: ", token.PreviousID, ", from: ", token.ID, ", error: ", err)
-				}
-			}
+			publishEvent("token.active", map[string]interface{}{"token_id": token.ID, "transaction_hash": token.TransactionHash}, events.GlobalTopic, events.UserTopic(token.CreatorID), events.TokenTopic(token.ID), events.CollectionTopic(token.CollectionID))
+			emitTokenWebhookEvent(helpers.WebhookEventTokenMinted, token)
 		} else {
-			err = tokenRepository.DeleteToken(token.ID)
+			err = tokenRepository.DeleteToken(ctx, token.ID)
 
 			if err != nil {
-				fmt.Println("Deleting failed, token: ", token.PreviousID, ", from: ", token.ID, ", error: ", err)
+				fmt.Println("Deleting failed, token: ", token.ID, ", error: ", err)
 			}
+
+			notify(token.CreatorID, helpers.NotificationTypeMintFailed, "Mint failed", fmt.Sprintf("The mint of %s was rejected on-chain.", token.Title), token)
+			publishEvent("token.failed", map[string]interface{}{"token_id": token.ID, "transaction_hash": token.TransactionHash}, events.UserTopic(token.CreatorID), events.TokenTopic(token.ID), events.CollectionTopic(token.CollectionID))
 		}
 
 		// Remove token cache
@@ -124,26 +180,26 @@ func checkBlock() {
 		}
 	}
 
-	// Get pending ownerships
-	ownerships, err := ownershipRepository.GetOwnershipList(0, 100000000, "", nil, &empty, nil, &empty, nil, status, "created_at", "ASC")
+	// Get pending changes
+	changeStatus := helpers.PendingChangeStatusPending
+	pendingChanges, err := versionRepository.GetPendingChangeList(ctx, 0, 100000000, nil, nil, &changeStatus)
 
 	if err != nil {
-		fmt.Println("ownership")
-		fmt.Println(err)
+		fmt.Println("Error getting pending change list: ", err)
 		return
 	}
 
-	// Check all pending ownerships
-	for _, ownership := range ownerships {
+	pendingChangeReceipts := fetchReceipts(len(pendingChanges), func(i int) string { return pendingChanges[i].TransactionHash })
+
+	// Check all pending changes
+	for i, pendingChange := range pendingChanges {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(ownership.TransactionHash)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := pendingChangeReceipts[i].receipt, pendingChangeReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -151,62 +207,70 @@ func checkBlock() {
 		}
 
 		if receipt.Status == 1 {
-			if ownership.PreviousID != helpers.GetEmptyUUID() {
-				oldOwnership, err := ownershipRepository.GetOwnershipData(ownership.PreviousID)
+			applyPendingChange(pendingChange)
+		} else {
+			err = versionRepository.UpdatePendingChangeStatus(ctx, pendingChange.ID, helpers.PendingChangeStatusFailed)
 
-				if err != nil {
-					fmt.Println("Failed to get, ownership: ", ownership.PreviousID, ", from: ", ownership.ID, ", error: ", err)
-				}
+			if err != nil {
+				fmt.Println("Updating failed, pending change: ", pendingChange.ID, ", error: ", err)
+			}
+		}
+	}
 
-				oldOwnership.Quantity = ownership.Quantity
-				oldOwnership.SalePrice = ownership.SalePrice
-				oldOwnership.RentCost = ownership.RentCost
-				oldOwnership.AvailableForSale = ownership.AvailableForSale
-				oldOwnership.AvailableForRent = ownership.AvailableForRent
-				oldOwnership.UserID = ownership.UserID
-
-				if ownership.Quantity > 0 {
-					oldOwnership.Status = "active"
-				} else {
-					oldOwnership.Status = "inactive"
-				}
+	// Get pending ownerships
+	ownerships, err := ownershipRepository.GetOwnershipList(ctx, 0, 100000000, "", nil, &empty, nil, &empty, nil, status, "created_at", "ASC")
 
-				err = ownershipRepository.UpdateOwnership(oldOwnership.ID, oldOwnership)
+	if err != nil {
+		fmt.Println("ownership")
+		fmt.Println(err)
+		return
+	}
 
-				if err != nil {
-					fmt.Println("Updating failed, ownership: ", ownership.PreviousID, ", from: ", ownership.ID, ", error: ", err)
-				}
+	ownershipReceipts := fetchReceipts(len(ownerships), func(i int) string { return ownerships[i].TransactionHash })
 
-				err = ownershipRepository.DeleteOwnership(ownership.ID)
+	// Check all pending ownerships
+	for i, ownership := range ownerships {
+		// Check if is in the monitoring only mode
+		if monitoringOnly || !stillLeading() {
+			break
+		}
 
-				if err != nil {
-					fmt.Println("Deleting failed, ownership: ", ownership.PreviousID, ", from: ", ownership.ID, ", error: ", err)
-				}
+		// Get transaction receipt
+		receipt, err := ownershipReceipts[i].receipt, ownershipReceipts[i].err
+
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if receipt.Status == 1 {
+			if ownership.Quantity > 0 {
+				ownership.Status = "active"
 			} else {
-				if ownership.Quantity > 0 {
-					ownership.Status = "active"
-				} else {
-					ownership.Status = "inactive"
-				}
+				ownership.Status = "inactive"
+			}
 
-				err = ownershipRepository.UpdateOwnership(ownership.ID, ownership)
+			err = ownershipRepository.UpdateOwnership(ctx, ownership.ID, ownership)
 
-				if err != nil {
-					fmt.Println("Updating failed, ownership: ", ownership.PreviousID, ", from: ", ownership.ID, ", error: ", err)
-				}
+			if err != nil {
+				fmt.Println("Updating failed, ownership: ", ownership.ID, ", error: ", err)
+			} else {
+				recordVersion(helpers.VersionResourceOwnership, ownership.ID, ownership.TransactionHash)
+				evaluateListingAlerts(models.Ownership{}, ownership)
+				refreshStatistics(ownership.TokenID)
 			}
 		} else {
-			err = ownershipRepository.DeleteOwnership(ownership.ID)
+			err = ownershipRepository.DeleteOwnership(ctx, ownership.ID)
 
 			if err != nil {
-				fmt.Println("Deleting failed, ownership: ", ownership.PreviousID, ", from: ", ownership.ID, ", error: ", err)
+				fmt.Println("Deleting failed, ownership: ", ownership.ID, ", error: ", err)
 			}
 		}
 
 		// Remove ownership cache
 		cacheKey := "ownership-*"
 		keys, err := redisClient.Keys(cacheKey).Result()
-		if err != nil {
+		if err == nil {
 			for _, key := range keys {
 				err = redisClient.Del(key).Err()
 
@@ -219,7 +283,7 @@ func checkBlock() {
 	}
 
 	// Get pending collections
-	collections, err := collectionRepository.GetCollectionList(0, 100000000, "", nil, &status, "created_at", "ASC")
+	collections, err := collectionRepository.GetCollectionList(ctx, 0, 100000000, "", nil, &status, false, "created_at", "ASC")
 
 	if err != nil {
 		fmt.Println("collection")
@@ -227,17 +291,17 @@ func checkBlock() {
 		return
 	}
 
+	collectionReceipts := fetchReceipts(len(collections), func(i int) string { return collections[i].TransactionHash.String })
+
 	// Check all pending collections
-	for _, collection := range collections {
+	for i, collection := range collections {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(collection.TransactionHash.String)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := collectionReceipts[i].receipt, collectionReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -245,44 +309,20 @@ func checkBlock() {
 		}
 
 		if receipt.Status == 1 {
-			if collection.PreviousID != helpers.GetEmptyUUID() {
-				oldCollection, err := collectionRepository.GetCollectionData(collection.PreviousID)
-
-				if err != nil {
-					fmt.Println("Failed to get, collection: ", collection.PreviousID, ", from: ", collection.ID, ", error: ", err)
-				}
+			collection.Status = sql.NullString{String: "active", Valid: true}
 
-				oldCollection.NumberOfItems = collection.NumberOfItems
-				oldCollection.NumberOfTransactions = collection.NumberOfTransactions
-				oldCollection.VolumeTransactions = collection.VolumeTransactions
-				oldCollection.Floor = collection.Floor
-				oldCollection.Views = collection.Views
+			err = collectionRepository.UpdateCollection(ctx, collection.ID, collection)
 
-				err = collectionRepository.UpdateCollection(oldCollection.ID, oldCollection)
-
-				if err != nil {
-					fmt.Println("Updating failed, collection: ", collection.PreviousID, ", from: ", collection.ID, ", error: ", err)
-				}
-
-				err = collectionRepository.DeleteCollection(collection.ID)
-
-				if err != nil {
-					fmt.Println("Deleting failed, collection: ", collection.PreviousID, ", from: ", collection.ID, ", error: ", err)
-				}
+			if err != nil {
+				fmt.Println("Updating failed, collection: ", collection.ID, ", error: ", err)
 			} else {
-				collection.Status = sql.NullString{String: "active", Valid: true}
-
-				err = collectionRepository.UpdateCollection(collection.ID, collection)
-
-				if err != nil {
-					fmt.Println("Updating failed, collection: ", collection.PreviousID, ", from: ", collection.ID, ", error: ", err)
-				}
+				recordVersion(helpers.VersionResourceCollection, collection.ID, collection.TransactionHash.String)
 			}
 		} else {
-			err = collectionRepository.DeleteCollection(collection.ID)
+			err = collectionRepository.DeleteCollection(ctx, collection.ID)
 
 			if err != nil {
-				fmt.Println("Deleting failed, collection: ", collection.PreviousID, ", from: ", collection.ID, ", error: ", err)
+				fmt.Println("Deleting failed, collection: ", collection.ID, ", error: ", err)
 			}
 		}
 
@@ -302,7 +342,7 @@ func checkBlock() {
 	}
 
 	// Get pending transactions
-	transactions, err := transactionRepository.GetTransactionList(0, 100000000, nil, &status, "created_at", "ASC")
+	transactions, err := transactionRepository.GetTransactionList(ctx, 0, 100000000, nil, &status, "created_at", "ASC")
 
 	if err != nil {
 		fmt.Println("transaction")
@@ -310,17 +350,17 @@ func checkBlock() {
 		return
 	}
 
+	transactionReceipts := fetchReceipts(len(transactions), func(i int) string { return transactions[i].TransactionHash })
+
 	// Check all pending transactions
-	for _, transaction := range transactions {
+	for i, transaction := range transactions {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(transaction.TransactionHash)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := transactionReceipts[i].receipt, transactionReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -330,16 +370,65 @@ func checkBlock() {
 		if receipt.Status == 1 {
 			transaction.Status = "active"
 
-			err = transactionRepository.UpdateTransaction(transaction.ID, transaction)
+			err = transactionRepository.UpdateTransaction(ctx, transaction.ID, transaction)
 
 			if err != nil {
-				fmt.Println("Updating failed, transaction: ", transaction.PreviousID, ", from: ", transaction.ID, ", error: ", err)
+				fmt.Println("Updating failed, transaction: ", transaction.ID, ", error: ", err)
 			}
+
+			err = transactionFeeRepository.UpdateTransactionFeeStatusByHash(ctx, transaction.TransactionHash, "active")
+
+			if err != nil {
+				fmt.Println("Updating failed, transaction fee: ", transaction.TransactionHash, ", error: ", err)
+			}
+
+			if transaction.Type == "purchase" || transaction.Type == "rent" {
+				recordPricePoint(transaction)
+				recordRankingEvents(transaction)
+			}
+
+			refreshStatistics(transaction.TokenID)
+
+			// Move fraction shares between holders
+			if transaction.Type == "purchase" {
+				recordFractionShareTransfer(transaction)
+				redeemMintVoucher(transaction)
+			}
+
+			publishTransactionEvent("transaction.active", transaction)
+
+			if transaction.Type == "purchase" {
+				notify(transaction.UserFromID, helpers.NotificationTypeItemSold, "Item sold", fmt.Sprintf("%d x %s has been sold for %g.", transaction.Quantity, transaction.Token.Title, transaction.Amount), transaction)
+				notify(transaction.UserToID, helpers.NotificationTypeItemPurchased, "Purchase confirmed", fmt.Sprintf("%d x %s has been added to your collection.", transaction.Quantity, transaction.Token.Title), transaction)
+			}
+			emitTransactionWebhookEvent(helpers.WebhookEventTransactionConfirmed, transaction)
 		} else {
-			err = transactionRepository.DeleteTransaction(transaction.ID)
+			// The failed row is kept in the trash as evidence until the retention purge
+			transaction.Status = "failed"
+
+			err = transactionRepository.UpdateTransaction(ctx, transaction.ID, transaction)
+
+			if err != nil {
+				fmt.Println("Updating failed, transaction: ", transaction.ID, ", error: ", err)
+			}
+
+			err = transactionRepository.DeleteTransaction(ctx, transaction.ID)
+
+			if err != nil {
+				fmt.Println("Deleting failed, transaction: ", transaction.ID, ", error: ", err)
+			}
+
+			err = transactionFeeRepository.UpdateTransactionFeeStatusByHash(ctx, transaction.TransactionHash, "failed")
 
 			if err != nil {
-				fmt.Println("Deleting failed, transaction: ", transaction.PreviousID, ", from: ", transaction.ID, ", error: ", err)
+				fmt.Println("Updating failed, transaction fee: ", transaction.TransactionHash, ", error: ", err)
+			}
+
+			publishTransactionEvent("transaction.failed", transaction)
+
+			// Failed rentals are notified from the rental loop
+			if transaction.Type == "purchase" {
+				notify(transaction.UserToID, helpers.NotificationTypeTransactionFailed, "Transaction failed", fmt.Sprintf("Your purchase of %s was rejected on-chain.", transaction.Token.Title), transaction)
 			}
 		}
 
@@ -359,7 +448,8 @@ func checkBlock() {
 	}
 
 	// Get pending rentals
-	rentals, err := rentalRepository.GetRentalList(0, 100000000, "", nil, &empty, nil, &empty, nil, &empty, nil, &status, "created_at", "ASC")
+	rentalStatus := helpers.RentalStatusPending
+	rentals, err := rentalRepository.GetRentalList(ctx, 0, 100000000, "", nil, &empty, nil, &empty, nil, &empty, nil, &rentalStatus, "created_at", "ASC")
 
 	if err != nil {
 		fmt.Println("rental")
@@ -367,17 +457,17 @@ func checkBlock() {
 		return
 	}
 
+	rentalReceipts := fetchReceipts(len(rentals), func(i int) string { return rentals[i].TransactionHash })
+
 	// Check all pending rentals
-	for _, rental := range rentals {
+	for i, rental := range rentals {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(rental.TransactionHash)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := rentalReceipts[i].receipt, rentalReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -385,24 +475,42 @@ func checkBlock() {
 		}
 
 		if receipt.Status == 1 {
-			rental.Status = "active"
+			// Rental period starts on confirmation
+			rental.StartedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			rental.Timestamp = sql.NullTime{Time: helpers.GetRentalEndTime(time.Now(), rental.Days), Valid: true}
+			rental.Status = helpers.RentalStatusActive
+			rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-			err = rentalRepository.UpdateRental(rental.ID, rental)
+			err = rentalRepository.UpdateRental(ctx, rental.ID, rental)
 
 			if err != nil {
-				fmt.Println("Updating failed, rental: ", rental.PreviousID, ", from: ", rental.ID, ", error: ", err)
+				fmt.Println("Updating failed, rental: ", rental.ID, ", error: ", err)
+				continue
 			}
+
+			recordVersion(helpers.VersionResourceRental, rental.ID, rental.TransactionHash)
+			recordRentalEvent(rental, helpers.RentalStatusPending, "Rental transaction confirmed")
 		} else {
-			err = rentalRepository.DeleteRental(rental.ID)
+			rental.EndedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			rental.Status = helpers.RentalStatusCancelled
+			rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+			err = rentalRepository.UpdateRental(ctx, rental.ID, rental)
 
 			if err != nil {
-				fmt.Println("Deleting failed, rental: ", rental.PreviousID, ", from: ", rental.ID, ", error: ", err)
+				fmt.Println("Updating failed, rental: ", rental.ID, ", error: ", err)
+				continue
 			}
+
+			recordRentalEvent(rental, helpers.RentalStatusPending, "Rental transaction failed")
 		}
+
+		removeCache("rental-*")
+		removeCache("ownership-*")
 	}
 
 	// Get pending fractions
-	fractions, err := fractionRepository.GetFractionList(0, 100000000, "", nil, &empty, &status, "created_at", "ASC")
+	fractions, err := fractionRepository.GetFractionList(ctx, 0, 100000000, "", nil, &empty, &status, "created_at", "ASC")
 
 	if err != nil {
 		fmt.Println("fraction")
@@ -410,19 +518,17 @@ func checkBlock() {
 		return
 	}
 
+	fractionReceipts := fetchReceipts(len(fractions), func(i int) string { return fractions[i].TransactionHash })
+
 	// Check all pending fractions
-	for _, fraction := range fractions {
+	for i, fraction := range fractions {
 		// Check if is in the monitoring only mode
-		if monitoringOnly {
+		if monitoringOnly || !stillLeading() {
 			break
 		}
 
-		transactionId := common.HexToHash(fraction.TransactionHash)
-
-		fmt.Println("fraction", fraction.TransactionHash)
-
 		// Get transaction receipt
-		receipt, err := ethClient.TransactionReceipt(ctx, transactionId)
+		receipt, err := fractionReceipts[i].receipt, fractionReceipts[i].err
 
 		if err != nil {
 			log.Print(err)
@@ -430,20 +536,56 @@ func checkBlock() {
 		}
 
 		if receipt.Status == 1 {
-			fmt.Println("fraction", 1)
 			fraction.Status = "active"
 
-			err = fractionRepository.UpdateFraction(fraction.ID, fraction)
+			err = fractionRepository.UpdateFraction(ctx, fraction.ID, fraction)
+
+			if err != nil {
+				fmt.Println("Updating failed, fraction: ", fraction.ID, ", error: ", err)
+			}
+
+			// Lock parent token in the vault
+			err = tokenRepository.UpdateTokenLocked(ctx, fraction.TokenParentID, true)
+
+			if err != nil {
+				fmt.Println("Updating failed, token: ", fraction.TokenParentID, ", error: ", err)
+			}
+
+			// Mint all shares to the owner
+			_, err = fractionShareRepository.InsertFractionShare(ctx, models.FractionShare{
+				FractionID:      fraction.ID,
+				UserID:          fraction.OwnerID,
+				Quantity:        fraction.TotalShares,
+				Reason:          "mint",
+				ReferenceID:     fraction.ID,
+				TransactionHash: fraction.TransactionHash,
+				CreatedAt:       sql.NullTime{Time: time.Now(), Valid: true},
+			})
+
+			if err != nil {
+				fmt.Println("Inserting failed, fraction share: ", fraction.ID, ", error: ", err)
+			}
+
+			publishEvent("fraction.active", map[string]interface{}{"fraction_id": fraction.ID, "transaction_hash": fraction.TransactionHash}, events.GlobalTopic, events.UserTopic(fraction.OwnerID), events.TokenTopic(fraction.TokenParentID), events.TokenTopic(fraction.TokenFractionID))
+
+			err = transactionFeeRepository.UpdateTransactionFeeStatusByHash(ctx, fraction.TransactionHash, "active")
 
 			if err != nil {
-				fmt.Println("Updating failed, fraction: ", fraction.PreviousID, ", from: ", fraction.ID, ", error: ", err)
+				fmt.Println("Updating failed, transaction fee: ", fraction.TransactionHash, ", error: ", err)
 			}
 		} else {
-			fmt.Println("fraction", 0)
-			err = fractionRepository.DeleteFraction(fraction.ID)
+			err = fractionRepository.DeleteFraction(ctx, fraction.ID)
 
 			if err != nil {
-				fmt.Println("Deleting failed, fraction: ", fraction.PreviousID, ", from: ", fraction.ID, ", error: ", err)
+				fmt.Println("Deleting failed, fraction: ", fraction.ID, ", error: ", err)
+			}
+
+			notify(fraction.OwnerID, helpers.NotificationTypeMintFailed, "Fractionalization failed", "The fraction mint was rejected on-chain.", fraction)
+
+			err = transactionFeeRepository.UpdateTransactionFeeStatusByHash(ctx, fraction.TransactionHash, "failed")
+
+			if err != nil {
+				fmt.Println("Updating failed, transaction fee: ", fraction.TransactionHash, ", error: ", err)
 			}
 		}
 
@@ -462,57 +604,690 @@ func checkBlock() {
 		}
 	}
 
+	// Get pending fraction buyouts
+	buyouts, err := fractionBuyoutRepository.GetFractionBuyoutList(ctx, 0, 100000000, nil, &status, "created_at", "ASC")
+
+	if err != nil {
+		fmt.Println("fraction buyout")
+		fmt.Println(err)
+		return
+	}
+
+	buyoutReceipts := fetchReceipts(len(buyouts), func(i int) string { return buyouts[i].TransactionHash })
+
+	// Check all pending fraction buyouts
+	for i, buyout := range buyouts {
+		// Check if is in the monitoring only mode
+		if monitoringOnly || !stillLeading() {
+			break
+		}
+
+		// Get transaction receipt
+		receipt, err := buyoutReceipts[i].receipt, buyoutReceipts[i].err
+
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+
+		if receipt.Status == 1 {
+			confirmFractionBuyout(buyout)
+		} else {
+			err = fractionBuyoutRepository.UpdateFractionBuyoutStatus(ctx, buyout.ID, "failed")
+
+			if err != nil {
+				fmt.Println("Updating failed, fraction buyout: ", buyout.ID, ", error: ", err)
+			}
+		}
+
+		removeCache("fraction-*")
+		removeCache("token-*")
+		removeCache("ownership-*")
+	}
+
 	fmt.Println("Number of pending tokens : ", len(tokens))
 	fmt.Println("Number of pending ownerships : ", len(ownerships))
 	fmt.Println("Number of pending collections : ", len(collections))
 	fmt.Println("Number of pending transactions : ", len(transactions))
 	fmt.Println("Number of pending rentals : ", len(rentals))
 	fmt.Println("Number of pending fractions : ", len(fractions))
+	fmt.Println("Number of pending fraction buyouts : ", len(buyouts))
+	fmt.Println("Number of pending changes : ", len(pendingChanges))
 	fmt.Println("--------------------------------------------")
 }
 
-func runCronJobs() {
-	s := gocron.NewScheduler(time.UTC)
+func expireRentals() {
+	rentals, err := rentalRepository.GetExpiredRentalList(ctx, time.Now())
+
+	if err != nil {
+		fmt.Println("Error getting expired rental list: ", err)
+		return
+	}
+
+	for _, rental := range rentals {
+		if !stillLeading() {
+			break
+		}
+
+		fromStatus := rental.Status
+
+		rental.EndedAt = rental.Timestamp
+		rental.Status = helpers.RentalStatusExpired
+		rental.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		err = rentalRepository.UpdateRental(ctx, rental.ID, rental)
+
+		if err != nil {
+			fmt.Println("Updating failed, rental: ", rental.ID, ", error: ", err)
+			continue
+		}
+
+		recordRentalEvent(rental, fromStatus, "Rental period ended")
+	}
+
+	if len(rentals) > 0 {
+		removeCache("rental-*")
+		removeCache("ownership-*")
+	}
+
+	fmt.Println("Number of expired rentals : ", len(rentals))
+}
+
+func purgeDeleted() {
+	before := time.Now().Add(-softDeleteRetention)
+
+	for _, table := range helpers.SoftDeletePurgeOrder {
+		count, err := trashRepository.PurgeDeleted(ctx, table, before)
+
+		if err != nil {
+			fmt.Println("Purging failed, table: ", table, ", error: ", err)
+			continue
+		}
+
+		fmt.Println("Number of purged ", table, " : ", count)
+	}
+}
+
+// confirmFractionBuyout opens a buyout offer once its escrow is on chain, a redemption settles right away
+func confirmFractionBuyout(buyout models.FractionBuyout) {
+	fraction, err := fractionRepository.GetFractionData(ctx, buyout.FractionID)
+
+	if err != nil {
+		fmt.Println("Getting failed, fraction: ", buyout.FractionID, ", error: ", err)
+		return
+	}
+
+	// Reject and refund offers on vaults that are already settled
+	if fraction.Status != "active" {
+		rejectFractionBuyouts([]models.FractionBuyout{buyout})
+		return
+	}
+
+	if buyout.Type == helpers.FractionBuyoutTypeBuyout {
+		err = fractionBuyoutRepository.ConfirmFractionBuyout(ctx, buyout.ID)
+
+		if err != nil {
+			fmt.Println("Updating failed, fraction buyout: ", buyout.ID, ", error: ", err)
+		}
+		return
+	}
+
+	settleFraction(fraction, append(getConfirmedFractionBuyouts(fraction.ID), buyout), &buyout)
+}
+
+// closeFractionBuyouts settles the vaults whose closing window passed with the highest offer that is still covered
+func closeFractionBuyouts() {
+	fractionIDs, err := fractionBuyoutRepository.GetClosedFractionIDList(ctx, time.Now().Add(-fractionBuyoutWindow))
+
+	if err != nil {
+		fmt.Println("Error getting closed fraction buyout list: ", err)
+		return
+	}
+
+	for _, fractionID := range fractionIDs {
+		if !stillLeading() {
+			break
+		}
+
+		fraction, err := fractionRepository.GetFractionData(ctx, fractionID)
+
+		if err != nil {
+			fmt.Println("Getting failed, fraction: ", fractionID, ", error: ", err)
+			continue
+		}
+
+		buyouts := getConfirmedFractionBuyouts(fraction.ID)
+
+		if fraction.Status != "active" {
+			rejectFractionBuyouts(buyouts)
+			continue
+		}
+
+		settleFraction(fraction, buyouts, nil)
+	}
+
+	if len(fractionIDs) > 0 {
+		removeCache("fraction-*")
+		removeCache("token-*")
+		removeCache("ownership-*")
+	}
+
+	fmt.Println("Number of closed fraction buyouts : ", len(fractionIDs))
+}
+
+func getConfirmedFractionBuyouts(fractionID uuid.UUID) []models.FractionBuyout {
+	status := "confirmed"
+	buyouts, err := fractionBuyoutRepository.GetFractionBuyoutList(ctx, 0, 100000000, &fractionID, &status, "created_at", "ASC")
+
+	if err != nil {
+		fmt.Println("Getting failed, fraction buyouts: ", fractionID, ", error: ", err)
+	}
+
+	return buyouts
+}
+
+// settleFraction settles the redemption when one is given, otherwise the highest covered offer, and refunds the rest
+func settleFraction(fraction models.Fraction, buyouts []models.FractionBuyout, redemption *models.FractionBuyout) {
+	balances, err := fractionShareRepository.GetFractionShareBalanceList(ctx, fraction.ID)
+
+	if err != nil {
+		fmt.Println("Getting failed, fraction shares: ", fraction.ID, ", error: ", err)
+		return
+	}
+
+	var winner models.FractionBuyout
+	var payouts []models.FractionPayout
+	var shares []models.FractionShare
+
+	if redemption != nil {
+		winner = *redemption
+
+		// Shares moved after the redemption was sent, nobody else may be bought out for nothing
+		if helpers.GetFractionShareBalance(balances, winner.UserID) < fraction.TotalShares {
+			rejectFractionBuyouts([]models.FractionBuyout{winner})
+			return
+		}
+
+		payouts, shares = helpers.CalculateFractionSettlement(balances, winner)
+	} else {
+		var isFound bool
+		winner, payouts, shares, isFound = helpers.SelectFractionBuyout(buyouts, balances)
+
+		// No offer covers the holders anymore, the vault stays open
+		if !isFound {
+			rejectFractionBuyouts(buyouts)
+			return
+		}
+	}
+
+	var rejectedIDs []uuid.UUID
+
+	for _, buyout := range buyouts {
+		if buyout.ID != winner.ID {
+			rejectedIDs = append(rejectedIDs, buyout.ID)
+		}
+	}
+
+	refunds := helpers.CalculateFractionRefunds(buyouts, winner, payouts)
+
+	isSettled, err := fractionBuyoutRepository.SettleFractionBuyout(ctx, winner, fraction, payouts, shares, rejectedIDs, refunds)
+
+	if err != nil {
+		fmt.Println("Settling failed, fraction buyout: ", winner.ID, ", error: ", err)
+		return
+	}
+
+	if !isSettled {
+		rejectFractionBuyouts(buyouts)
+		return
+	}
+
+	topics := []string{events.GlobalTopic, events.UserTopic(winner.UserID), events.TokenTopic(fraction.TokenParentID), events.TokenTopic(fraction.TokenFractionID)}
+
+	for _, payout := range payouts {
+		topics = append(topics, events.UserTopic(payout.UserID))
+	}
+
+	for _, refund := range refunds {
+		topics = append(topics, events.UserTopic(refund.UserID))
+	}
+
+	publishEvent("fraction.settled", map[string]interface{}{"fraction_id": fraction.ID, "buyout_id": winner.ID, "type": winner.Type}, topics...)
+}
+
+// rejectFractionBuyouts refunds the whole escrow of offers that can no longer win
+func rejectFractionBuyouts(buyouts []models.FractionBuyout) {
+	if len(buyouts) == 0 {
+		return
+	}
+
+	var ids []uuid.UUID
+
+	for _, buyout := range buyouts {
+		ids = append(ids, buyout.ID)
+	}
+
+	err := fractionBuyoutRepository.RejectFractionBuyouts(ctx, ids, helpers.CalculateFractionRefunds(buyouts, models.FractionBuyout{}, nil))
+
+	if err != nil {
+		fmt.Println("Rejecting failed, fraction buyouts: ", ids, ", error: ", err)
+	}
+}
+
+func redeemMintVoucher(transaction models.Transaction) {
+	token, err := tokenRepository.GetTokenData(ctx, transaction.TokenID)
+
+	if err != nil || token.Status != "lazy" {
+		return
+	}
+
+	// First confirmed purchase mints the token on-chain, the creator keeps the rest of the supply as an active ownership
+	isActivated, err := tokenRepository.ActivateLazyToken(ctx, token.ID, transaction.TransactionHash)
+
+	if err != nil || !isActivated {
+		fmt.Println("Activating failed, token: ", token.ID, ", error: ", err)
+		return
+	}
+
+	token.Status = "active"
+	token.TransactionHash = transaction.TransactionHash
+
+	voucher, err := mintVoucherRepository.GetMintVoucherByTokenID(ctx, token.ID)
+
+	if err != nil || voucher.Status == "" {
+		fmt.Println("Getting failed, mint voucher: ", token.ID, ", error: ", err)
+		return
+	}
+
+	err = mintVoucherRepository.UpdateMintVoucherStatus(ctx, voucher.ID, "redeemed", transaction.TransactionHash)
+
+	if err != nil {
+		fmt.Println("Updating failed, mint voucher: ", voucher.ID, ", error: ", err)
+	}
+
+	emitTokenWebhookEvent(helpers.WebhookEventTokenMinted, token)
+
+	removeCache("token-*")
+}
+
+func recordFractionShareTransfer(transaction models.Transaction) {
+	fraction, err := fractionRepository.GetFractionByTokenFractionID(ctx, transaction.TokenID)
+
+	if err != nil || fraction.Status != "active" {
+		return
+	}
+
+	for _, share := range []models.FractionShare{
+		{UserID: transaction.UserFromID, Quantity: -transaction.Quantity},
+		{UserID: transaction.UserToID, Quantity: transaction.Quantity},
+	} {
+		share.FractionID = fraction.ID
+		share.Reason = "transfer"
+		share.ReferenceID = transaction.ID
+		share.TransactionHash = transaction.TransactionHash
+		share.CreatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		_, err = fractionShareRepository.InsertFractionShare(ctx, share)
+
+		if err != nil {
+			fmt.Println("Inserting failed, fraction share: ", fraction.ID, ", error: ", err)
+		}
+	}
+}
+
+// applyPendingChange writes a confirmed change onto its resource and runs the side effects of the resource type
+func applyPendingChange(pendingChange models.PendingChange) {
+	switch pendingChange.ResourceType {
+	case helpers.VersionResourceToken:
+		if storePendingChange(pendingChange) {
+			removeCache("token-*")
+		}
+	case helpers.VersionResourceOwnership:
+		previousOwnership, err := ownershipRepository.GetOwnershipData(ctx, pendingChange.ResourceID)
+
+		if err != nil {
+			fmt.Println("Failed to get, ownership: ", pendingChange.ResourceID, ", error: ", err)
+			return
+		}
+
+		if !storePendingChange(pendingChange) {
+			return
+		}
+
+		ownership, err := ownershipRepository.GetOwnershipData(ctx, pendingChange.ResourceID)
+
+		if err != nil {
+			fmt.Println("Failed to get, ownership: ", pendingChange.ResourceID, ", error: ", err)
+			return
+		}
+
+		evaluateListingAlerts(previousOwnership, ownership)
+		refreshStatistics(ownership.TokenID)
+
+		removeCache("ownership-*")
+	case helpers.VersionResourceRental:
+		rental, err := rentalRepository.GetRentalData(ctx, pendingChange.ResourceID)
+
+		if err != nil {
+			fmt.Println("Failed to get, rental: ", pendingChange.ResourceID, ", error: ", err)
+			return
+		}
+
+		// An extension confirmed after the rental ended is not applied
+		if !helpers.IsValidRentalTransition(rental.Status, helpers.RentalStatusExtended) {
+			fmt.Println("Extension skipped, rental: ", pendingChange.ResourceID, ", status: ", rental.Status)
+
+			err = versionRepository.UpdatePendingChangeStatus(ctx, pendingChange.ID, helpers.PendingChangeStatusSkipped)
+
+			if err != nil {
+				fmt.Println("Updating failed, pending change: ", pendingChange.ID, ", error: ", err)
+			}
+
+			return
+		}
+
+		var rentalChange models.RentalChange
+
+		err = json.Unmarshal(pendingChange.Changes, &rentalChange)
+
+		if err != nil {
+			fmt.Println("Decoding failed, pending change: ", pendingChange.ID, ", error: ", err)
+			return
+		}
+
+		fromStatus := rental.Status
+
+		if !storePendingChange(pendingChange) {
+			return
+		}
+
+		rental, err = rentalRepository.GetRentalData(ctx, pendingChange.ResourceID)
+
+		if err != nil {
+			fmt.Println("Failed to get, rental: ", pendingChange.ResourceID, ", error: ", err)
+			return
+		}
+
+		days := 0
+
+		if rentalChange.ExtendDays != nil {
+			days = *rentalChange.ExtendDays
+		}
+
+		recordRentalEvent(rental, fromStatus, fmt.Sprintf("Extended by %d days", days))
+
+		removeCache("rental-*")
+		removeCache("ownership-*")
+	}
+}
+
+// storePendingChange returns whether the change has been applied, a change whose resource is gone is skipped
+func storePendingChange(pendingChange models.PendingChange) bool {
+	err := versionRepository.ApplyPendingChange(ctx, pendingChange)
 
-	s.Every(5).Seconds().Do(func() {
-		checkBlock()
-	})
+	if err == sql.ErrNoRows {
+		fmt.Println("Change skipped, ", pendingChange.ResourceType, ": ", pendingChange.ResourceID)
 
-	s.StartBlocking()
+		err = versionRepository.UpdatePendingChangeStatus(ctx, pendingChange.ID, helpers.PendingChangeStatusSkipped)
+
+		if err != nil {
+			fmt.Println("Updating failed, pending change: ", pendingChange.ID, ", error: ", err)
+		}
+
+		return false
+	}
+
+	if err != nil {
+		fmt.Println("Applying failed, pending change: ", pendingChange.ID, ", error: ", err)
+		return false
+	}
+
+	return true
 }
 
-func main() {
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
-		os.Exit(1)
+func recordVersion(resourceType string, resourceID uuid.UUID, transactionHash string) {
+	err := versionRepository.InsertResourceVersion(ctx, resourceType, resourceID, transactionHash)
+
+	if err != nil {
+		fmt.Println("Recording failed, ", resourceType, " version: ", resourceID, ", error: ", err)
 	}
+}
 
-	dbClient = config.CreateDBClient()
-	redisClient = config.CreateRedisClient()
+func recordRentalEvent(rental models.Rental, fromStatus string, note string) {
+	rentalEvent := helpers.NewRentalEvent(rental, fromStatus, rental.Status, helpers.GetEmptyUUID(), note)
+	rentalEvent.DispatchedAt = sql.NullTime{Time: time.Now(), Valid: true}
 
-	var success bool
-	rpcUrl, success = os.LookupEnv("RPC_URL")
-	if !success {
-		fmt.Fprintln(os.Stderr, "No RPC_URL - set the RPC_URL environment var and try again.")
-		os.Exit(1)
+	_, err := rentalEventRepository.InsertRentalEvent(ctx, rentalEvent)
+
+	if err != nil {
+		fmt.Println("Recording failed, rental event: ", rental.ID, ", error: ", err)
 	}
 
+	emitRentalEvent(rental, rentalEvent)
+}
+
+// dispatchRentalEvents emits the state changes made through the API, which only records them
+func dispatchRentalEvents() {
+	rentalEvents, err := rentalEventRepository.GetUndispatchedRentalEventList(ctx, 1000)
+
+	if err != nil {
+		fmt.Println("Error getting undispatched rental event list: ", err)
+		return
+	}
+
+	for _, rentalEvent := range rentalEvents {
+		if !stillLeading() {
+			break
+		}
+
+		rental, err := rentalRepository.GetRentalData(ctx, rentalEvent.RentalID)
+
+		if err != nil {
+			fmt.Println("Failed to get, rental: ", rentalEvent.RentalID, ", error: ", err)
+			continue
+		}
+
+		// Marked first so a failing emission is not repeated on every run
+		err = rentalEventRepository.UpdateRentalEventDispatched(ctx, rentalEvent.ID)
+
+		if err != nil {
+			fmt.Println("Updating failed, rental event: ", rentalEvent.ID, ", error: ", err)
+			continue
+		}
+
+		emitRentalEvent(rental, rentalEvent)
+	}
+}
+
+// emitRentalEvent notifies both parties and the webhook subscribers of a rental state change
+func emitRentalEvent(rental models.Rental, rentalEvent models.RentalEvent) {
+	switch rentalEvent.ToStatus {
+	case helpers.RentalStatusActive:
+		emitRentalWebhookEvent(helpers.WebhookEventRentalStarted, rental)
+		notify(rental.UserID, helpers.NotificationTypeRentalStarted, "Rental started", fmt.Sprintf("Your rental of %d item(s) for %d day(s) has started.", rental.Quantity, rental.Days), rental)
+		notify(rental.OwnerID, helpers.NotificationTypeRentalStarted, "Item rented", fmt.Sprintf("%d item(s) have been rented for %d day(s).", rental.Quantity, rental.Days), rental)
+	case helpers.RentalStatusExtended:
+		emitRentalWebhookEvent(helpers.WebhookEventRentalExtended, rental)
+		notify(rental.UserID, helpers.NotificationTypeRentalExtended, "Rental extended", fmt.Sprintf("Your rental now ends on %s.", rental.Timestamp.Time.Format(time.RFC1123)), rental)
+		notify(rental.OwnerID, helpers.NotificationTypeRentalExtended, "Rental extended", fmt.Sprintf("A rental of your item now ends on %s.", rental.Timestamp.Time.Format(time.RFC1123)), rental)
+	case helpers.RentalStatusReturned:
+		emitRentalWebhookEvent(helpers.WebhookEventRentalReturned, rental)
+		notify(rental.UserID, helpers.NotificationTypeRentalReturned, "Rental returned", fmt.Sprintf("Your rental has been returned with a refund of %v.", rental.RefundAmount), rental)
+		notify(rental.OwnerID, helpers.NotificationTypeRentalReturned, "Rental returned", fmt.Sprintf("A rental of your item has been returned, a refund of %v is owed to the renter.", rental.RefundAmount), rental)
+	case helpers.RentalStatusExpired:
+		emitRentalWebhookEvent(helpers.WebhookEventRentalExpired, rental)
+		notify(rental.UserID, helpers.NotificationTypeRentalExpired, "Rental expired", "Your rental period has ended.", rental)
+		notify(rental.OwnerID, helpers.NotificationTypeRentalExpired, "Rental expired", "A rental of your item has ended and the units are available again.", rental)
+	case helpers.RentalStatusCancelled:
+		emitRentalWebhookEvent(helpers.WebhookEventRentalCancelled, rental)
+
+		// Cancellations without an actor come from a rejected transaction
+		if rentalEvent.ActorID == helpers.GetEmptyUUID() {
+			notify(rental.UserID, helpers.NotificationTypeTransactionFailed, "Rental failed", "Your rental transaction was rejected on-chain.", rental)
+		} else {
+			notify(rental.OwnerID, helpers.NotificationTypeRentalCancelled, "Rental cancelled", "A pending rental of your item has been cancelled by the renter.", rental)
+		}
+	}
+
+	publishEvent("rental."+rentalEvent.ToStatus, map[string]interface{}{"rental_id": rental.ID, "token_id": rental.TokenID, "from_status": rentalEvent.FromStatus, "note": rentalEvent.Note}, events.UserTopic(rental.UserID), events.UserTopic(rental.OwnerID), events.TokenTopic(rental.TokenID))
+}
+
+func publishTransactionEvent(eventType string, transaction models.Transaction) {
+	publishEvent(eventType, map[string]interface{}{"transaction_id": transaction.ID, "token_id": transaction.TokenID, "type": transaction.Type, "transaction_hash": transaction.TransactionHash}, events.UserTopic(transaction.UserFromID), events.UserTopic(transaction.UserToID), events.TokenTopic(transaction.TokenID), events.CollectionTopic(transaction.CollectionID))
+}
+
+func publishEvent(eventType string, data interface{}, topics ...string) {
+	err := events.Publish(redisClient, eventType, data, topics...)
+
+	if err != nil {
+		fmt.Println("Publishing failed, event: ", eventType, ", error: ", err)
+	}
+}
+
+func removeCache(pattern string) {
+	keys, err := redisClient.Keys(pattern).Result()
+
+	if err != nil {
+		fmt.Println("Error getting cache keys: ", err)
+		return
+	}
+
+	for _, key := range keys {
+		err = redisClient.Del(key).Err()
+
+		if err != nil {
+			fmt.Println("Error removing cache: ", key, ", error: ", err)
+		}
+	}
+}
+
+func newScheduler() *gocron.Scheduler {
+	s := gocron.NewScheduler(time.UTC)
+
+	// A run that takes longer than its interval delays the next one instead of overlapping it
+	s.SingletonModeAll()
+
+	s.Every(workerConfig.LeaderElectionInterval).Do(campaign)
+	s.Every(workerConfig.ConfirmationInterval).Do(leading(checkBlock))
+	s.Every(workerConfig.RentalExpiryInterval).Do(leading(expireRentals))
+	s.Every(workerConfig.RentalExpiryInterval).Do(leading(dispatchRentalEvents))
+	s.Every(workerConfig.WebhookInterval).Do(leading(deliverWebhooks))
+	s.Every(workerConfig.EmailInterval).Do(leading(sendEmails))
+	s.Every(workerConfig.CollectionStatsInterval).Do(leading(rollupCollectionStats))
+	s.Every(workerConfig.StatisticsInterval).Do(leading(reconcileStatistics))
+	s.Every(workerConfig.RankingInterval).Do(leading(recomputeRankings))
+	s.Every(workerConfig.ViewFlushInterval).Do(leading(flushViews))
+	s.Every(workerConfig.TrendingInterval).Do(leading(recomputeTrending))
+	s.Every(workerConfig.PurgeInterval).Do(leading(purgeDeleted))
+	s.Every(workerConfig.BuyoutInterval).Do(leading(closeFractionBuyouts))
+
+	return s
+}
+
+// Setup wires the worker from the shared app
+func Setup(application *app.App) {
+	redisClient = application.Redis()
+	mailer = config.CreateMailer(application.Config.SMTP)
+	viewTracker = config.CreateViewTracker(redisClient, application.Config.App)
+	softDeleteRetention = application.Config.App.SoftDeleteRetention()
+	fractionBuyoutWindow = application.Config.App.FractionBuyoutWindow
+	rpcTimeout = application.Config.Chain.RPCTimeout
+	workerConfig = application.Config.Worker
+	leader = db.NewLeader(application.DB, leaderLockID)
+
+	// Requests are audited by the server, the changes the worker applies are recorded by the repositories
+	repositories.SetAuditHook(application.Repositories.Audit.RecordChange)
+
+	collectionRepository = application.Repositories.Collection
+	fractionRepository = application.Repositories.Fraction
+	fractionBuyoutRepository = application.Repositories.FractionBuyout
+	fractionShareRepository = application.Repositories.FractionShare
+	mintVoucherRepository = application.Repositories.MintVoucher
+	notificationRepository = application.Repositories.Notification
+	ownershipRepository = application.Repositories.Ownership
+	priceHistoryRepository = application.Repositories.PriceHistory
+	rankingRepository = application.Repositories.Ranking
+	statisticRepository = application.Repositories.Statistic
+	rentalRepository = application.Repositories.Rental
+	rentalEventRepository = application.Repositories.RentalEvent
+	tokenRepository = application.Repositories.Token
+	tokenCategoryRepository = application.Repositories.TokenCategory
+	transactionRepository = application.Repositories.Transaction
+	transactionFeeRepository = application.Repositories.TransactionFee
+	trashRepository = application.Repositories.Trash
+	userRepository = application.Repositories.User
+	versionRepository = application.Repositories.Version
+	viewRepository = application.Repositories.View
+	watchlistRepository = application.Repositories.Watchlist
+	webhookRepository = application.Repositories.Webhook
+}
+
+// Start connects to the blockchain network and runs the schedule in the background
+func Start(rpcUrl string) error {
 	var err error
 	ethClient, err = ethclient.DialContext(ctx, rpcUrl)
 
 	if err != nil {
-		fmt.Println("Failed to connect with blockchain network: ", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to connect with blockchain network: %w", err)
+	}
+
+	// Campaign before the first runs so the leader does not wait a whole interval
+	campaign()
+
+	scheduler = newScheduler()
+	scheduler.StartAsync()
+
+	return nil
+}
+
+// Stop lets the running jobs finish their batch, gives up the leadership and closes the blockchain client.
+// When stopCtx ends first the jobs are cancelled, and the lock is only given up once they have exited.
+func Stop(stopCtx context.Context) error {
+	stopped := make(chan struct{})
+
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+
+	var err error
+
+	select {
+	case <-stopped:
+	case <-stopCtx.Done():
+		err = fmt.Errorf("jobs still running: %w", stopCtx.Err())
+	}
+
+	// Cancelled before resigning, so no job campaigns again and takes the lock back
+	cancelJobs()
+
+	select {
+	case <-stopped:
+		// Hand the lock over right away, the standby replicas pick it up on their next campaign
+		resignCtx, cancel := context.WithTimeout(context.Background(), resignTimeout)
+		defer cancel()
+
+		if resignErr := leader.Resign(resignCtx); resignErr != nil && err == nil {
+			err = fmt.Errorf("resigning leadership: %w", resignErr)
+		}
+	case <-time.After(resignTimeout):
+		// A job is still writing, the lock is dropped with its connection when the process exits
+		fmt.Println("Jobs did not exit after cancelling, keeping the leadership until exit")
 	}
 
-	collectionRepository = repositories.NewCollectionRepository(dbClient)
-	fractionRepository = repositories.NewFractionRepository(dbClient)
-	ownershipRepository = repositories.NewOwnershipRepository(dbClient)
-	rentalRepository = repositories.NewRentalRepository(dbClient)
-	tokenRepository = repositories.NewTokenRepository(dbClient)
-	tokenCategoryRepository = repositories.NewTokenCategoryRepository(dbClient)
-	transactionRepository = repositories.NewTransactionRepository(dbClient)
-	userRepository = repositories.NewUserRepository(dbClient)
+	ethClient.Close()
+
+	return err
+}
+
+// Reindex rebuilds every derived table once, used after restores and manual data fixes
+func Reindex() {
+	reconcileStatistics()
+	rollupCollectionStats()
+
+	// Force the rankings to recompute even when no event arrived
+	redisClient.Set("ranking-dirty", 1, 0)
+	recomputeRankings()
 
-	runCronJobs()
+	recomputeTrending()
 }
@@ -0,0 +1,121 @@
+package cronjobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"metaedu-marketplace/events"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/templates"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// notify writes an inbox notification and queues the email copy to a verified address, following the user preference
+func notify(userID uuid.UUID, notificationType string, title string, body string, data interface{}) {
+	if userID == helpers.GetEmptyUUID() {
+		return
+	}
+
+	notificationPreference, err := notificationRepository.GetNotificationPreference(ctx, userID, notificationType)
+
+	if err != nil {
+		fmt.Println("Getting failed, notification preference: ", userID, ", error: ", err)
+		return
+	}
+
+	if notificationPreference.InApp {
+		dataBytes, err := json.Marshal(data)
+
+		if err != nil {
+			fmt.Println("Encoding failed, notification: ", notificationType, ", error: ", err)
+			return
+		}
+
+		notification := models.Notification{UserID: userID, Type: notificationType, Title: title, Body: body, Data: dataBytes}
+
+		notificationID, err := notificationRepository.InsertNotification(ctx, notification)
+
+		if err != nil {
+			fmt.Println("Inserting failed, notification: ", userID, ", error: ", err)
+		} else {
+			publishEvent("notification.created", map[string]interface{}{"notification_id": notificationID, "type": notificationType, "title": title}, events.UserTopic(userID))
+		}
+	}
+
+	if notificationPreference.Email {
+		user, err := userRepository.GetUserByID(ctx, userID)
+
+		// Mail only goes to addresses the user proved they own
+		if err != nil || user.Email == "" || !user.Verified {
+			return
+		}
+
+		subject, html, err := templates.RenderEmail(notificationType, templates.EmailData{Name: user.Name, Title: title, Body: body})
+
+		if err != nil {
+			fmt.Println("Rendering failed, email: ", notificationType, ", error: ", err)
+			return
+		}
+
+		_, err = notificationRepository.InsertOutboxEmail(ctx, models.OutboxEmail{
+			UserID:        userID,
+			Type:          notificationType,
+			ToAddress:     user.Email,
+			Subject:       subject,
+			Body:          html,
+			Status:        helpers.OutboxEmailStatusPending,
+			NextAttemptAt: sql.NullTime{Time: time.Now(), Valid: true},
+			UpdatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+			CreatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		})
+
+		if err != nil {
+			fmt.Println("Inserting failed, outbox email: ", userID, ", error: ", err)
+		}
+	}
+}
+
+// sendEmails sends the queued emails that are due and retries failed ones with a growing delay
+func sendEmails() {
+	outboxEmails, err := notificationRepository.GetDueOutboxEmailList(ctx, time.Now(), 100)
+
+	if err != nil {
+		fmt.Println("Error getting outbox email list: ", err)
+		return
+	}
+
+	for _, outboxEmail := range outboxEmails {
+		if !stillLeading() {
+			break
+		}
+
+		outboxEmail.Attempts++
+		outboxEmail.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		err = mailer.Send(outboxEmail.ToAddress, outboxEmail.Subject, outboxEmail.Body)
+
+		if err == nil {
+			outboxEmail.Status = helpers.OutboxEmailStatusSent
+			outboxEmail.LastError = ""
+			outboxEmail.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+		} else if outboxEmail.Attempts >= helpers.MaxEmailAttempts {
+			outboxEmail.Status = helpers.OutboxEmailStatusDead
+			outboxEmail.LastError = err.Error()
+		} else {
+			outboxEmail.Status = helpers.OutboxEmailStatusFailed
+			outboxEmail.LastError = err.Error()
+			outboxEmail.NextAttemptAt = sql.NullTime{Time: time.Now().Add(helpers.GetEmailRetryDelay(outboxEmail.Attempts)), Valid: true}
+		}
+
+		err = notificationRepository.UpdateOutboxEmail(ctx, outboxEmail.ID, outboxEmail)
+
+		if err != nil {
+			fmt.Println("Updating failed, outbox email: ", outboxEmail.ID, ", error: ", err)
+		}
+	}
+
+	fmt.Println("Number of sent emails : ", len(outboxEmails))
+}
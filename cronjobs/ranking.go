@@ -0,0 +1,100 @@
+package cronjobs
+
+import (
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+)
+
+// recordRankingEvents adds a confirmed transaction to the hourly ranking buckets
+func recordRankingEvents(transaction models.Transaction) {
+	now := time.Now()
+
+	if transaction.Type == "purchase" && transaction.Token.CollectionID != helpers.GetEmptyUUID() {
+		err := rankingRepository.IncrementRankingBucket(ctx, helpers.RankingTypeCollections, transaction.Token.CollectionID, now, transaction.Amount, 1)
+
+		if err != nil {
+			fmt.Println("Incrementing failed, collection ranking: ", transaction.Token.CollectionID, ", error: ", err)
+		}
+	}
+
+	// Creators earn from their own sales and rentals and from the royalties of resales
+	creatorEarning := 0.0
+	creatorCount := 0
+
+	if transaction.UserFromID == transaction.Token.CreatorID {
+		creatorEarning = transaction.Amount
+		creatorCount = 1
+	}
+
+	fees, err := transactionFeeRepository.GetTransactionFeeList(ctx, 0, 1000, &transaction.ID, nil, nil, nil, "created_at", "ASC")
+
+	if err != nil {
+		fmt.Println("Getting failed, transaction fees: ", transaction.ID, ", error: ", err)
+	}
+
+	for _, fee := range fees {
+		if fee.RecipientID == transaction.Token.CreatorID && transaction.UserFromID != transaction.Token.CreatorID {
+			creatorEarning = creatorEarning + fee.Amount
+		}
+	}
+
+	if creatorEarning > 0 {
+		err = rankingRepository.IncrementRankingBucket(ctx, helpers.RankingTypeCreators, transaction.Token.CreatorID, now, creatorEarning, creatorCount)
+
+		if err != nil {
+			fmt.Println("Incrementing failed, creator ranking: ", transaction.Token.CreatorID, ", error: ", err)
+		}
+	}
+
+	err = rankingRepository.IncrementRankingBucket(ctx, helpers.RankingTypeUsers, transaction.UserToID, now, transaction.Amount, 1)
+
+	if err != nil {
+		fmt.Println("Incrementing failed, user ranking: ", transaction.UserToID, ", error: ", err)
+	}
+
+	redisClient.Set("ranking-dirty", 1, 0)
+}
+
+// recomputeRankings re-ranks every window when a bucket changed or when the windows moved to a new hour
+func recomputeRankings() {
+	now := time.Now()
+	currentHour := now.UTC().Truncate(time.Hour).Format(time.RFC3339)
+
+	isDirty, err := redisClient.Exists("ranking-dirty").Result()
+
+	if err != nil {
+		fmt.Println("Error checking ranking state: ", err)
+		return
+	}
+
+	computedHour, err := redisClient.Get("ranking-computed-hour").Result()
+
+	if err != nil && err.Error() != "redis: nil" {
+		fmt.Println("Error checking ranking state: ", err)
+		return
+	}
+
+	if isDirty == 0 && computedHour == currentHour {
+		return
+	}
+
+	// Clear the flag first so events confirmed during the recompute trigger the next run
+	redisClient.Del("ranking-dirty")
+
+	for _, rankingType := range helpers.RankingTypes {
+		for window := range helpers.RankingWindows {
+			err = rankingRepository.RecomputeRankings(ctx, rankingType, window, now)
+
+			if err != nil {
+				fmt.Println("Recomputing failed, ranking: ", rankingType, ", window: ", window, ", error: ", err)
+				redisClient.Set("ranking-dirty", 1, 0)
+			}
+		}
+	}
+
+	redisClient.Set("ranking-computed-hour", currentHour, 0)
+
+	removeCache("ranking-list-*")
+}
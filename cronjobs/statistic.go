@@ -0,0 +1,87 @@
+package cronjobs
+
+import (
+	"fmt"
+	"metaedu-marketplace/helpers"
+
+	"github.com/google/uuid"
+)
+
+// refreshStatistics reconciles the derived statistics of a token and its collection after a confirmation
+func refreshStatistics(tokenID uuid.UUID) {
+	if tokenID == helpers.GetEmptyUUID() {
+		return
+	}
+
+	_, err := statisticRepository.ReconcileTokenStatistics(ctx, &tokenID)
+
+	if err != nil {
+		fmt.Println("Reconciling failed, token statistics: ", tokenID, ", error: ", err)
+	}
+
+	token, err := tokenRepository.GetTokenData(ctx, tokenID)
+
+	if err != nil {
+		fmt.Println("Failed to get, token: ", tokenID, ", error: ", err)
+		return
+	}
+
+	if token.CollectionID == helpers.GetEmptyUUID() {
+		return
+	}
+
+	previousCollection, err := collectionRepository.GetCollectionData(ctx, token.CollectionID)
+
+	if err != nil {
+		fmt.Println("Failed to get, collection: ", token.CollectionID, ", error: ", err)
+		return
+	}
+
+	count, err := statisticRepository.ReconcileCollectionStatistics(ctx, &token.CollectionID)
+
+	if err != nil {
+		fmt.Println("Reconciling failed, collection statistics: ", token.CollectionID, ", error: ", err)
+		return
+	}
+
+	if count == 0 {
+		return
+	}
+
+	removeCache("collection-*")
+
+	collection, err := collectionRepository.GetCollectionData(ctx, token.CollectionID)
+
+	if err != nil {
+		fmt.Println("Failed to get, collection: ", token.CollectionID, ", error: ", err)
+		return
+	}
+
+	if collection.Floor.Float64 != previousCollection.Floor.Float64 {
+		evaluateFloorAlerts(collection, previousCollection.Floor)
+	}
+}
+
+// reconcileStatistics repairs every counter that drifted from the derived statistics
+func reconcileStatistics() {
+	tokenCount, err := statisticRepository.ReconcileTokenStatistics(ctx, nil)
+
+	if err != nil {
+		fmt.Println("Reconciling failed, token statistics, error: ", err)
+		return
+	}
+
+	collectionCount, err := statisticRepository.ReconcileCollectionStatistics(ctx, nil)
+
+	if err != nil {
+		fmt.Println("Reconciling failed, collection statistics, error: ", err)
+		return
+	}
+
+	if tokenCount > 0 || collectionCount > 0 {
+		fmt.Println("Reconciled statistics, tokens: ", tokenCount, ", collections: ", collectionCount)
+
+		removeCache("token-*")
+		removeCache("collection-*")
+	}
+}
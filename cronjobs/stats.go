@@ -0,0 +1,81 @@
+package cronjobs
+
+import (
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+)
+
+// recordPricePoint keeps the confirmed price of a sale or rent for the price history
+func recordPricePoint(transaction models.Transaction) {
+	pricePointType := helpers.PricePointTypeSale
+
+	if transaction.Type == "rent" {
+		pricePointType = helpers.PricePointTypeRent
+	}
+
+	quantity := transaction.Quantity
+
+	if quantity < 1 {
+		quantity = 1
+	}
+
+	// The amount includes the fees paid on top of the price, which are not part of it
+	fees, err := transactionFeeRepository.GetTransactionFeeList(ctx, 0, 100, &transaction.ID, nil, nil, nil, "created_at", "ASC")
+
+	if err != nil {
+		fmt.Println("Getting failed, fees of transaction: ", transaction.ID, ", error: ", err)
+		return
+	}
+
+	amount := transaction.Amount
+
+	for _, fee := range fees {
+		amount -= fee.Amount
+	}
+
+	pricePoint := models.PricePoint{
+		TransactionID: transaction.ID,
+		TokenID:       transaction.TokenID,
+		CollectionID:  transaction.Token.CollectionID,
+		Type:          pricePointType,
+		Price:         amount / float64(quantity),
+		Quantity:      transaction.Quantity,
+		Amount:        amount,
+		BuyerID:       transaction.UserToID,
+		SellerID:      transaction.UserFromID,
+	}
+
+	err = priceHistoryRepository.InsertPricePoint(ctx, pricePoint)
+
+	if err != nil {
+		fmt.Println("Inserting failed, price point of transaction: ", transaction.ID, ", error: ", err)
+	}
+}
+
+// rollupCollectionStats refreshes the open bucket of every interval and closes the previous one
+func rollupCollectionStats() {
+	now := time.Now()
+
+	for interval, duration := range helpers.StatIntervals {
+		openBucket := helpers.GetStatBucket(interval, now)
+		previousBucket := openBucket.Add(-duration)
+
+		err := priceHistoryRepository.RollupCollectionStats(ctx, interval, previousBucket, openBucket, false)
+
+		if err != nil {
+			fmt.Println("Rollup failed, interval: ", interval, ", bucket: ", previousBucket, ", error: ", err)
+			continue
+		}
+
+		err = priceHistoryRepository.RollupCollectionStats(ctx, interval, openBucket, openBucket.Add(duration), true)
+
+		if err != nil {
+			fmt.Println("Rollup failed, interval: ", interval, ", bucket: ", openBucket, ", error: ", err)
+			continue
+		}
+	}
+
+	removeCache("collection-stats-*")
+}
@@ -0,0 +1,51 @@
+package cronjobs
+
+import (
+	"fmt"
+	"metaedu-marketplace/utils"
+)
+
+// flushViews stores the buffered views, a failed flush is kept in redis and retried on the next run
+func flushViews() {
+	for _, viewType := range []string{utils.ViewTypeToken, utils.ViewTypeCollection} {
+		counts, err := viewTracker.Drain(viewType)
+
+		if err != nil {
+			fmt.Println("Draining failed, views: ", viewType, ", error: ", err)
+			continue
+		}
+
+		if len(counts) == 0 {
+			continue
+		}
+
+		err = viewRepository.FlushViews(ctx, viewType, counts)
+
+		if err != nil {
+			fmt.Println("Flushing failed, views: ", viewType, ", error: ", err)
+			continue
+		}
+
+		err = viewTracker.Ack(viewType)
+
+		if err != nil {
+			fmt.Println("Acknowledging failed, views: ", viewType, ", error: ", err)
+		}
+
+		for id := range counts {
+			removeCache(fmt.Sprintf("%s-data-%s", viewType, id))
+		}
+	}
+}
+
+func recomputeTrending() {
+	for _, viewType := range []string{utils.ViewTypeToken, utils.ViewTypeCollection} {
+		err := viewRepository.RecomputeTrending(ctx, viewType)
+
+		if err != nil {
+			fmt.Println("Recomputing failed, trending: ", viewType, ", error: ", err)
+		}
+	}
+
+	removeCache("trending-list-*")
+}
@@ -0,0 +1,175 @@
+package cronjobs
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var webhookClient = utils.NewWebhookClient(10 * time.Second)
+
+func emitTokenWebhookEvent(eventType string, token models.Token) {
+	emitWebhookEvent(eventType, models.WebhookEventData{
+		ResourceID:      token.ID,
+		TokenID:         token.ID,
+		CollectionID:    token.CollectionID,
+		UserToID:        token.CreatorID,
+		Quantity:        token.Supply,
+		Amount:          token.InitialPrice,
+		TransactionHash: token.TransactionHash,
+	}, token.CreatorID)
+}
+
+func emitTransactionWebhookEvent(eventType string, transaction models.Transaction) {
+	emitWebhookEvent(eventType, models.WebhookEventData{
+		ResourceID:      transaction.ID,
+		TokenID:         transaction.TokenID,
+		CollectionID:    transaction.CollectionID,
+		UserFromID:      transaction.UserFromID,
+		UserToID:        transaction.UserToID,
+		Quantity:        transaction.Quantity,
+		Amount:          transaction.Amount,
+		TransactionHash: transaction.TransactionHash,
+	}, transaction.UserFromID, transaction.UserToID)
+}
+
+func emitRentalWebhookEvent(eventType string, rental models.Rental) {
+	emitWebhookEvent(eventType, models.WebhookEventData{
+		ResourceID:      rental.ID,
+		TokenID:         rental.TokenID,
+		UserFromID:      rental.OwnerID,
+		UserToID:        rental.UserID,
+		Quantity:        rental.Quantity,
+		Amount:          rental.Amount,
+		TransactionHash: rental.TransactionHash,
+	}, rental.OwnerID, rental.UserID)
+}
+
+// emitWebhookEvent queues a delivery for every endpoint subscribed to the event type that may see it
+func emitWebhookEvent(eventType string, data models.WebhookEventData, userIDs ...uuid.UUID) {
+	webhookEndpoints, err := webhookRepository.GetWebhookEndpointListByEventType(ctx, eventType, data.TokenID, userIDs)
+
+	if err != nil {
+		fmt.Println("Getting failed, webhook endpoints: ", eventType, ", error: ", err)
+		return
+	}
+
+	if len(webhookEndpoints) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"event": eventType, "data": data, "created_at": time.Now()})
+
+	if err != nil {
+		fmt.Println("Encoding failed, webhook event: ", eventType, ", error: ", err)
+		return
+	}
+
+	for _, webhookEndpoint := range webhookEndpoints {
+		_, err = webhookRepository.InsertWebhookDelivery(ctx, models.WebhookDelivery{
+			EndpointID:    webhookEndpoint.ID,
+			EventType:     eventType,
+			Payload:       payload,
+			Status:        helpers.WebhookDeliveryStatusPending,
+			NextAttemptAt: sql.NullTime{Time: time.Now(), Valid: true},
+			UpdatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+			CreatedAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		})
+
+		if err != nil {
+			fmt.Println("Inserting failed, webhook delivery: ", webhookEndpoint.ID, ", error: ", err)
+		}
+	}
+}
+
+func deliverWebhooks() {
+	webhookDeliveries, err := webhookRepository.GetDueWebhookDeliveryList(ctx, time.Now(), 100)
+
+	if err != nil {
+		fmt.Println("Error getting webhook delivery list: ", err)
+		return
+	}
+
+	for _, webhookDelivery := range webhookDeliveries {
+		if !stillLeading() {
+			break
+		}
+
+		webhookEndpoint, err := webhookRepository.GetWebhookEndpointData(ctx, webhookDelivery.EndpointID)
+
+		if err != nil {
+			fmt.Println("Getting failed, webhook endpoint: ", webhookDelivery.EndpointID, ", error: ", err)
+			continue
+		}
+
+		webhookDelivery.Attempts++
+		webhookDelivery.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		if webhookEndpoint.Url == "" || webhookEndpoint.Status != "active" {
+			webhookDelivery.Status = helpers.WebhookDeliveryStatusDead
+			webhookDelivery.LastError = "Webhook endpoint is not active"
+		} else {
+			webhookDelivery.ResponseStatus, err = sendWebhook(webhookEndpoint, webhookDelivery)
+
+			if err == nil {
+				webhookDelivery.Status = helpers.WebhookDeliveryStatusDelivered
+				webhookDelivery.LastError = ""
+				webhookDelivery.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+			} else if webhookDelivery.Attempts >= helpers.MaxWebhookAttempts {
+				webhookDelivery.Status = helpers.WebhookDeliveryStatusDead
+				webhookDelivery.LastError = err.Error()
+			} else {
+				webhookDelivery.Status = helpers.WebhookDeliveryStatusFailed
+				webhookDelivery.LastError = err.Error()
+				webhookDelivery.NextAttemptAt = sql.NullTime{Time: time.Now().Add(helpers.GetWebhookRetryDelay(webhookDelivery.Attempts)), Valid: true}
+			}
+		}
+
+		err = webhookRepository.UpdateWebhookDelivery(ctx, webhookDelivery.ID, webhookDelivery)
+
+		if err != nil {
+			fmt.Println("Updating failed, webhook delivery: ", webhookDelivery.ID, ", error: ", err)
+		}
+	}
+}
+
+func sendWebhook(webhookEndpoint models.WebhookEndpoint, webhookDelivery models.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookEndpoint.Url, bytes.NewReader(webhookDelivery.Payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-MetaEdu-Event", webhookDelivery.EventType)
+	request.Header.Set("X-MetaEdu-Delivery", webhookDelivery.ID.String())
+	request.Header.Set("X-MetaEdu-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-MetaEdu-Signature", "sha256="+utils.SignWebhookPayload(webhookEndpoint.Secret, timestamp, webhookDelivery.Payload))
+
+	response, err := webhookClient.Do(request)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("Webhook endpoint responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
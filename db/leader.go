@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+)
+
+// Leader holds a session advisory lock on its own connection, so one process per lock id leads at a time.
+// Postgres drops the lock with the connection, a crashed leader is replaced on the next campaign.
+type Leader struct {
+	db     *sql.DB
+	lockID int64
+	mutex  sync.Mutex
+	conn   *sql.Conn
+}
+
+func NewLeader(db *sql.DB, lockID int64) *Leader {
+	return &Leader{db: db, lockID: lockID}
+}
+
+// Campaign checks the lock is still held or tries to take it, and reports whether the process leads
+func (l *Leader) Campaign(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conn != nil {
+		_, err := l.conn.ExecContext(ctx, `SELECT 1`)
+
+		if err == nil {
+			return true, nil
+		}
+
+		// Whether the session died or the check timed out, closing the connection gives the lock up
+		l.discard()
+
+		return false, err
+	}
+
+	conn, err := l.db.Conn(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	var isAcquired bool
+
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.lockID).Scan(&isAcquired)
+
+	if err != nil || !isAcquired {
+		conn.Close()
+		return false, err
+	}
+
+	l.conn = conn
+
+	return true, nil
+}
+
+// Resign releases the lock so a standby takes over without waiting for its next campaign to notice a dead session
+func (l *Leader) Resign(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, l.lockID)
+
+	l.discard()
+
+	return err
+}
+
+// discard closes the connection instead of returning it to the pool, where it would keep the lock
+func (l *Leader) discard() {
+	l.conn.Raw(func(driverConn any) error {
+		return driver.ErrBadConn
+	})
+
+	l.conn.Close()
+	l.conn = nil
+}
@@ -0,0 +1,358 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Same lock key for every process so concurrent runs apply each migration once
+const migrationLockID = 7250420531
+
+var ErrDirtySchema = errors.New("schema is dirty, fix the failed migration and force its version")
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator applies the embedded migrations, the version is kept in the
+// schema_migrations table used by the golang-migrate CLI so both can be mixed
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db, migrations}, nil
+}
+
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+
+	for _, entry := range entries {
+		// 000001_init-schema.up.sql
+		name := entry.Name()
+		parts := strings.SplitN(strings.TrimSuffix(name, ".sql"), "_", 2)
+
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+
+		version, err := strconv.ParseUint(parts[0], 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s", name)
+		}
+
+		content, err := migrationFiles.ReadFile("migrations/" + name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[uint(version)]
+
+		if !ok {
+			migration = &Migration{Version: uint(version)}
+			byVersion[uint(version)] = migration
+		}
+
+		switch {
+		case strings.HasSuffix(parts[1], ".up"):
+			migration.Name = strings.TrimSuffix(parts[1], ".up")
+			migration.Up = string(content)
+		case strings.HasSuffix(parts[1], ".down"):
+			migration.Down = string(content)
+		default:
+			return nil, fmt.Errorf("migration %s is neither up nor down", name)
+		}
+	}
+
+	var migrations []Migration
+
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", migration.Version)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest is the version the binary was built for
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS "schema_migrations" ("version" BIGINT NOT NULL PRIMARY KEY, "dirty" BOOLEAN NOT NULL)`)
+
+	return err
+}
+
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func readVersion(q rowQuerier) (uint, bool, error) {
+	var version int64
+	var dirty bool
+
+	err := q.QueryRow(`SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint(version), dirty, nil
+}
+
+func writeVersion(tx *sql.Tx, version uint) error {
+	_, err := tx.Exec(`DELETE FROM schema_migrations`)
+
+	if err != nil {
+		return err
+	}
+
+	// No row means nothing is applied
+	if version == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, int64(version))
+
+	return err
+}
+
+// Version returns the applied version, 0 when the database is empty
+func (m *Migrator) Version() (uint, bool, error) {
+	err := m.ensureTable()
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	return readVersion(m.db)
+}
+
+func (m *Migrator) index(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// step applies the next or reverts the current migration and its version in one transaction,
+// nil is returned when there is nothing left to do
+func (m *Migrator) step(up bool) (*Migration, error) {
+	tx, err := m.db.Begin()
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	version, dirty, err := readVersion(tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dirty {
+		return nil, ErrDirtySchema
+	}
+
+	current := -1
+
+	if version != 0 {
+		current = m.index(version)
+
+		if current == -1 {
+			return nil, fmt.Errorf("database version %d is not a known migration", version)
+		}
+	}
+
+	var migration Migration
+	var statement string
+	var target uint
+
+	if up {
+		if current+1 >= len(m.migrations) {
+			return nil, nil
+		}
+
+		migration = m.migrations[current+1]
+		statement = migration.Up
+		target = migration.Version
+	} else {
+		if current == -1 {
+			return nil, nil
+		}
+
+		migration = m.migrations[current]
+		statement = migration.Down
+
+		if current > 0 {
+			target = m.migrations[current-1].Version
+		}
+
+		if statement == "" {
+			return nil, fmt.Errorf("migration %d has no down file", migration.Version)
+		}
+	}
+
+	_, err = tx.Exec(statement)
+
+	if err != nil {
+		return nil, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	err = writeVersion(tx, target)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &migration, tx.Commit()
+}
+
+// Up applies every pending migration and returns the ones that ran
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.run(true, -1)
+}
+
+// Down reverts the given number of migrations
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	return m.run(false, steps)
+}
+
+func (m *Migrator) run(up bool, steps int) ([]Migration, error) {
+	var applied []Migration
+
+	err := m.ensureTable()
+
+	if err != nil {
+		return applied, err
+	}
+
+	// The released init schema generates ids with uuid-ossp without creating it
+	if up {
+		_, err = m.db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+		if err != nil {
+			return applied, err
+		}
+	}
+
+	for steps != 0 {
+		migration, err := m.step(up)
+
+		if err != nil {
+			return applied, err
+		}
+
+		if migration == nil {
+			break
+		}
+
+		applied = append(applied, *migration)
+		steps--
+	}
+
+	return applied, nil
+}
+
+// Force sets the version without running anything, used to recover a dirty schema
+func (m *Migrator) Force(version uint) error {
+	if version != 0 && m.index(version) == -1 {
+		return fmt.Errorf("version %d is not a known migration", version)
+	}
+
+	err := m.ensureTable()
+
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.Begin()
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = writeVersion(tx, version)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CheckCurrent fails unless the database is at the latest embedded version
+func (m *Migrator) CheckCurrent() error {
+	version, dirty, err := m.Version()
+
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return ErrDirtySchema
+	}
+
+	if version != m.Latest() {
+		return fmt.Errorf("schema is at version %d, expected %d, run the migrate command", version, m.Latest())
+	}
+
+	return nil
+}
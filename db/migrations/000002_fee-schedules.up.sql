@@ -0,0 +1,41 @@
+CREATE TABLE "fee_schedules" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "title" VARCHAR NOT NULL,
+    "transaction_type" VARCHAR NOT NULL,
+    "fee_type" VARCHAR NOT NULL,
+    "value" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "category_id" UUID,
+    "collection_id" UUID,
+    "recipient_id" UUID,
+    "promotion" BOOLEAN NOT NULL DEFAULT 'false',
+    "starts_at" TIMESTAMP(3),
+    "ends_at" TIMESTAMP(3),
+    "status" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "fee_schedules_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "transaction_fees" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "transaction_id" UUID,
+    "fraction_id" UUID,
+    "fee_schedule_id" UUID NOT NULL,
+    "transaction_type" VARCHAR NOT NULL,
+    "fee_type" VARCHAR NOT NULL,
+    "value" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "base_amount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "amount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "recipient_id" UUID,
+    "status" VARCHAR NOT NULL,
+    "transaction_hash" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "transaction_fees_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "fee_schedules_transaction_type_idx" ON "fee_schedules" ("transaction_type", "status");
+CREATE INDEX "transaction_fees_transaction_id_idx" ON "transaction_fees" ("transaction_id");
+CREATE INDEX "transaction_fees_transaction_hash_idx" ON "transaction_fees" ("transaction_hash");
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS rental_refunds;
+DROP TABLE IF EXISTS rental_events;
+DROP INDEX IF EXISTS rentals_status_timestamp_idx;
+
+UPDATE "rentals" SET "status" = 'waiting_confirmation' WHERE "status" = 'pending';
+
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "ended_at";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "started_at";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "refund_amount";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "amount";
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "days";
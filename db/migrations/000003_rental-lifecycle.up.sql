@@ -0,0 +1,46 @@
+ALTER TABLE "rentals" ADD COLUMN "days" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "rentals" ADD COLUMN "amount" DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE "rentals" ADD COLUMN "refund_amount" DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE "rentals" ADD COLUMN "started_at" TIMESTAMP(3);
+ALTER TABLE "rentals" ADD COLUMN "ended_at" TIMESTAMP(3);
+
+UPDATE "rentals" SET "status" = 'pending' WHERE "status" = 'waiting_confirmation';
+
+CREATE TABLE "rental_events" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "rental_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "owner_id" UUID NOT NULL,
+    "token_id" UUID NOT NULL,
+    "actor_id" UUID,
+    "from_status" VARCHAR NOT NULL,
+    "to_status" VARCHAR NOT NULL,
+    "note" VARCHAR,
+    "dispatched_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "rental_events_pkey" PRIMARY KEY ("id")
+);
+
+-- What the owner owes the renter for the unused part of a returned rental
+CREATE TABLE "rental_refunds" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "rental_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "owner_id" UUID NOT NULL,
+    "amount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "status" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "rental_refunds_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "rentals_status_timestamp_idx" ON "rentals" ("status", "timestamp");
+CREATE INDEX "rental_events_rental_id_idx" ON "rental_events" ("rental_id");
+CREATE INDEX "rental_events_user_id_idx" ON "rental_events" ("user_id");
+CREATE INDEX "rental_events_owner_id_idx" ON "rental_events" ("owner_id");
+CREATE INDEX "rental_events_dispatched_at_idx" ON "rental_events" ("created_at") WHERE "dispatched_at" IS NULL;
+CREATE UNIQUE INDEX "rental_refunds_rental_id_key" ON "rental_refunds" ("rental_id");
+CREATE INDEX "rental_refunds_user_id_idx" ON "rental_refunds" ("user_id");
+CREATE INDEX "rental_refunds_owner_id_idx" ON "rental_refunds" ("owner_id");
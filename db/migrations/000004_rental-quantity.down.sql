@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS rentals_ownership_id_idx;
+
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "quantity";
@@ -0,0 +1,3 @@
+ALTER TABLE "rentals" ADD COLUMN "quantity" INTEGER NOT NULL DEFAULT 1;
+
+CREATE INDEX "rentals_ownership_id_idx" ON "rentals" ("ownership_id", "status");
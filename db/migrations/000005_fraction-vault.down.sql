@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS fraction_payouts;
+DROP TABLE IF EXISTS fraction_buyouts;
+DROP TABLE IF EXISTS fraction_shares;
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "locked";
+
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "settled_at";
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "reserve_price";
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "share_price";
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "total_shares";
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "ownership_id";
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "owner_id";
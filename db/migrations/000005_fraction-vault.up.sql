@@ -0,0 +1,57 @@
+ALTER TABLE "fractions" ADD COLUMN "owner_id" UUID;
+ALTER TABLE "fractions" ADD COLUMN "ownership_id" UUID;
+ALTER TABLE "fractions" ADD COLUMN "total_shares" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "fractions" ADD COLUMN "share_price" DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE "fractions" ADD COLUMN "reserve_price" DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE "fractions" ADD COLUMN "settled_at" TIMESTAMP(3);
+
+ALTER TABLE "tokens" ADD COLUMN "locked" BOOLEAN NOT NULL DEFAULT 'false';
+
+CREATE TABLE "fraction_shares" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "fraction_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "reason" VARCHAR NOT NULL,
+    "reference_id" UUID,
+    "transaction_hash" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "fraction_shares_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "fraction_buyouts" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "fraction_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "type" VARCHAR NOT NULL,
+    "price_per_share" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "amount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "status" VARCHAR NOT NULL,
+    "transaction_hash" VARCHAR NOT NULL,
+    "confirmed_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "fraction_buyouts_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "fraction_payouts" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "buyout_id" UUID NOT NULL,
+    "fraction_id" UUID NOT NULL,
+    "user_id" UUID NOT NULL,
+    "type" VARCHAR NOT NULL DEFAULT 'payout',
+    "shares" INTEGER NOT NULL,
+    "amount" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "status" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "fraction_payouts_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "fraction_shares_fraction_id_idx" ON "fraction_shares" ("fraction_id", "user_id");
+CREATE INDEX "fraction_buyouts_fraction_id_idx" ON "fraction_buyouts" ("fraction_id", "status");
+CREATE INDEX "fraction_payouts_fraction_id_idx" ON "fraction_payouts" ("fraction_id");
+CREATE INDEX "fraction_payouts_user_id_idx" ON "fraction_payouts" ("user_id");
@@ -0,0 +1,21 @@
+CREATE TABLE "mint_vouchers" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "token_id" UUID NOT NULL,
+    "creator_id" UUID NOT NULL,
+    "token_index" NUMERIC NOT NULL,
+    "uri" VARCHAR NOT NULL,
+    "supply" NUMERIC NOT NULL,
+    "price" DOUBLE PRECISION NOT NULL,
+    "royalty" INTEGER NOT NULL DEFAULT 0,
+    "nonce" VARCHAR NOT NULL,
+    "signature" VARCHAR NOT NULL,
+    "status" VARCHAR NOT NULL,
+    "transaction_hash" VARCHAR NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "mint_vouchers_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "mint_vouchers_token_id_key" ON "mint_vouchers"("token_id");
+CREATE UNIQUE INDEX "mint_vouchers_creator_id_nonce_key" ON "mint_vouchers"("creator_id", "nonce");
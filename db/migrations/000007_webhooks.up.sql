@@ -0,0 +1,33 @@
+CREATE TABLE "webhook_endpoints" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "url" VARCHAR NOT NULL,
+    "secret" VARCHAR NOT NULL,
+    "event_types" VARCHAR[] NOT NULL,
+    "status" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "webhook_endpoints_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "webhook_deliveries" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "endpoint_id" UUID NOT NULL,
+    "event_type" VARCHAR NOT NULL,
+    "payload" JSONB NOT NULL,
+    "status" VARCHAR NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "response_status" INTEGER NOT NULL DEFAULT 0,
+    "last_error" VARCHAR NOT NULL DEFAULT '',
+    "next_attempt_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "delivered_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "webhook_deliveries_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "webhook_endpoints_user_id_idx" ON "webhook_endpoints"("user_id");
+CREATE INDEX "webhook_deliveries_endpoint_id_idx" ON "webhook_deliveries"("endpoint_id");
+CREATE INDEX "webhook_deliveries_status_next_attempt_at_idx" ON "webhook_deliveries"("status", "next_attempt_at");
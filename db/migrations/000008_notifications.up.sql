@@ -0,0 +1,45 @@
+CREATE TABLE "notifications" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "type" VARCHAR NOT NULL,
+    "title" VARCHAR NOT NULL,
+    "body" VARCHAR NOT NULL,
+    "data" JSONB,
+    "read_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "notifications_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "notification_preferences" (
+    "user_id" UUID NOT NULL,
+    "type" VARCHAR NOT NULL,
+    "in_app" BOOLEAN NOT NULL DEFAULT 'true',
+    "email" BOOLEAN NOT NULL DEFAULT 'true',
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "notification_preferences_pkey" PRIMARY KEY ("user_id", "type")
+);
+
+-- Notification emails are queued here and sent by the worker, so a slow or failing SMTP server never holds up a job
+CREATE TABLE "email_outbox" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "type" VARCHAR NOT NULL,
+    "to_address" VARCHAR NOT NULL,
+    "subject" VARCHAR NOT NULL,
+    "body" TEXT NOT NULL,
+    "status" VARCHAR NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "last_error" VARCHAR NOT NULL DEFAULT '',
+    "next_attempt_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "sent_at" TIMESTAMP(3),
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "email_outbox_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "notifications_user_id_created_at_idx" ON "notifications"("user_id", "created_at");
+CREATE INDEX "email_outbox_status_next_attempt_at_idx" ON "email_outbox"("status", "next_attempt_at");
+CREATE INDEX "email_outbox_user_id_idx" ON "email_outbox"("user_id");
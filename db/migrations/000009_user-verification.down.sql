@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "user_addresses";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+ALTER TABLE "users" ALTER COLUMN "verified" DROP NOT NULL;
+ALTER TABLE "users" ALTER COLUMN "verified" SET DEFAULT 'yes';
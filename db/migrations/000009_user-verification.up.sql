@@ -0,0 +1,21 @@
+UPDATE "users" SET "verified" = 'false';
+ALTER TABLE "users" ALTER COLUMN "verified" SET DEFAULT 'false';
+ALTER TABLE "users" ALTER COLUMN "verified" SET NOT NULL;
+ALTER TABLE "users" ADD COLUMN "email_verified_at" TIMESTAMP(3);
+
+CREATE TABLE "user_addresses" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "address" VARCHAR NOT NULL,
+    "primary" BOOLEAN NOT NULL DEFAULT 'false',
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "user_addresses_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "user_addresses_address_key" ON "user_addresses"("address");
+CREATE INDEX "user_addresses_user_id_idx" ON "user_addresses"("user_id");
+
+INSERT INTO "user_addresses" ("user_id", "address", "primary")
+SELECT "id", LOWER("address"), 'true' FROM "users"
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "watchlists";
+
+DROP TABLE IF EXISTS "favorites";
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "favorite_count";
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "favorite_count";
@@ -0,0 +1,42 @@
+ALTER TABLE "tokens" ADD COLUMN "favorite_count" INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE "collections" ADD COLUMN "favorite_count" INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE "favorites" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "token_id" UUID,
+    "collection_id" UUID,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "favorites_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "favorites_target_check" CHECK (("token_id" IS NULL) <> ("collection_id" IS NULL))
+);
+
+CREATE TABLE "watchlists" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "user_id" UUID NOT NULL,
+    "token_id" UUID,
+    "collection_id" UUID,
+    "notify_sale" BOOLEAN NOT NULL DEFAULT 'true',
+    "notify_rent" BOOLEAN NOT NULL DEFAULT 'true',
+    "price_below" NUMERIC,
+    "notify_floor" BOOLEAN NOT NULL DEFAULT 'true',
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "watchlists_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "watchlists_target_check" CHECK (("token_id" IS NULL) <> ("collection_id" IS NULL))
+);
+
+CREATE UNIQUE INDEX "favorites_user_id_token_id_key" ON "favorites"("user_id", "token_id") WHERE "token_id" IS NOT NULL;
+
+CREATE UNIQUE INDEX "favorites_user_id_collection_id_key" ON "favorites"("user_id", "collection_id") WHERE "collection_id" IS NOT NULL;
+
+CREATE UNIQUE INDEX "watchlists_user_id_token_id_key" ON "watchlists"("user_id", "token_id") WHERE "token_id" IS NOT NULL;
+
+CREATE UNIQUE INDEX "watchlists_user_id_collection_id_key" ON "watchlists"("user_id", "collection_id") WHERE "collection_id" IS NOT NULL;
+
+CREATE INDEX "watchlists_token_id_idx" ON "watchlists"("token_id");
+
+CREATE INDEX "watchlists_collection_id_idx" ON "watchlists"("collection_id");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transactions_token_id_created_at_idx";
+
+DROP INDEX IF EXISTS "tokens_collection_id_idx";
+
+DROP INDEX IF EXISTS "tokens_creator_id_created_at_idx";
+
+DROP TABLE IF EXISTS "follows";
@@ -0,0 +1,20 @@
+CREATE TABLE "follows" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "follower_id" UUID NOT NULL,
+    "user_id" UUID,
+    "collection_id" UUID,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "follows_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "follows_target_check" CHECK (("user_id" IS NULL) <> ("collection_id" IS NULL))
+);
+
+CREATE UNIQUE INDEX "follows_follower_id_user_id_key" ON "follows"("follower_id", "user_id") WHERE "user_id" IS NOT NULL;
+
+CREATE UNIQUE INDEX "follows_follower_id_collection_id_key" ON "follows"("follower_id", "collection_id") WHERE "collection_id" IS NOT NULL;
+
+CREATE INDEX "tokens_creator_id_created_at_idx" ON "tokens"("creator_id", "created_at");
+
+CREATE INDEX "tokens_collection_id_idx" ON "tokens"("collection_id");
+
+CREATE INDEX "transactions_token_id_created_at_idx" ON "transactions"("token_id", "created_at");
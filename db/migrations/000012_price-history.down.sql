@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "collection_stats";
+
+DROP TABLE IF EXISTS "price_points";
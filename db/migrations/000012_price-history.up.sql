@@ -0,0 +1,48 @@
+CREATE TABLE "price_points" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "transaction_id" UUID NOT NULL,
+    "token_id" UUID NOT NULL,
+    "collection_id" UUID,
+    "type" VARCHAR NOT NULL,
+    "price" DOUBLE PRECISION NOT NULL,
+    "quantity" INTEGER NOT NULL,
+    "amount" DOUBLE PRECISION NOT NULL,
+    "buyer_id" UUID NOT NULL,
+    "seller_id" UUID NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "price_points_pkey" PRIMARY KEY ("id")
+);
+
+CREATE TABLE "collection_stats" (
+    "collection_id" UUID NOT NULL,
+    "interval" VARCHAR NOT NULL,
+    "bucket" TIMESTAMP(3) NOT NULL,
+    "open" DOUBLE PRECISION,
+    "high" DOUBLE PRECISION,
+    "low" DOUBLE PRECISION,
+    "close" DOUBLE PRECISION,
+    "volume" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "sales" INTEGER NOT NULL DEFAULT 0,
+    "unique_buyers" INTEGER NOT NULL DEFAULT 0,
+    "floor" DOUBLE PRECISION,
+    "listed_percentage" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "collection_stats_pkey" PRIMARY KEY ("collection_id", "interval", "bucket")
+);
+
+CREATE UNIQUE INDEX "price_points_transaction_id_key" ON "price_points"("transaction_id");
+
+CREATE INDEX "price_points_token_id_created_at_idx" ON "price_points"("token_id", "created_at");
+
+CREATE INDEX "price_points_collection_id_created_at_idx" ON "price_points"("collection_id", "created_at");
+
+-- Backfill from the confirmed transactions
+INSERT INTO "price_points" ("transaction_id", "token_id", "collection_id", "type", "price", "quantity", "amount", "buyer_id", "seller_id", "created_at")
+SELECT "transactions"."id", "transactions"."token_id", "tokens"."collection_id", CASE WHEN "transactions"."type" = 'rent' THEN 'rent' ELSE 'sale' END,
+    "transactions"."amount" / GREATEST("transactions"."quantity", 1), "transactions"."quantity", "transactions"."amount",
+    "transactions"."user_to_id", "transactions"."user_from_id", "transactions"."updated_at"
+FROM "transactions"
+INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+WHERE "transactions"."status" = 'active' AND "transactions"."type" IN ('purchase', 'rent');
@@ -0,0 +1,4 @@
+-- Column types stay aligned with the models, the code no longer writes the old formats
+ALTER TABLE "token_categories" ALTER COLUMN "transaction_hash" DROP DEFAULT;
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "initial_price";
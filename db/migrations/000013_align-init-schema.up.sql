@@ -0,0 +1,20 @@
+-- Databases created from the released init schema predate these columns and types, the derived statistics read them next
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+ALTER TABLE "tokens" ADD COLUMN IF NOT EXISTS "initial_price" DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE "token_categories" ALTER COLUMN "transaction_hash" SET DEFAULT '';
+
+-- Unset timestamps were stored as 0 and become NULL
+ALTER TABLE "rentals" ALTER COLUMN "timestamp" DROP NOT NULL;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'rentals' AND "column_name" = 'timestamp' AND "data_type" = 'numeric') THEN
+        ALTER TABLE "rentals" ALTER COLUMN "timestamp" TYPE TIMESTAMP(3) USING to_timestamp(NULLIF("timestamp", 0))::TIMESTAMP(3);
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'rentals' AND "column_name" = 'ownership_id' AND "data_type" = 'character varying') THEN
+        ALTER TABLE "rentals" ALTER COLUMN "ownership_id" TYPE UUID USING NULLIF("ownership_id", '')::UUID;
+    END IF;
+END $$;
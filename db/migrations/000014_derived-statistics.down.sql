@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "ownerships_token_id_status_idx";
+
+DROP INDEX IF EXISTS "transactions_token_id_status_idx";
+
+DROP VIEW IF EXISTS "collection_statistics";
+
+DROP VIEW IF EXISTS "token_statistics";
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "rankings";
+
+DROP TABLE IF EXISTS "ranking_buckets";
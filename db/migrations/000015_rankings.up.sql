@@ -0,0 +1,56 @@
+CREATE TABLE "ranking_buckets" (
+    "type" VARCHAR NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "bucket" TIMESTAMP(3) NOT NULL,
+    "volume" DOUBLE PRECISION NOT NULL DEFAULT 0,
+    "count" INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT "ranking_buckets_pkey" PRIMARY KEY ("type", "subject_id", "bucket")
+);
+
+CREATE TABLE "rankings" (
+    "type" VARCHAR NOT NULL,
+    "window" VARCHAR NOT NULL,
+    "rank" INTEGER NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "score" DOUBLE PRECISION NOT NULL,
+    "volume" DOUBLE PRECISION NOT NULL,
+    "count" INTEGER NOT NULL,
+    "previous_score" DOUBLE PRECISION NOT NULL,
+    "change_percentage" DOUBLE PRECISION,
+    "computed_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "rankings_pkey" PRIMARY KEY ("type", "window", "subject_id")
+);
+
+CREATE INDEX "ranking_buckets_type_bucket_idx" ON "ranking_buckets"("type", "bucket");
+
+CREATE INDEX "rankings_type_window_rank_idx" ON "rankings"("type", "window", "rank");
+
+-- Backfill the hourly buckets from the recorded price history
+INSERT INTO "ranking_buckets" ("type", "subject_id", "bucket", "volume", "count")
+SELECT 'collections', "collection_id", DATE_TRUNC('hour', "created_at"), SUM("amount"), COUNT(*)
+FROM "price_points"
+WHERE "type" = 'sale' AND "collection_id" IS NOT NULL
+GROUP BY "collection_id", DATE_TRUNC('hour', "created_at");
+
+INSERT INTO "ranking_buckets" ("type", "subject_id", "bucket", "volume", "count")
+SELECT 'creators', "price_points"."seller_id", DATE_TRUNC('hour', "price_points"."created_at"), SUM("price_points"."amount"), COUNT(*)
+FROM "price_points"
+INNER JOIN "tokens" ON "tokens"."id" = "price_points"."token_id"
+WHERE "tokens"."creator_id" = "price_points"."seller_id"
+GROUP BY "price_points"."seller_id", DATE_TRUNC('hour', "price_points"."created_at");
+
+INSERT INTO "ranking_buckets" ("type", "subject_id", "bucket", "volume", "count")
+SELECT 'creators', "transaction_fees"."recipient_id", DATE_TRUNC('hour', "transaction_fees"."updated_at"), SUM("transaction_fees"."amount"), 0
+FROM "transaction_fees"
+INNER JOIN "transactions" ON "transactions"."id" = "transaction_fees"."transaction_id"
+INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+WHERE "transaction_fees"."status" = 'active' AND "transaction_fees"."recipient_id" = "tokens"."creator_id"
+GROUP BY "transaction_fees"."recipient_id", DATE_TRUNC('hour', "transaction_fees"."updated_at")
+ON CONFLICT ("type", "subject_id", "bucket") DO UPDATE SET "volume" = "ranking_buckets"."volume" + EXCLUDED."volume";
+
+INSERT INTO "ranking_buckets" ("type", "subject_id", "bucket", "volume", "count")
+SELECT 'users', "buyer_id", DATE_TRUNC('hour', "created_at"), SUM("amount"), COUNT(*)
+FROM "price_points"
+GROUP BY "buyer_id", DATE_TRUNC('hour', "created_at");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "trending";
+
+DROP TABLE IF EXISTS "view_buckets";
@@ -0,0 +1,23 @@
+CREATE TABLE "view_buckets" (
+    "type" VARCHAR NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "bucket" TIMESTAMP(3) NOT NULL,
+    "views" INTEGER NOT NULL DEFAULT 0,
+
+    CONSTRAINT "view_buckets_pkey" PRIMARY KEY ("type", "subject_id", "bucket")
+);
+
+CREATE TABLE "trending" (
+    "type" VARCHAR NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "score" DOUBLE PRECISION NOT NULL,
+    "views" INTEGER NOT NULL,
+    "sales" INTEGER NOT NULL,
+    "computed_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "trending_pkey" PRIMARY KEY ("type", "subject_id")
+);
+
+CREATE INDEX "view_buckets_type_bucket_idx" ON "view_buckets"("type", "bucket");
+
+CREATE INDEX "trending_type_score_idx" ON "trending"("type", "score");
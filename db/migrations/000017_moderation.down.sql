@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "moderation_actions";
+
+DROP TABLE IF EXISTS "reports";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "moderation_status";
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "moderation_status";
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "moderation_status";
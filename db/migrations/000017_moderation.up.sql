@@ -0,0 +1,44 @@
+ALTER TABLE "tokens" ADD COLUMN "moderation_status" VARCHAR NOT NULL DEFAULT 'visible';
+
+ALTER TABLE "collections" ADD COLUMN "moderation_status" VARCHAR NOT NULL DEFAULT 'visible';
+
+ALTER TABLE "users" ADD COLUMN "moderation_status" VARCHAR NOT NULL DEFAULT 'visible';
+
+CREATE TABLE "reports" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "reporter_id" UUID NOT NULL,
+    "subject_type" VARCHAR NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "reason" VARCHAR NOT NULL,
+    "description" TEXT,
+    "status" VARCHAR NOT NULL DEFAULT 'open',
+    "resolved_by" UUID,
+    "resolved_at" TIMESTAMP(3),
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "reports_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "reports_subject_type_check" CHECK ("subject_type" IN ('token', 'collection', 'user')),
+    CONSTRAINT "reports_status_check" CHECK ("status" IN ('open', 'resolved', 'dismissed'))
+);
+
+CREATE TABLE "moderation_actions" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "moderator_id" UUID,
+    "subject_type" VARCHAR NOT NULL,
+    "subject_id" UUID NOT NULL,
+    "report_id" UUID,
+    "action" VARCHAR NOT NULL,
+    "previous_status" VARCHAR,
+    "new_status" VARCHAR,
+    "reason" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "moderation_actions_pkey" PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "reports_reporter_id_subject_key" ON "reports"("reporter_id", "subject_type", "subject_id") WHERE "status" = 'open';
+
+CREATE INDEX "reports_status_created_at_idx" ON "reports"("status", "created_at");
+
+CREATE INDEX "moderation_actions_subject_idx" ON "moderation_actions"("subject_type", "subject_id", "created_at");
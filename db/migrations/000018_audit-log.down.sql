@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "audit_logs";
+
+DROP FUNCTION IF EXISTS "audit_logs_prevent_change"();
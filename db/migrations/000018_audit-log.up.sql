@@ -0,0 +1,39 @@
+CREATE TABLE "audit_logs" (
+    "id" BIGSERIAL NOT NULL,
+    "actor_id" UUID,
+    "action" VARCHAR NOT NULL,
+    "method" VARCHAR NOT NULL,
+    "path" VARCHAR NOT NULL,
+    "resource_type" VARCHAR NOT NULL,
+    "resource_id" VARCHAR NOT NULL DEFAULT '',
+    "before" JSON NOT NULL DEFAULT 'null',
+    "after" JSON NOT NULL DEFAULT 'null',
+    "request_id" VARCHAR NOT NULL,
+    "ip" VARCHAR NOT NULL,
+    "user_agent" VARCHAR NOT NULL DEFAULT '',
+    "status_code" INTEGER NOT NULL,
+    "previous_hash" VARCHAR NOT NULL,
+    "hash" VARCHAR NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL,
+
+    CONSTRAINT "audit_logs_pkey" PRIMARY KEY ("id")
+);
+
+CREATE INDEX "audit_logs_actor_id_created_at_idx" ON "audit_logs"("actor_id", "created_at");
+
+CREATE INDEX "audit_logs_resource_idx" ON "audit_logs"("resource_type", "resource_id", "created_at");
+
+CREATE INDEX "audit_logs_request_id_idx" ON "audit_logs"("request_id");
+
+-- The log is append-only, rows can be neither changed nor removed
+CREATE FUNCTION "audit_logs_prevent_change"() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_logs_prevent_change" BEFORE UPDATE OR DELETE ON "audit_logs"
+    FOR EACH ROW EXECUTE PROCEDURE "audit_logs_prevent_change"();
+
+CREATE TRIGGER "audit_logs_prevent_truncate" BEFORE TRUNCATE ON "audit_logs"
+    FOR EACH STATEMENT EXECUTE PROCEDURE "audit_logs_prevent_change"();
@@ -0,0 +1,39 @@
+CREATE OR REPLACE VIEW "token_statistics" AS
+SELECT "tokens"."id" AS "token_id",
+    (SELECT COUNT(*) FROM "transactions" WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active') AS "number_of_transactions",
+    (SELECT COALESCE(SUM("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)), 0) FROM "transactions" WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active') AS "volume_transactions",
+    COALESCE(
+        (SELECT ("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)) / GREATEST("transactions"."quantity", 1)::DOUBLE PRECISION FROM "transactions"
+            WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active' AND "transactions"."type" = 'purchase'
+            ORDER BY "transactions"."created_at" DESC LIMIT 1),
+        "tokens"."initial_price"
+    ) AS "last_price"
+FROM "tokens"
+WHERE "tokens"."status" <> 'waiting_confirmation';
+
+CREATE OR REPLACE VIEW "collection_statistics" AS
+SELECT "collections"."id" AS "collection_id",
+    (SELECT COUNT(*) FROM "tokens" WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."status" = 'active') AS "number_of_items",
+    (SELECT COUNT(*) FROM "transactions" INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "transactions"."status" = 'active') AS "number_of_transactions",
+    (SELECT COALESCE(SUM("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)), 0) FROM "transactions" INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "transactions"."status" = 'active') AS "volume_transactions",
+    (SELECT COALESCE(MIN("ownerships"."sale_price"), 0) FROM "ownerships" INNER JOIN "tokens" ON "tokens"."id" = "ownerships"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."status" = 'active'
+            AND "ownerships"."status" = 'active' AND "ownerships"."available_for_sale" AND "ownerships"."quantity" > 0) AS "floor"
+FROM "collections"
+WHERE "collections"."status" <> 'waiting_confirmation';
+
+ALTER TABLE "token_categories" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "ownerships" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "deleted_at";
@@ -0,0 +1,57 @@
+ALTER TABLE "tokens" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "collections" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "ownerships" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "rentals" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "fractions" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "transactions" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+ALTER TABLE "token_categories" ADD COLUMN "deleted_at" TIMESTAMP(3);
+
+-- Deleted rows are only looked up by the trash listing and the retention purge
+CREATE INDEX "tokens_deleted_at_idx" ON "tokens"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "collections_deleted_at_idx" ON "collections"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "ownerships_deleted_at_idx" ON "ownerships"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "rentals_deleted_at_idx" ON "rentals"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "fractions_deleted_at_idx" ON "fractions"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "transactions_deleted_at_idx" ON "transactions"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE INDEX "token_categories_deleted_at_idx" ON "token_categories"("deleted_at") WHERE "deleted_at" IS NOT NULL;
+
+CREATE OR REPLACE VIEW "token_statistics" AS
+SELECT "tokens"."id" AS "token_id",
+    (SELECT COUNT(*) FROM "transactions" WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active' AND "transactions"."deleted_at" IS NULL) AS "number_of_transactions",
+    (SELECT COALESCE(SUM("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)), 0) FROM "transactions" WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active' AND "transactions"."deleted_at" IS NULL) AS "volume_transactions",
+    COALESCE(
+        (SELECT ("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)) / GREATEST("transactions"."quantity", 1)::DOUBLE PRECISION FROM "transactions"
+            WHERE "transactions"."token_id" = "tokens"."id" AND "transactions"."status" = 'active' AND "transactions"."type" = 'purchase' AND "transactions"."deleted_at" IS NULL
+            ORDER BY "transactions"."created_at" DESC LIMIT 1),
+        "tokens"."initial_price"
+    ) AS "last_price"
+FROM "tokens"
+WHERE "tokens"."status" <> 'waiting_confirmation' AND "tokens"."deleted_at" IS NULL;
+
+CREATE OR REPLACE VIEW "collection_statistics" AS
+SELECT "collections"."id" AS "collection_id",
+    (SELECT COUNT(*) FROM "tokens" WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."status" = 'active' AND "tokens"."deleted_at" IS NULL) AS "number_of_items",
+    (SELECT COUNT(*) FROM "transactions" INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."deleted_at" IS NULL
+            AND "transactions"."status" = 'active' AND "transactions"."deleted_at" IS NULL) AS "number_of_transactions",
+    (SELECT COALESCE(SUM("transactions"."amount" - COALESCE((SELECT SUM("transaction_fees"."amount") FROM "transaction_fees" WHERE "transaction_fees"."transaction_id" = "transactions"."id"), 0)), 0) FROM "transactions" INNER JOIN "tokens" ON "tokens"."id" = "transactions"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."deleted_at" IS NULL
+            AND "transactions"."status" = 'active' AND "transactions"."deleted_at" IS NULL) AS "volume_transactions",
+    (SELECT COALESCE(MIN("ownerships"."sale_price"), 0) FROM "ownerships" INNER JOIN "tokens" ON "tokens"."id" = "ownerships"."token_id"
+        WHERE "tokens"."collection_id" = "collections"."id" AND "tokens"."status" = 'active' AND "tokens"."deleted_at" IS NULL
+            AND "ownerships"."status" = 'active' AND "ownerships"."available_for_sale" AND "ownerships"."quantity" > 0
+            AND "ownerships"."deleted_at" IS NULL) AS "floor"
+FROM "collections"
+WHERE "collections"."status" <> 'waiting_confirmation' AND "collections"."deleted_at" IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "resource_versions";
+
+DROP TABLE IF EXISTS "pending_changes";
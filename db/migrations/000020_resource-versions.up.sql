@@ -0,0 +1,135 @@
+CREATE TABLE "pending_changes" (
+    "id" UUID NOT NULL DEFAULT (uuid_generate_v4()),
+    "resource_type" VARCHAR NOT NULL,
+    "resource_id" UUID NOT NULL,
+    "changes" JSONB NOT NULL,
+    "transaction_hash" VARCHAR NOT NULL,
+    "status" VARCHAR NOT NULL DEFAULT 'pending',
+    "applied_at" TIMESTAMP(3),
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "pending_changes_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "pending_changes_resource_type_check" CHECK ("resource_type" IN ('token', 'ownership', 'rental')),
+    CONSTRAINT "pending_changes_status_check" CHECK ("status" IN ('pending', 'applied', 'failed', 'skipped'))
+);
+
+CREATE TABLE "resource_versions" (
+    "id" BIGSERIAL NOT NULL,
+    "resource_type" VARCHAR NOT NULL,
+    "resource_id" UUID NOT NULL,
+    "version" INTEGER NOT NULL,
+    "data" JSONB NOT NULL,
+    "change_id" UUID,
+    "transaction_hash" VARCHAR,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "resource_versions_pkey" PRIMARY KEY ("id"),
+    CONSTRAINT "resource_versions_resource_type_check" CHECK ("resource_type" IN ('token', 'collection', 'ownership', 'rental'))
+);
+
+CREATE INDEX "pending_changes_status_created_at_idx" ON "pending_changes"("status", "created_at");
+
+CREATE INDEX "pending_changes_resource_idx" ON "pending_changes"("resource_type", "resource_id", "created_at");
+
+CREATE INDEX "pending_changes_transaction_hash_idx" ON "pending_changes"("transaction_hash");
+
+CREATE UNIQUE INDEX "resource_versions_resource_version_key" ON "resource_versions"("resource_type", "resource_id", "version");
+
+-- Databases created from the old dump stage changes as copies with previous_id, the pending copies become pending changes
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'tokens' AND "column_name" = 'previous_id') THEN
+        INSERT INTO "pending_changes" ("resource_type", "resource_id", "changes", "transaction_hash", "created_at")
+        SELECT 'token', "copies"."previous_id", jsonb_build_object('fraction_id', "copies"."fraction_id", 'source_id', "copies"."source_id"), "copies"."transaction_hash", "copies"."created_at"
+        FROM "tokens" "copies"
+        WHERE "copies"."previous_id" <> '00000000-0000-0000-0000-000000000000' AND "copies"."status" = 'waiting_confirmation' AND "copies"."deleted_at" IS NULL;
+
+        DELETE FROM "tokens" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'ownerships' AND "column_name" = 'previous_id') THEN
+        INSERT INTO "pending_changes" ("resource_type", "resource_id", "changes", "transaction_hash", "created_at")
+        SELECT 'ownership', "copies"."previous_id", jsonb_build_object(
+                'user_id', "copies"."user_id",
+                'quantity_delta', "copies"."quantity" - "ownerships"."quantity",
+                'sale_price', "copies"."sale_price",
+                'rent_cost', "copies"."rent_cost",
+                'available_for_sale', "copies"."available_for_sale",
+                'available_for_rent', "copies"."available_for_rent"
+            ), "copies"."transaction_hash", "copies"."created_at"
+        FROM "ownerships" "copies"
+        INNER JOIN "ownerships" ON "ownerships"."id" = "copies"."previous_id"
+        WHERE "copies"."status" = 'waiting_confirmation' AND "copies"."deleted_at" IS NULL;
+
+        DELETE FROM "ownerships" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'rentals' AND "column_name" = 'previous_id') THEN
+        INSERT INTO "pending_changes" ("resource_type", "resource_id", "changes", "transaction_hash", "created_at")
+        SELECT 'rental', "copies"."previous_id", jsonb_build_object(
+                'timestamp', "copies"."timestamp",
+                'extend_days', "copies"."days",
+                'extend_amount', "copies"."amount",
+                'status', 'extended'
+            ), "copies"."transaction_hash", "copies"."created_at"
+        FROM "rentals" "copies"
+        WHERE "copies"."previous_id" <> '00000000-0000-0000-0000-000000000000' AND "copies"."status" = 'pending' AND "copies"."deleted_at" IS NULL;
+
+        DELETE FROM "rentals" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'collections' AND "column_name" = 'previous_id') THEN
+        DELETE FROM "collections" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    -- Nothing stages changes of these as copies anymore, and they have no pending change type, so their copies are dropped like collections
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'fractions' AND "column_name" = 'previous_id') THEN
+        DELETE FROM "fractions" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'transactions' AND "column_name" = 'previous_id') THEN
+        DELETE FROM "transactions" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'token_categories' AND "column_name" = 'previous_id') THEN
+        DELETE FROM "token_categories" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM "information_schema"."columns" WHERE "table_name" = 'users' AND "column_name" = 'previous_id') THEN
+        DELETE FROM "users" WHERE "previous_id" <> '00000000-0000-0000-0000-000000000000';
+    END IF;
+END $$;
+
+ALTER TABLE "tokens" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "collections" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "ownerships" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "rentals" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "fractions" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "token_categories" DROP COLUMN IF EXISTS "previous_id";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "previous_id";
+
+-- Confirmed resources start their history at version 1
+INSERT INTO "resource_versions" ("resource_type", "resource_id", "version", "data", "transaction_hash", "created_at")
+SELECT 'token', "tokens"."id", 1, row_to_json("tokens")::JSONB, "tokens"."transaction_hash", "tokens"."updated_at"
+FROM "tokens" WHERE "tokens"."status" <> 'waiting_confirmation' AND "tokens"."deleted_at" IS NULL;
+
+INSERT INTO "resource_versions" ("resource_type", "resource_id", "version", "data", "transaction_hash", "created_at")
+SELECT 'collection', "collections"."id", 1, row_to_json("collections")::JSONB, "collections"."transaction_hash", "collections"."updated_at"
+FROM "collections" WHERE "collections"."status" <> 'waiting_confirmation' AND "collections"."deleted_at" IS NULL;
+
+INSERT INTO "resource_versions" ("resource_type", "resource_id", "version", "data", "transaction_hash", "created_at")
+SELECT 'ownership', "ownerships"."id", 1, row_to_json("ownerships")::JSONB, "ownerships"."transaction_hash", "ownerships"."updated_at"
+FROM "ownerships" WHERE "ownerships"."status" <> 'waiting_confirmation' AND "ownerships"."deleted_at" IS NULL;
+
+INSERT INTO "resource_versions" ("resource_type", "resource_id", "version", "data", "transaction_hash", "created_at")
+SELECT 'rental', "rentals"."id", 1, row_to_json("rentals")::JSONB, "rentals"."transaction_hash", "rentals"."updated_at"
+FROM "rentals" WHERE "rentals"."status" <> 'pending' AND "rentals"."deleted_at" IS NULL;
@@ -0,0 +1,47 @@
+DROP INDEX IF EXISTS "fractions_token_fraction_id_idx";
+DROP INDEX IF EXISTS "fractions_token_parent_id_idx";
+DROP INDEX IF EXISTS "transactions_collection_id_idx";
+DROP INDEX IF EXISTS "transactions_ownership_id_idx";
+DROP INDEX IF EXISTS "transactions_user_to_id_idx";
+DROP INDEX IF EXISTS "transactions_user_from_id_idx";
+DROP INDEX IF EXISTS "rentals_ownership_id_idx";
+DROP INDEX IF EXISTS "rentals_token_id_idx";
+DROP INDEX IF EXISTS "rentals_owner_id_idx";
+DROP INDEX IF EXISTS "rentals_user_id_idx";
+DROP INDEX IF EXISTS "ownerships_user_id_idx";
+DROP INDEX IF EXISTS "collections_creator_id_idx";
+DROP INDEX IF EXISTS "tokens_category_id_idx";
+
+ALTER TABLE "fractions" DROP CONSTRAINT IF EXISTS "fractions_shares_check";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_amount_check";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_status_check";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_amount_check";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_quantity_check";
+ALTER TABLE "ownerships" DROP CONSTRAINT IF EXISTS "ownerships_price_check";
+ALTER TABLE "ownerships" DROP CONSTRAINT IF EXISTS "ownerships_quantity_check";
+ALTER TABLE "tokens" DROP CONSTRAINT IF EXISTS "tokens_price_check";
+ALTER TABLE "tokens" DROP CONSTRAINT IF EXISTS "tokens_supply_check";
+
+ALTER TABLE "fractions" DROP CONSTRAINT IF EXISTS "fractions_ownership_id_fkey";
+ALTER TABLE "fractions" DROP CONSTRAINT IF EXISTS "fractions_owner_id_fkey";
+ALTER TABLE "fractions" DROP CONSTRAINT IF EXISTS "fractions_token_fraction_id_fkey";
+ALTER TABLE "fractions" DROP CONSTRAINT IF EXISTS "fractions_token_parent_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_rental_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_collection_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_ownership_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_token_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_user_to_id_fkey";
+ALTER TABLE "transactions" DROP CONSTRAINT IF EXISTS "transactions_user_from_id_fkey";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_ownership_id_fkey";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_token_id_fkey";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_owner_id_fkey";
+ALTER TABLE "rentals" DROP CONSTRAINT IF EXISTS "rentals_user_id_fkey";
+ALTER TABLE "ownerships" DROP CONSTRAINT IF EXISTS "ownerships_token_id_fkey";
+ALTER TABLE "ownerships" DROP CONSTRAINT IF EXISTS "ownerships_user_id_fkey";
+ALTER TABLE "collections" DROP CONSTRAINT IF EXISTS "collections_category_id_fkey";
+ALTER TABLE "collections" DROP CONSTRAINT IF EXISTS "collections_creator_id_fkey";
+ALTER TABLE "tokens" DROP CONSTRAINT IF EXISTS "tokens_collection_id_fkey";
+ALTER TABLE "tokens" DROP CONSTRAINT IF EXISTS "tokens_category_id_fkey";
+ALTER TABLE "tokens" DROP CONSTRAINT IF EXISTS "tokens_creator_id_fkey";
+
+-- Column types and nullability stay aligned with the models, the code no longer writes the old formats
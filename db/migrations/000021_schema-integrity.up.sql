@@ -0,0 +1,73 @@
+-- Align the nullability of optional references with the models
+ALTER TABLE "collections" ALTER COLUMN "category_id" DROP NOT NULL;
+ALTER TABLE "transactions" ALTER COLUMN "collection_id" DROP NOT NULL;
+ALTER TABLE "transactions" ALTER COLUMN "ownership_id" DROP NOT NULL;
+
+-- Missing references used to be stored as the empty UUID
+UPDATE "tokens" SET "category_id" = NULL WHERE "category_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "tokens" SET "collection_id" = NULL WHERE "collection_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "tokens" SET "source_id" = NULL WHERE "source_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "tokens" SET "fraction_id" = NULL WHERE "fraction_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "collections" SET "category_id" = NULL WHERE "category_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "ownerships" SET "user_id" = NULL WHERE "user_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "transactions" SET "ownership_id" = NULL WHERE "ownership_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "transactions" SET "collection_id" = NULL WHERE "collection_id" = '00000000-0000-0000-0000-000000000000';
+UPDATE "transactions" SET "rental_id" = NULL WHERE "rental_id" = '00000000-0000-0000-0000-000000000000';
+
+-- Foreign keys are not validated against rows written before they existed
+ALTER TABLE "tokens" ADD CONSTRAINT "tokens_creator_id_fkey" FOREIGN KEY ("creator_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "tokens" ADD CONSTRAINT "tokens_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "token_categories"("id") ON DELETE SET NULL NOT VALID;
+ALTER TABLE "tokens" ADD CONSTRAINT "tokens_collection_id_fkey" FOREIGN KEY ("collection_id") REFERENCES "collections"("id") ON DELETE SET NULL NOT VALID;
+
+ALTER TABLE "collections" ADD CONSTRAINT "collections_creator_id_fkey" FOREIGN KEY ("creator_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "collections" ADD CONSTRAINT "collections_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "token_categories"("id") ON DELETE SET NULL NOT VALID;
+
+ALTER TABLE "ownerships" ADD CONSTRAINT "ownerships_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "ownerships" ADD CONSTRAINT "ownerships_token_id_fkey" FOREIGN KEY ("token_id") REFERENCES "tokens"("id") ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_owner_id_fkey" FOREIGN KEY ("owner_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_token_id_fkey" FOREIGN KEY ("token_id") REFERENCES "tokens"("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_ownership_id_fkey" FOREIGN KEY ("ownership_id") REFERENCES "ownerships"("id") ON DELETE CASCADE NOT VALID;
+
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_user_from_id_fkey" FOREIGN KEY ("user_from_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_user_to_id_fkey" FOREIGN KEY ("user_to_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_token_id_fkey" FOREIGN KEY ("token_id") REFERENCES "tokens"("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_ownership_id_fkey" FOREIGN KEY ("ownership_id") REFERENCES "ownerships"("id") ON DELETE SET NULL NOT VALID;
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_collection_id_fkey" FOREIGN KEY ("collection_id") REFERENCES "collections"("id") ON DELETE SET NULL NOT VALID;
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_rental_id_fkey" FOREIGN KEY ("rental_id") REFERENCES "rentals"("id") ON DELETE SET NULL NOT VALID;
+
+ALTER TABLE "fractions" ADD CONSTRAINT "fractions_token_parent_id_fkey" FOREIGN KEY ("token_parent_id") REFERENCES "tokens"("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "fractions" ADD CONSTRAINT "fractions_token_fraction_id_fkey" FOREIGN KEY ("token_fraction_id") REFERENCES "tokens"("id") ON DELETE CASCADE NOT VALID;
+ALTER TABLE "fractions" ADD CONSTRAINT "fractions_owner_id_fkey" FOREIGN KEY ("owner_id") REFERENCES "users"("id") NOT VALID;
+ALTER TABLE "fractions" ADD CONSTRAINT "fractions_ownership_id_fkey" FOREIGN KEY ("ownership_id") REFERENCES "ownerships"("id") ON DELETE SET NULL NOT VALID;
+
+-- Check constraints
+ALTER TABLE "tokens" ADD CONSTRAINT "tokens_supply_check" CHECK ("supply" >= 0) NOT VALID;
+ALTER TABLE "tokens" ADD CONSTRAINT "tokens_price_check" CHECK ("last_price" >= 0 AND "initial_price" >= 0) NOT VALID;
+
+ALTER TABLE "ownerships" ADD CONSTRAINT "ownerships_quantity_check" CHECK ("quantity" >= 0) NOT VALID;
+ALTER TABLE "ownerships" ADD CONSTRAINT "ownerships_price_check" CHECK ("sale_price" >= 0 AND "initial_price" >= 0 AND "rent_cost" >= 0) NOT VALID;
+
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_quantity_check" CHECK ("quantity" > 0) NOT VALID;
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_amount_check" CHECK ("days" >= 0 AND "amount" >= 0 AND "refund_amount" >= 0) NOT VALID;
+ALTER TABLE "rentals" ADD CONSTRAINT "rentals_status_check" CHECK ("status" IN ('pending', 'active', 'expired', 'returned', 'extended', 'cancelled')) NOT VALID;
+
+ALTER TABLE "transactions" ADD CONSTRAINT "transactions_amount_check" CHECK ("quantity" >= 0 AND "amount" >= 0 AND "gas_fee" >= 0) NOT VALID;
+
+ALTER TABLE "fractions" ADD CONSTRAINT "fractions_shares_check" CHECK ("total_shares" >= 0 AND "share_price" >= 0 AND "reserve_price" >= 0) NOT VALID;
+
+-- Indexes for the foreign keys that are not covered yet
+CREATE INDEX IF NOT EXISTS "tokens_category_id_idx" ON "tokens"("category_id");
+CREATE INDEX IF NOT EXISTS "collections_creator_id_idx" ON "collections"("creator_id");
+CREATE INDEX IF NOT EXISTS "ownerships_user_id_idx" ON "ownerships"("user_id");
+CREATE INDEX IF NOT EXISTS "rentals_user_id_idx" ON "rentals"("user_id");
+CREATE INDEX IF NOT EXISTS "rentals_owner_id_idx" ON "rentals"("owner_id");
+CREATE INDEX IF NOT EXISTS "rentals_token_id_idx" ON "rentals"("token_id");
+CREATE INDEX IF NOT EXISTS "rentals_ownership_id_idx" ON "rentals"("ownership_id");
+CREATE INDEX IF NOT EXISTS "transactions_user_from_id_idx" ON "transactions"("user_from_id");
+CREATE INDEX IF NOT EXISTS "transactions_user_to_id_idx" ON "transactions"("user_to_id");
+CREATE INDEX IF NOT EXISTS "transactions_ownership_id_idx" ON "transactions"("ownership_id");
+CREATE INDEX IF NOT EXISTS "transactions_collection_id_idx" ON "transactions"("collection_id");
+CREATE INDEX IF NOT EXISTS "fractions_token_parent_id_idx" ON "fractions"("token_parent_id");
+CREATE INDEX IF NOT EXISTS "fractions_token_fraction_id_idx" ON "fractions"("token_fraction_id");
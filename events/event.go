@@ -0,0 +1,57 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+const Channel = "marketplace-events"
+
+const GlobalTopic = "global"
+
+type Event struct {
+	Type      string      `json:"type"`
+	Topics    []string    `json:"topics"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func UserTopic(id uuid.UUID) string {
+	return fmt.Sprintf("user:%s", id.String())
+}
+
+func TokenTopic(id uuid.UUID) string {
+	return fmt.Sprintf("token:%s", id.String())
+}
+
+func CollectionTopic(id uuid.UUID) string {
+	return fmt.Sprintf("collection:%s", id.String())
+}
+
+// Publish sends the event to every API instance through Redis pub/sub, only public events pass GlobalTopic
+func Publish(redisClient *redis.Client, eventType string, data interface{}, topics ...string) error {
+	event := Event{
+		Type:      eventType,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	// Skip topics of unset ids
+	for _, topic := range topics {
+		if !strings.HasSuffix(topic, uuid.Nil.String()) {
+			event.Topics = append(event.Topics, topic)
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return redisClient.Publish(Channel, payload).Err()
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+type Subscriber struct {
+	topics map[string]bool
+	Events chan Event
+}
+
+type Hub struct {
+	redisClient *redis.Client
+	mutex       sync.RWMutex
+	subscribers map[*Subscriber]bool
+	pubsub      *redis.PubSub
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func NewHub(redisClient *redis.Client) *Hub {
+	return &Hub{redisClient: redisClient, subscribers: make(map[*Subscriber]bool), done: make(chan struct{})}
+}
+
+// Run fans out events from Redis to the local subscribers until Close
+func (h *Hub) Run() {
+	h.mutex.Lock()
+
+	select {
+	case <-h.done:
+		h.mutex.Unlock()
+		return
+	default:
+	}
+
+	pubsub := h.redisClient.Subscribe(Channel)
+	h.pubsub = pubsub
+	h.mutex.Unlock()
+
+	defer pubsub.Close()
+
+	for message := range pubsub.Channel() {
+		var event Event
+
+		err := json.Unmarshal([]byte(message.Payload), &event)
+		if err != nil {
+			fmt.Println("Decoding failed, event: ", message.Payload, ", error: ", err)
+			continue
+		}
+
+		h.broadcast(event)
+	}
+}
+
+// Close stops the fan out and ends every open stream, the server waits for streams while shutting down
+func (h *Hub) Close() {
+	h.closeOnce.Do(func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		close(h.done)
+
+		if h.pubsub != nil {
+			h.pubsub.Close()
+		}
+	})
+}
+
+// Done is closed once the hub is closed
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+func (h *Hub) Subscribe(topics []string) *Subscriber {
+	subscriber := &Subscriber{topics: make(map[string]bool), Events: make(chan Event, 64)}
+
+	for _, topic := range topics {
+		subscriber.topics[topic] = true
+	}
+
+	h.mutex.Lock()
+	h.subscribers[subscriber] = true
+	h.mutex.Unlock()
+
+	return subscriber
+}
+
+func (h *Hub) Unsubscribe(subscriber *Subscriber) {
+	h.mutex.Lock()
+	delete(h.subscribers, subscriber)
+	h.mutex.Unlock()
+}
+
+func (h *Hub) broadcast(event Event) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	for subscriber := range h.subscribers {
+		for _, topic := range event.Topics {
+			if !subscriber.topics[topic] {
+				continue
+			}
+
+			// Slow subscribers miss events instead of blocking the hub
+			select {
+			case subscriber.Events <- event:
+			default:
+			}
+			break
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	models "metaedu-marketplace/models"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Route groups whose id parameter is a row of the table, used for the before and after snapshots
+var AuditResourceTables = map[string]string{
+	"collection":     "collections",
+	"fee-schedule":   "fee_schedules",
+	"fraction":       "fractions",
+	"ownership":      "ownerships",
+	"rental":         "rentals",
+	"token":          "tokens",
+	"token-category": "token_categories",
+	"transaction":    "transactions",
+	"user":           "users",
+	"watchlist":      "watchlists",
+	"webhook":        "webhook_endpoints",
+}
+
+// Tables that stage updates as pending changes until their transaction is confirmed
+var AuditPendingTables = map[string]bool{
+	"ownerships": true,
+	"rentals":    true,
+	"tokens":     true,
+}
+
+var auditRedactedFields = []string{"nonce", "secret", "password"}
+
+const AuditTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// AuditWriteTimeout bounds writing an entry once the request or job it records is already over
+const AuditWriteTimeout = 5 * time.Second
+
+// AuditMethodWorker marks entries for changes the worker made, which have no request
+const AuditMethodWorker = "WORKER"
+
+// GetAuditResourceType returns the route group of a table, or the table when it has none
+func GetAuditResourceType(table string) string {
+	for resourceType, resourceTable := range AuditResourceTables {
+		if resourceTable == table {
+			return resourceType
+		}
+	}
+
+	return table
+}
+
+func IsAuditedMethod(method string) bool {
+	return method == "POST" || method == "PUT" || method == "PATCH" || method == "DELETE"
+}
+
+// GetAuditResource returns the route group of a path such as /api/v1/token/:id
+func GetAuditResource(fullPath string) string {
+	segments := strings.Split(strings.TrimPrefix(fullPath, "/api/v1/"), "/")
+
+	return segments[0]
+}
+
+func decodeAuditSnapshot(snapshot []byte) map[string]interface{} {
+	var fields map[string]interface{}
+
+	if len(snapshot) == 0 || json.Unmarshal(snapshot, &fields) != nil {
+		return nil
+	}
+
+	for _, field := range auditRedactedFields {
+		if _, isExist := fields[field]; isExist {
+			fields[field] = "[redacted]"
+		}
+	}
+
+	return fields
+}
+
+func encodeAuditSnapshot(fields map[string]interface{}) json.RawMessage {
+	if fields == nil {
+		return json.RawMessage("null")
+	}
+
+	snapshot, err := json.Marshal(fields)
+
+	if err != nil {
+		return json.RawMessage("null")
+	}
+
+	return snapshot
+}
+
+// DiffAuditSnapshots keeps only the fields that changed, a created or deleted row is kept whole
+func DiffAuditSnapshots(before []byte, after []byte) (json.RawMessage, json.RawMessage) {
+	beforeFields := decodeAuditSnapshot(before)
+	afterFields := decodeAuditSnapshot(after)
+
+	if beforeFields == nil || afterFields == nil {
+		return encodeAuditSnapshot(beforeFields), encodeAuditSnapshot(afterFields)
+	}
+
+	beforeDiff := make(map[string]interface{})
+	afterDiff := make(map[string]interface{})
+
+	for key, value := range afterFields {
+		if !reflect.DeepEqual(beforeFields[key], value) {
+			beforeDiff[key] = beforeFields[key]
+			afterDiff[key] = value
+		}
+	}
+
+	return encodeAuditSnapshot(beforeDiff), encodeAuditSnapshot(afterDiff)
+}
+
+// ComputeAuditHash chains an entry to the previous one, changing any stored field breaks every later hash
+func ComputeAuditHash(auditLog models.AuditLog) string {
+	fields := []string{
+		auditLog.PreviousHash,
+		auditLog.ActorID.String(),
+		auditLog.Action,
+		auditLog.Method,
+		auditLog.Path,
+		auditLog.ResourceType,
+		auditLog.ResourceID,
+		string(auditLog.Before),
+		string(auditLog.After),
+		auditLog.RequestID,
+		auditLog.IP,
+		auditLog.UserAgent,
+		strconv.Itoa(auditLog.StatusCode),
+		auditLog.CreatedAt.UTC().Format(AuditTimeLayout),
+	}
+
+	// Encoded as a JSON array so no field can run into the next
+	encodedFields, _ := json.Marshal(fields)
+	hash := sha256.Sum256(encodedFields)
+
+	return hex.EncodeToString(hash[:])
+}
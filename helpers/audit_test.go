@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"encoding/json"
+	models "metaedu-marketplace/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestComputeAuditHash(t *testing.T) {
+	auditLog := models.AuditLog{
+		ActorID:      uuid.MustParse("7d3c1a4e-5f9b-4c8e-9a6d-2b1f0e3c4d5a"),
+		Action:       "update",
+		Method:       "PUT",
+		Path:         "/api/v1/token/:id",
+		ResourceType: "token",
+		ResourceID:   "1",
+		Before:       json.RawMessage(`{"price":1}`),
+		After:        json.RawMessage(`{"price":2}`),
+		RequestID:    "request",
+		IP:           "127.0.0.1",
+		UserAgent:    "test",
+		StatusCode:   200,
+		PreviousHash: "previous",
+		CreatedAt:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	hash := ComputeAuditHash(auditLog)
+
+	if len(hash) != 64 {
+		t.Fatalf("ComputeAuditHash() = %q, want a hex sha256", hash)
+	}
+
+	sameInstant := auditLog
+	sameInstant.CreatedAt = auditLog.CreatedAt.In(time.FixedZone("UTC+7", 7*60*60))
+
+	if ComputeAuditHash(sameInstant) != hash {
+		t.Errorf("ComputeAuditHash() depends on the time zone")
+	}
+
+	tests := []struct {
+		name   string
+		change func(auditLog *models.AuditLog)
+	}{
+		{name: "previous hash", change: func(a *models.AuditLog) { a.PreviousHash = "other" }},
+		{name: "actor", change: func(a *models.AuditLog) { a.ActorID = uuid.New() }},
+		{name: "action", change: func(a *models.AuditLog) { a.Action = "delete" }},
+		{name: "method", change: func(a *models.AuditLog) { a.Method = "DELETE" }},
+		{name: "path", change: func(a *models.AuditLog) { a.Path = "/api/v1/token" }},
+		{name: "resource type", change: func(a *models.AuditLog) { a.ResourceType = "collection" }},
+		{name: "resource id", change: func(a *models.AuditLog) { a.ResourceID = "2" }},
+		{name: "before", change: func(a *models.AuditLog) { a.Before = json.RawMessage(`{"price":3}`) }},
+		{name: "after", change: func(a *models.AuditLog) { a.After = json.RawMessage(`{"price":3}`) }},
+		{name: "request id", change: func(a *models.AuditLog) { a.RequestID = "other" }},
+		{name: "ip", change: func(a *models.AuditLog) { a.IP = "10.0.0.1" }},
+		{name: "user agent", change: func(a *models.AuditLog) { a.UserAgent = "other" }},
+		{name: "status code", change: func(a *models.AuditLog) { a.StatusCode = 500 }},
+		{name: "created at", change: func(a *models.AuditLog) { a.CreatedAt = a.CreatedAt.Add(time.Millisecond) }},
+		{name: "field boundary", change: func(a *models.AuditLog) { a.IP, a.UserAgent = "127.0.0.1test", "" }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changed := auditLog
+			test.change(&changed)
+
+			if ComputeAuditHash(changed) == hash {
+				t.Errorf("ComputeAuditHash() did not change with the %s", test.name)
+			}
+		})
+	}
+}
+
+func TestDiffAuditSnapshots(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     string
+		after      string
+		wantBefore string
+		wantAfter  string
+	}{
+		{name: "only changed fields", before: `{"id":1,"name":"a","price":1}`, after: `{"id":1,"name":"a","price":2}`, wantBefore: `{"price":1}`, wantAfter: `{"price":2}`},
+		{name: "added field", before: `{"id":1}`, after: `{"id":1,"status":"active"}`, wantBefore: `{"status":null}`, wantAfter: `{"status":"active"}`},
+		{name: "nothing changed", before: `{"id":1}`, after: `{"id":1}`, wantBefore: `{}`, wantAfter: `{}`},
+		{name: "created row kept whole", after: `{"id":1,"name":"a"}`, wantBefore: `null`, wantAfter: `{"id":1,"name":"a"}`},
+		{name: "deleted row kept whole", before: `{"id":1,"name":"a"}`, wantBefore: `{"id":1,"name":"a"}`, wantAfter: `null`},
+		{name: "secrets redacted", before: `{"nonce":1,"secret":"a","password":"b"}`, after: `{"nonce":2,"secret":"c","password":"d"}`, wantBefore: `{}`, wantAfter: `{}`},
+		{name: "secrets redacted in a whole row", after: `{"id":1,"secret":"a"}`, wantBefore: `null`, wantAfter: `{"id":1,"secret":"[redacted]"}`},
+		{name: "invalid json", before: `not json`, after: `{"id":1}`, wantBefore: `null`, wantAfter: `{"id":1}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before, after := DiffAuditSnapshots([]byte(test.before), []byte(test.after))
+
+			if string(before) != test.wantBefore || string(after) != test.wantAfter {
+				t.Errorf("DiffAuditSnapshots() = %s, %s, want %s, %s", before, after, test.wantBefore, test.wantAfter)
+			}
+		})
+	}
+}
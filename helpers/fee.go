@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"math"
+	models "metaedu-marketplace/models"
+)
+
+const (
+	FeeTypePercentage = "percentage"
+	FeeTypeFlat       = "flat"
+)
+
+func IsValidFeeTransactionType(value string) bool {
+	return value == "purchase" || value == "rent" || value == "fraction"
+}
+
+func IsValidFeeType(value string) bool {
+	return value == FeeTypePercentage || value == FeeTypeFlat
+}
+
+func getFeeScheduleScope(feeSchedule models.FeeSchedule) int {
+	if IsValidUUID(feeSchedule.CollectionID) == true {
+		return 2
+	}
+
+	if IsValidUUID(feeSchedule.CategoryID) == true {
+		return 1
+	}
+
+	return 0
+}
+
+// Only the most specific scope is applied, a promotion waives it when the promotion is of that scope or a more specific one
+func CalculateFees(baseAmount float64, feeSchedules []models.FeeSchedule) ([]models.TransactionFee, float64) {
+	var fees []models.TransactionFee
+	var promotion *models.FeeSchedule
+
+	scope := -1
+	promotionScope := -1
+
+	for index, feeSchedule := range feeSchedules {
+		if feeSchedule.Promotion {
+			if getFeeScheduleScope(feeSchedule) > promotionScope {
+				promotion = &feeSchedules[index]
+				promotionScope = getFeeScheduleScope(feeSchedule)
+			}
+
+			continue
+		}
+
+		if getFeeScheduleScope(feeSchedule) > scope {
+			scope = getFeeScheduleScope(feeSchedule)
+		}
+	}
+
+	if promotion != nil && promotionScope >= scope {
+		fees = append(fees, models.TransactionFee{
+			FeeScheduleID:   promotion.ID,
+			TransactionType: promotion.TransactionType,
+			FeeType:         promotion.FeeType,
+			Value:           0,
+			BaseAmount:      baseAmount,
+			Amount:          0,
+			RecipientID:     promotion.RecipientID,
+		})
+
+		return fees, 0
+	}
+
+	var total float64
+
+	for _, feeSchedule := range feeSchedules {
+		if feeSchedule.Promotion || getFeeScheduleScope(feeSchedule) != scope {
+			continue
+		}
+
+		var amount float64
+
+		if feeSchedule.FeeType == FeeTypePercentage {
+			amount = baseAmount * feeSchedule.Value / 100
+		} else {
+			amount = feeSchedule.Value
+		}
+
+		amount = math.Round(amount*1e8) / 1e8
+		total = total + amount
+
+		fees = append(fees, models.TransactionFee{
+			FeeScheduleID:   feeSchedule.ID,
+			TransactionType: feeSchedule.TransactionType,
+			FeeType:         feeSchedule.FeeType,
+			Value:           feeSchedule.Value,
+			BaseAmount:      baseAmount,
+			Amount:          amount,
+			RecipientID:     feeSchedule.RecipientID,
+		})
+	}
+
+	return fees, total
+}
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	models "metaedu-marketplace/models"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCalculateFees(t *testing.T) {
+	categoryID := uuid.New()
+	collectionID := uuid.New()
+
+	platform := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 2.5}
+	flat := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypeFlat, Value: 0.01}
+	category := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 5, CategoryID: categoryID}
+	collection := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 1, CollectionID: collectionID}
+	promotion := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 10, Promotion: true}
+	categoryPromotion := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 10, Promotion: true, CategoryID: categoryID}
+	collectionPromotion := models.FeeSchedule{ID: uuid.New(), FeeType: FeeTypePercentage, Value: 10, Promotion: true, CollectionID: collectionID}
+
+	tests := []struct {
+		name         string
+		baseAmount   float64
+		feeSchedules []models.FeeSchedule
+		wantFees     []uuid.UUID
+		wantTotal    float64
+	}{
+		{name: "no schedules", baseAmount: 10, wantTotal: 0},
+		{name: "percentage", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform}, wantFees: []uuid.UUID{platform.ID}, wantTotal: 0.25},
+		{name: "percentage and flat of the same scope add up", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform, flat}, wantFees: []uuid.UUID{platform.ID, flat.ID}, wantTotal: 0.26},
+		{name: "category wins over platform", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform, category}, wantFees: []uuid.UUID{category.ID}, wantTotal: 0.5},
+		{name: "collection wins over category", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform, category, collection}, wantFees: []uuid.UUID{collection.ID}, wantTotal: 0.1},
+		{name: "promotion waives fees of its scope", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform, flat, promotion}, wantFees: []uuid.UUID{promotion.ID}, wantTotal: 0},
+		{name: "promotion waives broader fees", baseAmount: 10, feeSchedules: []models.FeeSchedule{platform, category, collectionPromotion}, wantFees: []uuid.UUID{collectionPromotion.ID}, wantTotal: 0},
+		{name: "broader promotion keeps a more specific fee", baseAmount: 10, feeSchedules: []models.FeeSchedule{promotion, collection}, wantFees: []uuid.UUID{collection.ID}, wantTotal: 0.1},
+		{name: "most specific promotion is recorded", baseAmount: 10, feeSchedules: []models.FeeSchedule{promotion, categoryPromotion, category}, wantFees: []uuid.UUID{categoryPromotion.ID}, wantTotal: 0},
+		{name: "promotion alone", baseAmount: 10, feeSchedules: []models.FeeSchedule{promotion}, wantFees: []uuid.UUID{promotion.ID}, wantTotal: 0},
+		{name: "rounded to 8 decimals", baseAmount: 0.123456789, feeSchedules: []models.FeeSchedule{platform}, wantFees: []uuid.UUID{platform.ID}, wantTotal: 0.00308642},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fees, total := CalculateFees(test.baseAmount, test.feeSchedules)
+
+			if total != test.wantTotal {
+				t.Errorf("CalculateFees() total = %v, want %v", total, test.wantTotal)
+			}
+
+			if len(fees) != len(test.wantFees) {
+				t.Fatalf("CalculateFees() returned %d fees, want %d", len(fees), len(test.wantFees))
+			}
+
+			var sum float64
+
+			for i, fee := range fees {
+				if fee.FeeScheduleID != test.wantFees[i] {
+					t.Errorf("fee %d schedule = %v, want %v", i, fee.FeeScheduleID, test.wantFees[i])
+				}
+
+				if fee.BaseAmount != test.baseAmount {
+					t.Errorf("fee %d base amount = %v, want %v", i, fee.BaseAmount, test.baseAmount)
+				}
+
+				sum += fee.Amount
+			}
+
+			if roundAmount(sum) != test.wantTotal {
+				t.Errorf("fee amounts add up to %v, want %v", sum, test.wantTotal)
+			}
+		})
+	}
+}
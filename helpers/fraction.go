@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"database/sql"
+	"math"
+	models "metaedu-marketplace/models"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FractionBuyoutTypeRedemption = "redemption"
+	FractionBuyoutTypeBuyout     = "buyout"
+)
+
+const (
+	FractionPayoutTypePayout = "payout"
+	FractionPayoutTypeRefund = "refund"
+)
+
+func GetFractionShareBalance(balances []models.FractionShareBalance, userID uuid.UUID) int {
+	for _, balance := range balances {
+		if balance.UserID == userID {
+			return balance.Balance
+		}
+	}
+
+	return 0
+}
+
+// Burn every share and pay the remaining holders pro rata
+func CalculateFractionSettlement(balances []models.FractionShareBalance, buyout models.FractionBuyout) ([]models.FractionPayout, []models.FractionShare) {
+	var payouts []models.FractionPayout
+	var shares []models.FractionShare
+
+	for _, balance := range balances {
+		if balance.Balance <= 0 {
+			continue
+		}
+
+		shares = append(shares, models.FractionShare{
+			FractionID:      buyout.FractionID,
+			UserID:          balance.UserID,
+			Quantity:        -balance.Balance,
+			Reason:          buyout.Type,
+			ReferenceID:     buyout.ID,
+			TransactionHash: buyout.TransactionHash,
+		})
+
+		if balance.UserID == buyout.UserID {
+			continue
+		}
+
+		payouts = append(payouts, models.FractionPayout{
+			BuyoutID:   buyout.ID,
+			FractionID: buyout.FractionID,
+			UserID:     balance.UserID,
+			Type:       FractionPayoutTypePayout,
+			Shares:     balance.Balance,
+			Amount:     roundAmount(buyout.PricePerShare * float64(balance.Balance)),
+			Status:     "pending",
+			CreatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+			UpdatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+		})
+	}
+
+	return payouts, shares
+}
+
+// SelectFractionBuyout picks the highest confirmed offer whose escrowed amount covers the payouts to the holders at settlement
+func SelectFractionBuyout(buyouts []models.FractionBuyout, balances []models.FractionShareBalance) (models.FractionBuyout, []models.FractionPayout, []models.FractionShare, bool) {
+	sorted := append([]models.FractionBuyout(nil), buyouts...)
+
+	// Equal prices go to the earlier offer
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].PricePerShare != sorted[j].PricePerShare {
+			return sorted[i].PricePerShare > sorted[j].PricePerShare
+		}
+
+		return sorted[i].CreatedAt.Time.Before(sorted[j].CreatedAt.Time)
+	})
+
+	for _, buyout := range sorted {
+		payouts, shares := CalculateFractionSettlement(balances, buyout)
+
+		// Holders may have moved shares since the offer was made, so the escrow is checked against the balances now
+		if buyout.Amount < GetFractionPayoutTotal(payouts) {
+			continue
+		}
+
+		return buyout, payouts, shares, true
+	}
+
+	return models.FractionBuyout{}, nil, nil, false
+}
+
+func GetFractionPayoutTotal(payouts []models.FractionPayout) float64 {
+	var total float64
+
+	for _, payout := range payouts {
+		total += payout.Amount
+	}
+
+	return roundAmount(total)
+}
+
+// CalculateFractionRefunds returns the escrow of every losing offer and what the winner escrowed above the payouts
+func CalculateFractionRefunds(buyouts []models.FractionBuyout, winner models.FractionBuyout, payouts []models.FractionPayout) []models.FractionPayout {
+	var refunds []models.FractionPayout
+
+	for _, buyout := range buyouts {
+		amount := buyout.Amount
+
+		if buyout.ID == winner.ID {
+			amount = roundAmount(buyout.Amount - GetFractionPayoutTotal(payouts))
+		}
+
+		if amount <= 0 {
+			continue
+		}
+
+		refunds = append(refunds, models.FractionPayout{
+			BuyoutID:   buyout.ID,
+			FractionID: buyout.FractionID,
+			UserID:     buyout.UserID,
+			Type:       FractionPayoutTypeRefund,
+			Amount:     amount,
+			Status:     "pending",
+			CreatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+			UpdatedAt:  sql.NullTime{Time: time.Now(), Valid: true},
+		})
+	}
+
+	return refunds
+}
+
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*1e8) / 1e8
+}
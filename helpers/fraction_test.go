@@ -0,0 +1,176 @@
+package helpers
+
+import (
+	"database/sql"
+	models "metaedu-marketplace/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCalculateFractionSettlement(t *testing.T) {
+	buyer := uuid.New()
+	holder := uuid.New()
+	otherHolder := uuid.New()
+
+	buyout := models.FractionBuyout{ID: uuid.New(), FractionID: uuid.New(), UserID: buyer, Type: FractionBuyoutTypeBuyout, PricePerShare: 0.5}
+
+	tests := []struct {
+		name        string
+		balances    []models.FractionShareBalance
+		wantPayouts map[uuid.UUID]float64
+		wantBurned  map[uuid.UUID]int
+	}{
+		{
+			name:        "pays every holder pro rata",
+			balances:    []models.FractionShareBalance{{UserID: holder, Balance: 30}, {UserID: otherHolder, Balance: 70}},
+			wantPayouts: map[uuid.UUID]float64{holder: 15, otherHolder: 35},
+			wantBurned:  map[uuid.UUID]int{holder: 30, otherHolder: 70},
+		},
+		{
+			name:        "burns the buyer's shares without paying them",
+			balances:    []models.FractionShareBalance{{UserID: buyer, Balance: 60}, {UserID: holder, Balance: 40}},
+			wantPayouts: map[uuid.UUID]float64{holder: 20},
+			wantBurned:  map[uuid.UUID]int{buyer: 60, holder: 40},
+		},
+		{
+			name:        "skips empty balances",
+			balances:    []models.FractionShareBalance{{UserID: holder, Balance: 0}, {UserID: otherHolder, Balance: 3}},
+			wantPayouts: map[uuid.UUID]float64{otherHolder: 1.5},
+			wantBurned:  map[uuid.UUID]int{otherHolder: 3},
+		},
+		{
+			name:        "redemption by the only holder pays nobody",
+			balances:    []models.FractionShareBalance{{UserID: buyer, Balance: 100}},
+			wantPayouts: map[uuid.UUID]float64{},
+			wantBurned:  map[uuid.UUID]int{buyer: 100},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payouts, shares := CalculateFractionSettlement(test.balances, buyout)
+
+			if len(payouts) != len(test.wantPayouts) {
+				t.Fatalf("CalculateFractionSettlement() returned %d payouts, want %d", len(payouts), len(test.wantPayouts))
+			}
+
+			for _, payout := range payouts {
+				if payout.Amount != test.wantPayouts[payout.UserID] {
+					t.Errorf("payout to %v = %v, want %v", payout.UserID, payout.Amount, test.wantPayouts[payout.UserID])
+				}
+
+				if payout.Type != FractionPayoutTypePayout || payout.BuyoutID != buyout.ID {
+					t.Errorf("payout = %+v, want a payout of the buyout", payout)
+				}
+			}
+
+			if len(shares) != len(test.wantBurned) {
+				t.Fatalf("CalculateFractionSettlement() burned %d balances, want %d", len(shares), len(test.wantBurned))
+			}
+
+			for _, share := range shares {
+				if share.Quantity != -test.wantBurned[share.UserID] {
+					t.Errorf("burned from %v = %d, want %d", share.UserID, -share.Quantity, test.wantBurned[share.UserID])
+				}
+			}
+		})
+	}
+}
+
+func TestSelectFractionBuyout(t *testing.T) {
+	holder := uuid.New()
+	balances := []models.FractionShareBalance{{UserID: holder, Balance: 10}}
+
+	now := time.Now()
+
+	newBuyout := func(pricePerShare float64, amount float64, createdAt time.Time) models.FractionBuyout {
+		return models.FractionBuyout{
+			ID:            uuid.New(),
+			UserID:        uuid.New(),
+			Type:          FractionBuyoutTypeBuyout,
+			PricePerShare: pricePerShare,
+			Amount:        amount,
+			CreatedAt:     sql.NullTime{Time: createdAt, Valid: true},
+		}
+	}
+
+	low := newBuyout(1, 10, now)
+	high := newBuyout(2, 20, now)
+	uncovered := newBuyout(3, 29, now)
+	earlier := newBuyout(2, 20, now.Add(-time.Minute))
+
+	tests := []struct {
+		name     string
+		buyouts  []models.FractionBuyout
+		want     uuid.UUID
+		wantPaid float64
+		wantOk   bool
+	}{
+		{name: "no offers", wantOk: false},
+		{name: "highest price wins", buyouts: []models.FractionBuyout{low, high}, want: high.ID, wantPaid: 20, wantOk: true},
+		{name: "uncovered offer is skipped", buyouts: []models.FractionBuyout{low, uncovered, high}, want: high.ID, wantPaid: 20, wantOk: true},
+		{name: "earlier offer wins a tie", buyouts: []models.FractionBuyout{high, earlier}, want: earlier.ID, wantPaid: 20, wantOk: true},
+		{name: "nothing covered", buyouts: []models.FractionBuyout{uncovered}, wantOk: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			winner, payouts, _, ok := SelectFractionBuyout(test.buyouts, balances)
+
+			if ok != test.wantOk {
+				t.Fatalf("SelectFractionBuyout() ok = %v, want %v", ok, test.wantOk)
+			}
+
+			if !ok {
+				return
+			}
+
+			if winner.ID != test.want {
+				t.Errorf("SelectFractionBuyout() winner = %v, want %v", winner.ID, test.want)
+			}
+
+			if total := GetFractionPayoutTotal(payouts); total != test.wantPaid {
+				t.Errorf("SelectFractionBuyout() pays %v, want %v", total, test.wantPaid)
+			}
+		})
+	}
+}
+
+func TestCalculateFractionRefunds(t *testing.T) {
+	winner := models.FractionBuyout{ID: uuid.New(), UserID: uuid.New(), Amount: 25}
+	loser := models.FractionBuyout{ID: uuid.New(), UserID: uuid.New(), Amount: 12.5}
+	payouts := []models.FractionPayout{{Amount: 15}, {Amount: 5}}
+
+	tests := []struct {
+		name    string
+		buyouts []models.FractionBuyout
+		payouts []models.FractionPayout
+		want    map[uuid.UUID]float64
+	}{
+		{name: "loser gets everything back and winner the excess", buyouts: []models.FractionBuyout{winner, loser}, payouts: payouts, want: map[uuid.UUID]float64{winner.ID: 5, loser.ID: 12.5}},
+		{name: "winner spent exactly the escrow", buyouts: []models.FractionBuyout{winner}, payouts: []models.FractionPayout{{Amount: 25}}, want: map[uuid.UUID]float64{}},
+		{name: "no payouts refunds the winner in full", buyouts: []models.FractionBuyout{winner}, want: map[uuid.UUID]float64{winner.ID: 25}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			refunds := CalculateFractionRefunds(test.buyouts, winner, test.payouts)
+
+			if len(refunds) != len(test.want) {
+				t.Fatalf("CalculateFractionRefunds() returned %d refunds, want %d", len(refunds), len(test.want))
+			}
+
+			for _, refund := range refunds {
+				if refund.Amount != test.want[refund.BuyoutID] {
+					t.Errorf("refund of %v = %v, want %v", refund.BuyoutID, refund.Amount, test.want[refund.BuyoutID])
+				}
+
+				if refund.Type != FractionPayoutTypeRefund {
+					t.Errorf("refund type = %q, want %q", refund.Type, FractionPayoutTypeRefund)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package helpers
+
+import models "metaedu-marketplace/models"
+
+const (
+	ModerationSubjectToken      = "token"
+	ModerationSubjectCollection = "collection"
+	ModerationSubjectUser       = "user"
+)
+
+const (
+	ModerationStatusVisible = "visible"
+	ModerationStatusFlagged = "flagged"
+	ModerationStatusHidden  = "hidden"
+	ModerationStatusBanned  = "banned"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+const (
+	ModerationActionFlag          = "flag"
+	ModerationActionHide          = "hide"
+	ModerationActionBan           = "ban"
+	ModerationActionRestore       = "restore"
+	ModerationActionDelete        = "delete"
+	ModerationActionResolveReport = "resolve_report"
+	ModerationActionDismissReport = "dismiss_report"
+)
+
+// Open reports needed before a subject is flagged automatically
+const ReportFlagThreshold = 3
+
+var ReportReasons = []string{
+	"plagiarism",
+	"copyright",
+	"inappropriate",
+	"scam",
+	"harassment",
+	"spam",
+	"other",
+}
+
+func IsValidModerationSubject(subjectType string) bool {
+	return subjectType == ModerationSubjectToken || subjectType == ModerationSubjectCollection || subjectType == ModerationSubjectUser
+}
+
+// Tokens and collections are hidden, users are banned
+func IsValidModerationStatus(subjectType string, status string) bool {
+	switch status {
+	case ModerationStatusVisible, ModerationStatusFlagged:
+		return true
+	case ModerationStatusHidden:
+		return subjectType != ModerationSubjectUser
+	case ModerationStatusBanned:
+		return subjectType == ModerationSubjectUser
+	}
+
+	return false
+}
+
+func IsValidReportReason(reason string) bool {
+	for _, validReason := range ReportReasons {
+		if validReason == reason {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GetModerationAction(status string) string {
+	switch status {
+	case ModerationStatusFlagged:
+		return ModerationActionFlag
+	case ModerationStatusHidden:
+		return ModerationActionHide
+	case ModerationStatusBanned:
+		return ModerationActionBan
+	}
+
+	return ModerationActionRestore
+}
+
+// Hidden tokens and collections and the content of banned users are only shown to admins
+func IsTokenHidden(token models.Token) bool {
+	return token.ModerationStatus == ModerationStatusHidden || token.Creator.ModerationStatus == ModerationStatusBanned || token.Collection.ModerationStatus == ModerationStatusHidden
+}
+
+func IsCollectionHidden(collection models.Collection) bool {
+	return collection.ModerationStatus == ModerationStatusHidden || collection.Creator.ModerationStatus == ModerationStatusBanned
+}
@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	NotificationTypeItemSold          = "item_sold"
+	NotificationTypeItemPurchased     = "item_purchased"
+	NotificationTypeRentalStarted     = "rental_started"
+	NotificationTypeRentalExpired     = "rental_expired"
+	NotificationTypeRentalExtended    = "rental_extended"
+	NotificationTypeRentalReturned    = "rental_returned"
+	NotificationTypeRentalCancelled   = "rental_cancelled"
+	NotificationTypeMintFailed        = "mint_failed"
+	NotificationTypeTransactionFailed = "transaction_failed"
+	NotificationTypeWatchlistListed   = "watchlist_listed"
+	NotificationTypePriceAlert        = "price_alert"
+	NotificationTypeFloorChanged      = "floor_changed"
+)
+
+var NotificationTypes = []string{
+	NotificationTypeItemSold,
+	NotificationTypeItemPurchased,
+	NotificationTypeRentalStarted,
+	NotificationTypeRentalExpired,
+	NotificationTypeRentalExtended,
+	NotificationTypeRentalReturned,
+	NotificationTypeRentalCancelled,
+	NotificationTypeMintFailed,
+	NotificationTypeTransactionFailed,
+	NotificationTypeWatchlistListed,
+	NotificationTypePriceAlert,
+	NotificationTypeFloorChanged,
+}
+
+const (
+	OutboxEmailStatusPending = "pending"
+	OutboxEmailStatusSent    = "sent"
+	OutboxEmailStatusFailed  = "failed"
+	OutboxEmailStatusDead    = "dead"
+)
+
+// MaxEmailAttempts is the number of attempts before an email is given up
+const MaxEmailAttempts = 6
+
+// GetEmailRetryDelay doubles the delay after every attempt, starting at a minute and capped at an hour
+func GetEmailRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempts-1))) * time.Minute
+
+	if delay > time.Hour || delay <= 0 {
+		return time.Hour
+	}
+
+	return delay
+}
+
+func IsValidNotificationType(notificationType string) bool {
+	for _, validType := range NotificationTypes {
+		if validType == notificationType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -33,3 +33,11 @@ func GetOptionalIntParams(value *int) any {
 type Optional struct {
 	Photo string `json:"photo"`
 }
+
+func GetNullableUUIDParams(value uuid.UUID) any {
+	if value == GetEmptyUUID() {
+		return nil
+	}
+
+	return value
+}
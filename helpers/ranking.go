@@ -0,0 +1,39 @@
+package helpers
+
+import "time"
+
+const (
+	RankingTypeCollections = "collections"
+	RankingTypeCreators    = "creators"
+	RankingTypeUsers       = "users"
+)
+
+const RankingSize = 100
+
+var RankingTypes = []string{
+	RankingTypeCollections,
+	RankingTypeCreators,
+	RankingTypeUsers,
+}
+
+var RankingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+func IsValidRankingType(rankingType string) bool {
+	for _, validType := range RankingTypes {
+		if validType == rankingType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsValidRankingWindow(window string) bool {
+	_, isExist := RankingWindows[window]
+
+	return isExist
+}
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"math"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RentalStatusPending   = "pending"
+	RentalStatusActive    = "active"
+	RentalStatusExpired   = "expired"
+	RentalStatusReturned  = "returned"
+	RentalStatusExtended  = "extended"
+	RentalStatusCancelled = "cancelled"
+)
+
+var rentalTransitions = map[string][]string{
+	RentalStatusPending:  {RentalStatusActive, RentalStatusCancelled},
+	RentalStatusActive:   {RentalStatusExtended, RentalStatusExpired, RentalStatusReturned},
+	RentalStatusExtended: {RentalStatusExtended, RentalStatusExpired, RentalStatusReturned},
+}
+
+func IsValidRentalTransition(from string, to string) bool {
+	for _, status := range rentalTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+func IsRentalInProgress(status string) bool {
+	return status == RentalStatusActive || status == RentalStatusExtended
+}
+
+func GetRentalEndTime(from time.Time, days int) time.Time {
+	return from.Add(time.Hour * 24 * time.Duration(days))
+}
+
+// Refund the unused part of the rental period
+func CalculateRentalRefund(amount float64, startedAt time.Time, endsAt time.Time, at time.Time) float64 {
+	total := endsAt.Sub(startedAt)
+	remaining := endsAt.Sub(at)
+
+	if total <= 0 || remaining <= 0 {
+		return 0
+	}
+
+	if remaining > total {
+		remaining = total
+	}
+
+	return math.Round(amount*(float64(remaining)/float64(total))*1e8) / 1e8
+}
+
+func NewRentalEvent(rental models.Rental, fromStatus string, toStatus string, actorID uuid.UUID, note string) models.RentalEvent {
+	var rentalEvent models.RentalEvent
+
+	rentalEvent.RentalID = rental.ID
+	rentalEvent.UserID = rental.UserID
+	rentalEvent.OwnerID = rental.OwnerID
+	rentalEvent.TokenID = rental.TokenID
+	rentalEvent.ActorID = actorID
+	rentalEvent.FromStatus = fromStatus
+	rentalEvent.ToStatus = toStatus
+	rentalEvent.Note = note
+
+	return rentalEvent
+}
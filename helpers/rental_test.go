@@ -0,0 +1,61 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateRentalRefund(t *testing.T) {
+	startedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := GetRentalEndTime(startedAt, 10)
+
+	tests := []struct {
+		name     string
+		amount   float64
+		startsAt time.Time
+		endsAt   time.Time
+		at       time.Time
+		want     float64
+	}{
+		{name: "returned right away", amount: 10, startsAt: startedAt, endsAt: endsAt, at: startedAt, want: 10},
+		{name: "returned before it started", amount: 10, startsAt: startedAt, endsAt: endsAt, at: startedAt.Add(-time.Hour), want: 10},
+		{name: "returned halfway", amount: 10, startsAt: startedAt, endsAt: endsAt, at: startedAt.Add(5 * 24 * time.Hour), want: 5},
+		{name: "returned with a day left", amount: 3, startsAt: startedAt, endsAt: endsAt, at: endsAt.Add(-24 * time.Hour), want: 0.3},
+		{name: "rounded to 8 decimals", amount: 1, startsAt: startedAt, endsAt: startedAt.Add(3 * time.Hour), at: startedAt.Add(time.Hour), want: 0.66666667},
+		{name: "returned at the end", amount: 10, startsAt: startedAt, endsAt: endsAt, at: endsAt, want: 0},
+		{name: "returned after the end", amount: 10, startsAt: startedAt, endsAt: endsAt, at: endsAt.Add(time.Hour), want: 0},
+		{name: "empty period", amount: 10, startsAt: endsAt, endsAt: endsAt, at: startedAt, want: 0},
+		{name: "nothing paid", amount: 0, startsAt: startedAt, endsAt: endsAt, at: startedAt, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CalculateRentalRefund(test.amount, test.startsAt, test.endsAt, test.at); got != test.want {
+				t.Errorf("CalculateRentalRefund() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsValidRentalTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{from: RentalStatusPending, to: RentalStatusActive, want: true},
+		{from: RentalStatusPending, to: RentalStatusCancelled, want: true},
+		{from: RentalStatusPending, to: RentalStatusReturned, want: false},
+		{from: RentalStatusActive, to: RentalStatusReturned, want: true},
+		{from: RentalStatusExtended, to: RentalStatusExtended, want: true},
+		{from: RentalStatusActive, to: RentalStatusCancelled, want: false},
+		{from: RentalStatusReturned, to: RentalStatusActive, want: false},
+		{from: RentalStatusExpired, to: RentalStatusExtended, want: false},
+	}
+
+	for _, test := range tests {
+		if got := IsValidRentalTransition(test.from, test.to); got != test.want {
+			t.Errorf("IsValidRentalTransition(%s, %s) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package helpers
+
+// Route types of the soft deleted tables, used by the trash endpoints and the retention purge
+var SoftDeleteTables = map[string]string{
+	"collection":     "collections",
+	"fraction":       "fractions",
+	"ownership":      "ownerships",
+	"rental":         "rentals",
+	"token":          "tokens",
+	"token-category": "token_categories",
+	"transaction":    "transactions",
+}
+
+// Dependent rows are purged before the rows they point at
+var SoftDeletePurgeOrder = []string{
+	"rentals",
+	"fractions",
+	"transactions",
+	"ownerships",
+	"tokens",
+	"collections",
+	"token_categories",
+}
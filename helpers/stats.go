@@ -0,0 +1,25 @@
+package helpers
+
+import "time"
+
+const (
+	PricePointTypeSale = "sale"
+	PricePointTypeRent = "rent"
+)
+
+// Rollup intervals, buckets are aligned on UTC boundaries and weeks start on Monday
+var StatIntervals = map[string]time.Duration{
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+	"1w": 7 * 24 * time.Hour,
+}
+
+func IsValidStatInterval(interval string) bool {
+	_, isExist := StatIntervals[interval]
+
+	return isExist
+}
+
+func GetStatBucket(interval string, timestamp time.Time) time.Time {
+	return timestamp.UTC().Truncate(StatIntervals[interval])
+}
@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"context"
+	"time"
+)
+
+// Routes that need another deadline than the default request timeout, keyed by method and full path.
+// Uploads wait on web3.storage, 0 leaves the long lived event streams without a deadline.
+var RouteTimeouts = map[string]time.Duration{
+	"POST /api/v1/collection/":     2 * time.Minute,
+	"POST /api/v1/token/":          2 * time.Minute,
+	"POST /api/v1/token-category/": 2 * time.Minute,
+	"PUT /api/v1/user/:id":         2 * time.Minute,
+	"GET /api/v1/event/stream":     0,
+	"GET /api/v1/event/ws":         0,
+}
+
+func GetRouteTimeout(method string, fullPath string, defaultTimeout time.Duration) time.Duration {
+	if timeout, isExist := RouteTimeouts[method+" "+fullPath]; isExist {
+		return timeout
+	}
+
+	return defaultTimeout
+}
+
+// detachedContext keeps the values of its parent but none of its deadline or cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (c detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
+
+// WithoutCancel is context.WithoutCancel until the module moves past Go 1.18, for writes that must outlive the request or job
+func WithoutCancel(parent context.Context) context.Context {
+	return detachedContext{parent}
+}
@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type contextKey string
+
+func TestWithoutCancel(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.WithValue(context.Background(), contextKey("request_id"), "abc"), time.Millisecond)
+	cancel()
+
+	ctx := WithoutCancel(parent)
+
+	if ctx.Err() != nil {
+		t.Errorf("Err() = %v, want nil after the parent is cancelled", ctx.Err())
+	}
+
+	if _, hasDeadline := ctx.Deadline(); hasDeadline {
+		t.Error("Deadline() reports the deadline of the parent")
+	}
+
+	if ctx.Value(contextKey("request_id")) != "abc" {
+		t.Errorf("Value() = %v, want the value of the parent", ctx.Value(contextKey("request_id")))
+	}
+
+	// A timeout on top still applies
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, time.Millisecond)
+	defer timeoutCancel()
+
+	select {
+	case <-timeoutCtx.Done():
+	case <-time.After(time.Second):
+		t.Error("the timeout derived from the detached context never fired")
+	}
+}
+
+func TestGetRouteTimeout(t *testing.T) {
+	tests := []struct {
+		method   string
+		fullPath string
+		want     time.Duration
+	}{
+		{method: "POST", fullPath: "/api/v1/token/", want: 2 * time.Minute},
+		{method: "GET", fullPath: "/api/v1/event/stream", want: 0},
+		{method: "GET", fullPath: "/api/v1/token/", want: 15 * time.Second},
+	}
+
+	for _, test := range tests {
+		if got := GetRouteTimeout(test.method, test.fullPath, 15*time.Second); got != test.want {
+			t.Errorf("GetRouteTimeout(%s, %s) = %v, want %v", test.method, test.fullPath, got, test.want)
+		}
+	}
+}
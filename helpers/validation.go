@@ -1,6 +1,10 @@
 package helpers
 
-import "github.com/google/uuid"
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
 
 func IsValidUUID(value uuid.UUID) any {
 	var emptyUUID uuid.UUID
@@ -11,3 +15,18 @@ func IsValidUUID(value uuid.UUID) any {
 		return false
 	}
 }
+
+// IsAllowedOrigin accepts the listed origins and requests without one, which only come from non-browser clients
+func IsAllowedOrigin(origin string, allowedOrigins []string) bool {
+	if origin == "" {
+		return true
+	}
+
+	for _, allowedOrigin := range allowedOrigins {
+		if strings.EqualFold(strings.TrimSuffix(allowedOrigin, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
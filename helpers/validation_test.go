@@ -0,0 +1,27 @@
+package helpers
+
+import "testing"
+
+func TestIsAllowedOrigin(t *testing.T) {
+	allowedOrigins := []string{"http://localhost:8000", "https://app.metaedu.io/"}
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "", want: true},
+		{origin: "http://localhost:8000", want: true},
+		{origin: "https://app.metaedu.io", want: true},
+		{origin: "https://APP.metaedu.io", want: true},
+		{origin: "http://app.metaedu.io", want: false},
+		{origin: "http://localhost:3000", want: false},
+		{origin: "https://app.metaedu.io.evil.com", want: false},
+		{origin: "null", want: false},
+	}
+
+	for _, test := range tests {
+		if got := IsAllowedOrigin(test.origin, allowedOrigins); got != test.want {
+			t.Errorf("IsAllowedOrigin(%q) = %v, want %v", test.origin, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package helpers
+
+const (
+	VersionResourceToken      = "token"
+	VersionResourceCollection = "collection"
+	VersionResourceOwnership  = "ownership"
+	VersionResourceRental     = "rental"
+)
+
+const (
+	PendingChangeStatusPending = "pending"
+	PendingChangeStatusApplied = "applied"
+	PendingChangeStatusFailed  = "failed"
+	PendingChangeStatusSkipped = "skipped"
+)
+
+// Resources whose confirmed states are kept as versions
+var VersionTables = map[string]string{
+	VersionResourceCollection: "collections",
+	VersionResourceOwnership:  "ownerships",
+	VersionResourceRental:     "rentals",
+	VersionResourceToken:      "tokens",
+}
@@ -0,0 +1,10 @@
+package helpers
+
+import "time"
+
+const (
+	TrendingWindow     = 24 * time.Hour
+	TrendingHalfLife   = 6 * time.Hour
+	TrendingSaleWeight = 10
+	TrendingSize       = 100
+)
@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"math"
+	"net"
+	"time"
+)
+
+const (
+	WebhookEventTransactionConfirmed = "transaction.confirmed"
+	WebhookEventRentalStarted        = "rental.started"
+	WebhookEventRentalExpired        = "rental.expired"
+	WebhookEventRentalExtended       = "rental.extended"
+	WebhookEventRentalReturned       = "rental.returned"
+	WebhookEventRentalCancelled      = "rental.cancelled"
+	WebhookEventTokenMinted          = "token.minted"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusFailed    = "failed"
+	WebhookDeliveryStatusDead      = "dead"
+)
+
+// MaxWebhookAttempts is the number of attempts before a delivery is dead-lettered
+const MaxWebhookAttempts = 8
+
+func IsValidWebhookEventType(eventType string) bool {
+	switch eventType {
+	case WebhookEventTransactionConfirmed, WebhookEventRentalStarted, WebhookEventRentalExpired, WebhookEventRentalExtended, WebhookEventRentalReturned, WebhookEventRentalCancelled, WebhookEventTokenMinted:
+		return true
+	}
+
+	return false
+}
+
+// Carrier-grade NAT range, not covered by net.IP.IsPrivate
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicIP rejects loopback, private, link-local and other internal addresses webhooks must never reach
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	return !sharedAddressSpace.Contains(ip)
+}
+
+// GetWebhookRetryDelay doubles the delay after every attempt, capped at six hours
+func GetWebhookRetryDelay(attempts int) time.Duration {
+	delay := time.Duration(math.Pow(2, float64(attempts))) * 30 * time.Second
+
+	if delay > 6*time.Hour || delay <= 0 {
+		return 6 * time.Hour
+	}
+
+	return delay
+}
@@ -1,106 +1,286 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"log"
+	"net"
 	"net/http"
 	"os"
 
+	"metaedu-marketplace/app"
 	"metaedu-marketplace/config"
 	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/events"
 	"metaedu-marketplace/middlewares"
-	"metaedu-marketplace/repositories"
 	"metaedu-marketplace/routes"
 
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 
 	_ "github.com/lib/pq"
 )
 
+const usage = `Usage: metaedu-marketplace [flags] <command> [arguments]
+
+Settings come from defaults, the config file, secret files, the environment
+and the command flags, each one overriding the previous.
+
+Commands:
+  serve                          start the API
+  worker                         start the confirmation worker, replicas elect one leader
+  migrate                        upgrade or downgrade the database schema
+  seed                           insert the default token categories
+  reindex                        rebuild statistics, rankings and trending scores
+  user promote-admin <address>   give a user the admin role
+  user promote-partner <address> give a user the partner role
+  config [command]               validate and print the settings, secrets redacted
+
+Flags:
+`
+
 var (
 	server *gin.Engine
 
-	CollectionRepository    *repositories.CollectionRepository
-	FractionRepository      *repositories.FractionRepository
-	OwnershipRepository     *repositories.OwnershipRepository
-	RentalRepository        *repositories.RentalRepository
-	TokenRepository         *repositories.TokenRepository
-	TokenCategoryRepository *repositories.TokenCategoryRepository
-	TransactionRepository   *repositories.TransactionRepository
-	UserRepository          *repositories.UserRepository
+	EventHub *events.Hub
 
+	AuditMiddleware         *middlewares.AuditMiddleware
 	AuthorizationMiddleware *middlewares.AuthorizationMiddleware
+	TimeoutMiddleware       *middlewares.TimeoutMiddleware
 
+	AuditController          controllers.AuditController
 	AuthenticationController controllers.AuthenticationController
 	CollectionController     controllers.CollectionController
+	EventController          controllers.EventController
+	FavoriteController       controllers.FavoriteController
+	FeeScheduleController    controllers.FeeScheduleController
+	FollowController         controllers.FollowController
 	FractionController       controllers.FractionController
+	ModerationController     controllers.ModerationController
+	NotificationController   controllers.NotificationController
 	OwnershipController      controllers.OwnershipController
+	RankingController        controllers.RankingController
 	RentalController         controllers.RentalController
+	StatisticController      controllers.StatisticController
 	TokenController          controllers.TokenController
 	TokenCategoryController  controllers.TokenCategoryController
 	TransactionController    controllers.TransactionController
+	TrashController          controllers.TrashController
+	TrendingController       controllers.TrendingController
 	UserController           controllers.UserController
+	VersionController        controllers.VersionController
+	WatchlistController      controllers.WatchlistController
+	WebhookController        controllers.WebhookController
 
+	AuditRoutes          routes.AuditRoutes
 	AuthenticationRoutes routes.AuthenticationRoutes
 	CollectionRoutes     routes.CollectionRoutes
+	EventRoutes          routes.EventRoutes
+	FavoriteRoutes       routes.FavoriteRoutes
+	FeeScheduleRoutes    routes.FeeScheduleRoutes
+	FollowRoutes         routes.FollowRoutes
 	FractionRoutes       routes.FractionRoutes
+	ModerationRoutes     routes.ModerationRoutes
+	NotificationRoutes   routes.NotificationRoutes
 	OwnershipRoutes      routes.OwnershipRoutes
+	RankingRoutes        routes.RankingRoutes
 	RentalRoutes         routes.RentalRoutes
+	StatisticRoutes      routes.StatisticRoutes
 	TokenRoutes          routes.TokenRoutes
 	TokenCategoryRoutes  routes.TokenCategoryRoutes
 	TransactionRoutes    routes.TransactionRoutes
+	TrashRoutes          routes.TrashRoutes
+	TrendingRoutes       routes.TrendingRoutes
 	UserRoutes           routes.UserRoutes
+	VersionRoutes        routes.VersionRoutes
+	WatchlistRoutes      routes.WatchlistRoutes
+	WebhookRoutes        routes.WebhookRoutes
 )
 
-func init() {
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
+// Where the config is read from, set by the global flags
+var configSource config.Source
+
+func main() {
+	envFile := flag.String("env-file", ".env", "dotenv file merged into the environment, variables already set win")
+	flag.StringVar(&configSource.File, "config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file")
+	flag.StringVar(&configSource.SecretsDir, "secrets-dir", config.DefaultSecretsDir, "directory with one file per secret")
+
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// A missing default file is fine, the environment may hold everything
+	envFileRequired := false
+
+	flag.Visit(func(f *flag.Flag) {
+		envFileRequired = envFileRequired || f.Name == "env-file"
+	})
+
+	if err := config.LoadEnvFile(*envFile, envFileRequired); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	commands := map[string]func(args []string) int{
+		"serve":   serve,
+		"worker":  runWorker,
+		"migrate": runMigrate,
+		"seed":    runSeed,
+		"reindex": runReindex,
+		"user":    runUser,
+		"config":  printConfig,
+	}
+
+	command, ok := commands[flag.Arg(0)]
+
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	os.Exit(command(flag.Args()[1:]))
+}
+
+// loadConfig reads the settings and stops with every problem when the command cannot run with them
+func loadConfig(command string) *config.Config {
+	cfg, err := config.LoadFor(configSource, command)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(1)
 	}
 
-	dbClient := config.CreateDBClient()
-	jwtHmacProvider := config.CreateJwtHmacProvider()
-	web3StorageClient := config.CreateWeb3StorageClient()
-	redisClient := config.CreateRedisClient()
-
-	CollectionRepository = repositories.NewCollectionRepository(dbClient)
-	FractionRepository = repositories.NewFractionRepository(dbClient)
-	OwnershipRepository = repositories.NewOwnershipRepository(dbClient)
-	RentalRepository = repositories.NewRentalRepository(dbClient)
-	TokenRepository = repositories.NewTokenRepository(dbClient)
-	TokenCategoryRepository = repositories.NewTokenCategoryRepository(dbClient)
-	TransactionRepository = repositories.NewTransactionRepository(dbClient)
-	UserRepository = repositories.NewUserRepository(dbClient)
-
-	AuthorizationMiddleware = middlewares.NewAuthorizationMiddleware(*UserRepository, jwtHmacProvider)
-
-	AuthenticationController = *controllers.NewAuthenticationController(UserRepository, jwtHmacProvider)
-	CollectionController = *controllers.NewCollectionController(CollectionRepository, TransactionRepository, web3StorageClient, redisClient)
-	FractionController = *controllers.NewFractionController(FractionRepository, TokenRepository, OwnershipRepository, RentalRepository, UserRepository, web3StorageClient, redisClient)
-	OwnershipController = *controllers.NewOwnershipController(OwnershipRepository, TokenRepository, RentalRepository, web3StorageClient, redisClient)
-	RentalController = *controllers.NewRentalController(RentalRepository, web3StorageClient, redisClient)
-	TokenController = *controllers.NewTokenController(TokenRepository, OwnershipRepository, CollectionRepository, TransactionRepository, web3StorageClient, redisClient)
-	TokenCategoryController = *controllers.NewTokenCategoryController(TokenCategoryRepository, web3StorageClient, redisClient)
-	TransactionController = *controllers.NewTransactionController(TransactionRepository, TokenRepository, CollectionRepository, OwnershipRepository, RentalRepository, web3StorageClient, redisClient)
-	UserController = *controllers.NewUserController(UserRepository, web3StorageClient, redisClient)
+	return cfg
+}
+
+// newApp connects to Postgres and refuses to go on against an outdated schema
+func newApp(command string) *app.App {
+	application, err := app.New(loadConfig(command))
+
+	if err == nil {
+		err = application.CheckSchema()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	return application
+}
+
+func printConfig(args []string) int {
+	command := "serve"
+
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	fmt.Print(loadConfig(command))
+
+	return 0
+}
+
+func setupServer(application *app.App) error {
+	cfg := application.Config
+
+	web3StorageClient, err := config.CreateWeb3StorageClient(cfg.Web3Storage)
+
+	if err != nil {
+		return err
+	}
+
+	jwtHmacProvider := config.CreateJwtHmacProvider(cfg.JWT)
+	redisClient := application.Redis()
+	voucherVerifier := config.CreateVoucherVerifier(cfg.Chain)
+	emailVerifier := config.CreateEmailVerifier(cfg.JWT, cfg.App.URL)
+	mailer := config.CreateMailer(cfg.SMTP)
+	viewTracker := config.CreateViewTracker(redisClient, cfg.App)
+
+	repos := application.Repositories
+
+	EventHub = events.NewHub(redisClient)
 
+	AuditMiddleware = middlewares.NewAuditMiddleware(repos.Audit)
+	AuthorizationMiddleware = middlewares.NewAuthorizationMiddleware(*repos.User, jwtHmacProvider)
+	TimeoutMiddleware = middlewares.NewTimeoutMiddleware(cfg.Server.RequestTimeout)
+
+	AuditController = *controllers.NewAuditController(repos.Audit)
+	AuthenticationController = *controllers.NewAuthenticationController(repos.User, jwtHmacProvider)
+	CollectionController = *controllers.NewCollectionController(repos.Collection, repos.Transaction, repos.PriceHistory, repos.Moderation, viewTracker, web3StorageClient, redisClient)
+	EventController = *controllers.NewEventController(EventHub, cfg.AllowedOriginList())
+	FavoriteController = *controllers.NewFavoriteController(repos.Favorite, repos.Token, repos.Collection, redisClient)
+	FeeScheduleController = *controllers.NewFeeScheduleController(repos.FeeSchedule, repos.Token, redisClient)
+	FollowController = *controllers.NewFollowController(repos.Follow, repos.User, repos.Collection, redisClient)
+	FractionController = *controllers.NewFractionController(repos.Fraction, repos.Token, repos.Ownership, repos.Rental, repos.User, repos.FeeSchedule, repos.TransactionFee, repos.FractionShare, repos.FractionBuyout, repos.Version, web3StorageClient, redisClient)
+	ModerationController = *controllers.NewModerationController(repos.Moderation, redisClient)
+	NotificationController = *controllers.NewNotificationController(repos.Notification)
+	OwnershipController = *controllers.NewOwnershipController(repos.Ownership, repos.Token, repos.Rental, repos.Version, web3StorageClient, redisClient)
+	RankingController = *controllers.NewRankingController(repos.Ranking, redisClient)
+	RentalController = *controllers.NewRentalController(repos.Rental, repos.Token, repos.Ownership, repos.RentalEvent, repos.FeeSchedule, repos.Version, web3StorageClient, redisClient)
+	StatisticController = *controllers.NewStatisticController(repos.Statistic, redisClient)
+	TokenController = *controllers.NewTokenController(repos.Token, repos.Ownership, repos.Collection, repos.Transaction, repos.MintVoucher, repos.PriceHistory, repos.Moderation, voucherVerifier, viewTracker, web3StorageClient, redisClient)
+	TokenCategoryController = *controllers.NewTokenCategoryController(repos.TokenCategory, web3StorageClient, redisClient)
+	TransactionController = *controllers.NewTransactionController(repos.Transaction, repos.Token, repos.Collection, repos.Ownership, repos.Rental, repos.FeeSchedule, repos.TransactionFee, repos.MintVoucher, repos.Version, web3StorageClient, redisClient)
+	TrashController = *controllers.NewTrashController(repos.Trash, redisClient)
+	TrendingController = *controllers.NewTrendingController(repos.View, redisClient)
+	UserController = *controllers.NewUserController(repos.User, emailVerifier, mailer, web3StorageClient, redisClient)
+	VersionController = *controllers.NewVersionController(repos.Version, repos.Token, repos.Collection)
+	WatchlistController = *controllers.NewWatchlistController(repos.Watchlist, repos.Token, repos.Collection)
+	WebhookController = *controllers.NewWebhookController(repos.Webhook)
+
+	AuditRoutes = routes.NewAuditRoutes(*AuthorizationMiddleware, AuditController)
 	AuthenticationRoutes = routes.NewAuthenticationRoutes(AuthenticationController)
 	CollectionRoutes = routes.NewCollectionRoutes(*AuthorizationMiddleware, CollectionController)
+	EventRoutes = routes.NewEventRoutes(*AuthorizationMiddleware, EventController)
+	FavoriteRoutes = routes.NewFavoriteRoutes(*AuthorizationMiddleware, FavoriteController)
+	FeeScheduleRoutes = routes.NewFeeScheduleRoutes(*AuthorizationMiddleware, FeeScheduleController)
+	FollowRoutes = routes.NewFollowRoutes(*AuthorizationMiddleware, FollowController)
 	FractionRoutes = routes.NewFractionRoutes(*AuthorizationMiddleware, FractionController)
+	ModerationRoutes = routes.NewModerationRoutes(*AuthorizationMiddleware, ModerationController)
+	NotificationRoutes = routes.NewNotificationRoutes(*AuthorizationMiddleware, NotificationController)
 	OwnershipRoutes = routes.NewOwnershipRoutes(*AuthorizationMiddleware, OwnershipController)
+	RankingRoutes = routes.NewRankingRoutes(*AuthorizationMiddleware, RankingController)
 	RentalRoutes = routes.NewRentalRoutes(*AuthorizationMiddleware, RentalController)
+	StatisticRoutes = routes.NewStatisticRoutes(*AuthorizationMiddleware, StatisticController)
 	TokenRoutes = routes.NewTokenRoutes(*AuthorizationMiddleware, TokenController)
 	TokenCategoryRoutes = routes.NewTokenCategoryRoutes(*AuthorizationMiddleware, TokenCategoryController)
 	TransactionRoutes = routes.NewTransactionRoutes(*AuthorizationMiddleware, TransactionController)
+	TrashRoutes = routes.NewTrashRoutes(*AuthorizationMiddleware, TrashController)
+	TrendingRoutes = routes.NewTrendingRoutes(*AuthorizationMiddleware, TrendingController)
 	UserRoutes = routes.NewUserRoutes(*AuthorizationMiddleware, UserController)
+	VersionRoutes = routes.NewVersionRoutes(*AuthorizationMiddleware, VersionController)
+	WatchlistRoutes = routes.NewWatchlistRoutes(*AuthorizationMiddleware, WatchlistController)
+	WebhookRoutes = routes.NewWebhookRoutes(*AuthorizationMiddleware, WebhookController)
 
 	server = gin.Default()
-}
 
-func main() {
 	// Set maximum uploaded file size
-	server.MaxMultipartMemory = 10 << 20
+	server.MaxMultipartMemory = cfg.Server.MaxUploadSize
+
+	return nil
+}
+
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.String("port", "", "port the API listens on, overrides PORT")
+	flags.Parse(args)
+
+	config.ApplyFlags(flags, map[string]string{"port": "PORT"})
+
+	application := newApp("serve")
+
+	if err := setupServer(application); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
 
 	// Add gin logger
 	server.Use(gin.Logger())
@@ -108,27 +288,109 @@ func main() {
 	// Add gin recovery
 	server.Use(gin.Recovery())
 
+	lifecycle := app.NewLifecycle(application.Config.App.ShutdownTimeout, application.Config.Server.ReadinessDrainDelay)
+
+	lifecycle.OnStop("redis and postgres", func(ctx context.Context) error {
+		return application.Close()
+	})
+
+	// Fan out marketplace events to subscribers
+	go EventHub.Run()
+
+	lifecycle.OnStop("event hub", func(ctx context.Context) error {
+		EventHub.Close()
+		return nil
+	})
+
 	router := server.Group("/api/v1")
 
+	// Record every mutating request in the audit log
+	router.Use(AuditMiddleware.RecordAction)
+
+	// Give every request a deadline, see helpers.RouteTimeouts for the routes that differ
+	router.Use(TimeoutMiddleware.SetDeadline)
+
 	router.GET("/healthchecker", func(ctx *gin.Context) {
 		ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Welcome to MetaEdu Marketplace"})
 	})
 
+	// Fails as soon as shutdown starts so the load balancer stops sending requests before the server drains
+	router.GET("/readiness", func(ctx *gin.Context) {
+		if !lifecycle.IsReady() {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"status": "failed", "error": "Server is shutting down"})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Ready"})
+	})
+
+	AuditRoutes.AuditRoute(router)
 	AuthenticationRoutes.AuthenticationRoute(router)
 	CollectionRoutes.CollectionRoute(router)
+	EventRoutes.EventRoute(router)
+	FavoriteRoutes.FavoriteRoute(router)
+	FeeScheduleRoutes.FeeScheduleRoute(router)
+	FollowRoutes.FollowRoute(router)
 	FractionRoutes.FractionRoute(router)
+	ModerationRoutes.ModerationRoute(router)
+	NotificationRoutes.NotificationRoute(router)
 	OwnershipRoutes.OwnershipRoute(router)
+	RankingRoutes.RankingRoute(router)
 	RentalRoutes.RentalRoute(router)
+	StatisticRoutes.StatisticRoute(router)
 	TokenRoutes.TokenRoute(router)
 	TokenCategoryRoutes.TokenCategoryRoute(router)
 	TransactionRoutes.TransactionRoute(router)
+	TrashRoutes.TrashRoute(router)
+	TrendingRoutes.TrendingRoute(router)
 	UserRoutes.UserRoute(router)
+	VersionRoutes.VersionRoute(router)
+	WatchlistRoutes.WatchlistRoute(router)
+	WebhookRoutes.WebhookRoute(router)
 
-	port, success := os.LookupEnv("PORT")
-	if !success {
-		fmt.Fprintln(os.Stderr, "No REDIS_PORT - set the REDIS_PORT environment var and try again.")
-		os.Exit(1)
+	httpServer := &http.Server{
+		Addr:              ":" + application.Config.Server.Port,
+		Handler:           server,
+		ReadHeaderTimeout: application.Config.Server.ReadHeaderTimeout,
+	}
+
+	// Event streams only end when the hub closes, Shutdown would wait for them until the timeout
+	httpServer.RegisterOnShutdown(EventHub.Close)
+
+	listener, err := net.Listen("tcp", httpServer.Addr)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		application.Close()
+		return 1
+	}
+
+	failed := make(chan error, 1)
+
+	go func() {
+		if err := httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			failed <- err
+		}
+	}()
+
+	lifecycle.OnStop("http server", func(ctx context.Context) error {
+		err := httpServer.Shutdown(ctx)
+
+		// Cut the requests still running once the timeout is over
+		if err != nil {
+			httpServer.Close()
+		}
+
+		return err
+	})
+
+	lifecycle.SetReady(true)
+	fmt.Println("Listening on ", httpServer.Addr)
+
+	if err := lifecycle.Wait(failed); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
 	}
 
-	log.Fatal(server.Run(":" + port))
+	return 0
 }
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AuditMiddleware struct {
+	auditRepository *repositories.AuditRepository
+}
+
+func NewAuditMiddleware(auditRepository *repositories.AuditRepository) *AuditMiddleware {
+	return &AuditMiddleware{auditRepository}
+}
+
+// RecordAction tags every request with an id and appends mutating requests to the audit log
+func (am *AuditMiddleware) RecordAction(ctx *gin.Context) {
+	requestID := ctx.GetHeader("X-Request-ID")
+
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	ctx.Set("request_id", requestID)
+	ctx.Header("X-Request-ID", requestID)
+
+	if !helpers.IsAuditedMethod(ctx.Request.Method) || ctx.FullPath() == "" {
+		return
+	}
+
+	resourceType := helpers.GetAuditResource(ctx.FullPath())
+	table, isTable := helpers.AuditResourceTables[resourceType]
+	resourceID, err := uuid.Parse(ctx.Param("id"))
+	hasSnapshot := isTable && err == nil
+
+	requestCtx := ctx.Request.Context()
+
+	var before []byte
+
+	if hasSnapshot {
+		before, err = am.auditRepository.GetResourceSnapshot(requestCtx, table, resourceID, false)
+
+		if err != nil {
+			fmt.Println("Error getting audit snapshot: ", resourceType, ", id: ", resourceID, ", error: ", err)
+		}
+	}
+
+	ctx.Next()
+
+	// The request may have timed out or the client gone away, the change is made and still has to be recorded
+	auditCtx, cancel := context.WithTimeout(helpers.WithoutCancel(requestCtx), helpers.AuditWriteTimeout)
+	defer cancel()
+
+	var after []byte
+
+	if hasSnapshot {
+		after, err = am.auditRepository.GetResourceSnapshot(auditCtx, table, resourceID, true)
+
+		if err != nil {
+			fmt.Println("Error getting audit snapshot: ", resourceType, ", id: ", resourceID, ", error: ", err)
+		}
+	}
+
+	auditLog := models.AuditLog{
+		Action:       ctx.Request.Method + " " + ctx.FullPath(),
+		Method:       ctx.Request.Method,
+		Path:         ctx.Request.URL.Path,
+		ResourceType: resourceType,
+		ResourceID:   ctx.Param("id"),
+		RequestID:    requestID,
+		IP:           ctx.ClientIP(),
+		UserAgent:    ctx.Request.UserAgent(),
+		StatusCode:   ctx.Writer.Status(),
+	}
+
+	if user, isExist := ctx.Get("user"); isExist {
+		auditLog.ActorID = user.(models.User).ID
+	}
+
+	auditLog.Before, auditLog.After = helpers.DiffAuditSnapshots(before, after)
+
+	_, err = am.auditRepository.InsertAuditLog(auditCtx, auditLog)
+
+	if err != nil {
+		fmt.Println("Error recording audit log: ", auditLog.Action, ", request: ", requestID, ", error: ", err)
+	}
+}
@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
 	"metaedu-marketplace/repositories"
 	"metaedu-marketplace/utils"
 	"net/http"
@@ -46,7 +48,7 @@ func (ac *AuthorizationMiddleware) VerifyToken(ctx *gin.Context) {
 		return
 	}
 
-	user, err := ac.userRepository.GetUserByID(userId)
+	user, err := ac.userRepository.GetUserByID(ctx.Request.Context(), userId)
 
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
@@ -54,5 +56,89 @@ func (ac *AuthorizationMiddleware) VerifyToken(ctx *gin.Context) {
 		return
 	}
 
+	if user.ModerationStatus == helpers.ModerationStatusBanned {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "User has been banned"})
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set("user", user)
+}
+
+func (ac *AuthorizationMiddleware) VerifyAdmin(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
+		ctx.Abort()
+		return
+	}
+
+	if user.(models.User).Role != "admin" {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only admin can access this resource"})
+		ctx.Abort()
+		return
+	}
+}
+
+// VerifyPartner lets partner schools and admins through, used for the integrations that receive marketplace data
+func (ac *AuthorizationMiddleware) VerifyPartner(ctx *gin.Context) {
+	user, isExist := ctx.Get("user")
+
+	if !isExist {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
+		ctx.Abort()
+		return
+	}
+
+	if role := user.(models.User).Role; role != "admin" && role != "partner" {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "Only partners can access this resource"})
+		ctx.Abort()
+		return
+	}
+}
+
+// VerifyOptionalToken sets the user when a valid token is sent in the header or the access_token query
+func (ac *AuthorizationMiddleware) VerifyOptionalToken(ctx *gin.Context) {
+	accessToken := strings.Replace(ctx.Request.Header.Get("Authorization"), "Bearer ", "", 1)
+
+	if accessToken == "" {
+		accessToken = ctx.Query("access_token")
+	}
+
+	if accessToken == "" {
+		return
+	}
+
+	result, err := ac.jwtProvider.Verify(accessToken)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
+		ctx.Abort()
+		return
+	}
+
+	userId, err := uuid.Parse(result.Subject)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
+		ctx.Abort()
+		return
+	}
+
+	user, err := ac.userRepository.GetUserByID(ctx.Request.Context(), userId)
+
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"status": "failed", "error": "User not authorized"})
+		ctx.Abort()
+		return
+	}
+
+	if user.ModerationStatus == helpers.ModerationStatusBanned {
+		ctx.JSON(http.StatusForbidden, gin.H{"status": "failed", "error": "User has been banned"})
+		ctx.Abort()
+		return
+	}
+
 	ctx.Set("user", user)
 }
@@ -0,0 +1,33 @@
+package middlewares
+
+import (
+	"context"
+	"metaedu-marketplace/helpers"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TimeoutMiddleware struct {
+	defaultTimeout time.Duration
+}
+
+func NewTimeoutMiddleware(defaultTimeout time.Duration) *TimeoutMiddleware {
+	return &TimeoutMiddleware{defaultTimeout}
+}
+
+// SetDeadline bounds the request context by the route timeout, queries and uploads using it stop once it expires
+func (tm *TimeoutMiddleware) SetDeadline(ctx *gin.Context) {
+	timeout := helpers.GetRouteTimeout(ctx.Request.Method, ctx.FullPath(), tm.defaultTimeout)
+
+	if timeout <= 0 {
+		return
+	}
+
+	requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+	defer cancel()
+
+	ctx.Request = ctx.Request.WithContext(requestCtx)
+
+	ctx.Next()
+}
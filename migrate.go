@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"metaedu-marketplace/app"
+	"metaedu-marketplace/db"
+)
+
+const migrateUsage = `Usage: metaedu-marketplace migrate <command>
+
+Commands:
+  up          apply every pending migration
+  down [n]    revert the last n migrations (default 1)
+  version     print the applied and the latest version
+  force <v>   set the version without running migrations`
+
+// runMigrate handles the migrate subcommand and returns the exit code
+func runMigrate(args []string) int {
+	// No schema check, fixing the schema is the point of this command
+	application, err := app.New(loadConfig("migrate"))
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	migrator, err := db.NewMigrator(application.DB)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "up":
+		migrations, err := migrator.Up()
+
+		printMigrations("Applied", migrations)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	case "down":
+		steps := 1
+
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+
+			if err != nil || steps < 1 {
+				fmt.Fprintln(os.Stderr, "Number of migrations to revert must be a positive integer")
+				return 2
+			}
+		}
+
+		migrations, err := migrator.Down(steps)
+
+		printMigrations("Reverted", migrations)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	case "version":
+		version, dirty, err := migrator.Version()
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+
+		fmt.Printf("Version: %d, latest: %d, dirty: %t\n", version, migrator.Latest(), dirty)
+	case "force":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+
+		version, err := strconv.ParseUint(args[1], 10, 64)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Version must be a non-negative integer")
+			return 2
+		}
+
+		err = migrator.Force(uint(version))
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+
+		fmt.Printf("Forced version %d\n", version)
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
+
+func printMigrations(action string, migrations []db.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("No migrations to run")
+		return
+	}
+
+	for _, migration := range migrations {
+		fmt.Printf("%s %06d_%s\n", action, migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLog struct {
+	ID           int64           `json:"id"`
+	ActorID      uuid.UUID       `json:"actor_id"`
+	Action       string          `json:"action"`
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	RequestID    string          `json:"request_id"`
+	IP           string          `json:"ip"`
+	UserAgent    string          `json:"user_agent"`
+	StatusCode   int             `json:"status_code"`
+	PreviousHash string          `json:"previous_hash"`
+	Hash         string          `json:"hash"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type AuditChainVerification struct {
+	Checked  int   `json:"checked"`
+	Valid    bool  `json:"valid"`
+	BrokenID int64 `json:"broken_id"`
+}
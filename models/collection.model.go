@@ -8,7 +8,6 @@ import (
 
 type Collection struct {
 	ID                   uuid.UUID       `json:"id"`
-	PreviousID           uuid.UUID       `json:"previous_id"`
 	Thumbnail            sql.NullString  `json:"thumbnail"`
 	Cover                sql.NullString  `json:"cover"`
 	Title                sql.NullString  `json:"title"`
@@ -17,11 +16,13 @@ type Collection struct {
 	NumberOfTransactions sql.NullInt64   `json:"number_of_transactions"`
 	VolumeTransactions   sql.NullFloat64 `json:"volume_transactions"`
 	Floor                sql.NullFloat64 `json:"floor"`
+	FavoriteCount        sql.NullInt64   `json:"favorite_count"`
 	Description          sql.NullString  `json:"description"`
 	CategoryID           uuid.UUID       `json:"category_id"`
 	Category             TokenCategory   `json:"category"`
 	CreatorID            uuid.UUID       `json:"owner_id"`
 	Creator              User            `json:"creator"`
+	ModerationStatus     string          `json:"moderation_status"`
 	Status               sql.NullString  `json:"status"`
 	TransactionHash      sql.NullString  `json:"transaction_hash"`
 	CreatedAt            sql.NullTime    `json:"created_at"`
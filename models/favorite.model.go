@@ -0,0 +1,17 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Favorite struct {
+	ID           uuid.UUID    `json:"id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	TokenID      uuid.UUID    `json:"token_id"`
+	Token        Token        `json:"token"`
+	CollectionID uuid.UUID    `json:"collection_id"`
+	Collection   Collection   `json:"collection"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
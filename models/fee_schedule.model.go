@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type FeeSchedule struct {
+	ID              uuid.UUID    `json:"id"`
+	Title           string       `json:"title"`
+	TransactionType string       `json:"transaction_type"`
+	FeeType         string       `json:"fee_type"`
+	Value           float64      `json:"value"`
+	CategoryID      uuid.UUID    `json:"category_id"`
+	CollectionID    uuid.UUID    `json:"collection_id"`
+	RecipientID     uuid.UUID    `json:"recipient_id"`
+	Promotion       bool         `json:"promotion"`
+	StartsAt        sql.NullTime `json:"starts_at"`
+	EndsAt          sql.NullTime `json:"ends_at"`
+	Status          string       `json:"status"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+}
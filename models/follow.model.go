@@ -0,0 +1,28 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Follow struct {
+	ID           uuid.UUID    `json:"id"`
+	FollowerID   uuid.UUID    `json:"follower_id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	User         User         `json:"user"`
+	CollectionID uuid.UUID    `json:"collection_id"`
+	Collection   Collection   `json:"collection"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
+
+type FeedItem struct {
+	Type         string       `json:"type"`
+	ReferenceID  uuid.UUID    `json:"reference_id"`
+	TokenID      uuid.UUID    `json:"token_id"`
+	Token        Token        `json:"token"`
+	CollectionID uuid.UUID    `json:"collection_id"`
+	ActorID      uuid.UUID    `json:"actor_id"`
+	Price        float64      `json:"price"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
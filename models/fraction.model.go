@@ -8,9 +8,14 @@ import (
 
 type Fraction struct {
 	ID              uuid.UUID    `json:"id"`
-	PreviousID      uuid.UUID    `json:"previous_id"`
 	TokenParentID   uuid.UUID    `json:"token_parent_id"`
 	TokenFractionID uuid.UUID    `json:"token_fraction_id"`
+	OwnerID         uuid.UUID    `json:"owner_id"`
+	OwnershipID     uuid.UUID    `json:"ownership_id"`
+	TotalShares     int          `json:"total_shares"`
+	SharePrice      float64      `json:"share_price"`
+	ReservePrice    float64      `json:"reserve_price"`
+	SettledAt       sql.NullTime `json:"settled_at"`
 	Status          string       `json:"status"`
 	TransactionHash string       `json:"transaction_hash"`
 	CreatedAt       sql.NullTime `json:"created_at"`
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type FractionBuyout struct {
+	ID              uuid.UUID    `json:"id"`
+	FractionID      uuid.UUID    `json:"fraction_id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Type            string       `json:"type"`
+	PricePerShare   float64      `json:"price_per_share"`
+	Amount          float64      `json:"amount"`
+	Status          string       `json:"status"`
+	TransactionHash string       `json:"transaction_hash"`
+	ConfirmedAt     sql.NullTime `json:"confirmed_at"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type FractionPayout struct {
+	ID         uuid.UUID    `json:"id"`
+	BuyoutID   uuid.UUID    `json:"buyout_id"`
+	FractionID uuid.UUID    `json:"fraction_id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Type       string       `json:"type"`
+	Shares     int          `json:"shares"`
+	Amount     float64      `json:"amount"`
+	Status     string       `json:"status"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type FractionShare struct {
+	ID              uuid.UUID    `json:"id"`
+	FractionID      uuid.UUID    `json:"fraction_id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	Quantity        int          `json:"quantity"`
+	Reason          string       `json:"reason"`
+	ReferenceID     uuid.UUID    `json:"reference_id"`
+	TransactionHash string       `json:"transaction_hash"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+}
+
+type FractionShareBalance struct {
+	UserID  uuid.UUID `json:"user_id"`
+	User    User      `json:"user"`
+	Balance int       `json:"balance"`
+}
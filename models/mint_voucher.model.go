@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type MintVoucher struct {
+	ID              uuid.UUID    `json:"id"`
+	TokenID         uuid.UUID    `json:"token_id"`
+	CreatorID       uuid.UUID    `json:"creator_id"`
+	TokenIndex      int          `json:"token_index"`
+	Uri             string       `json:"uri"`
+	Supply          int          `json:"supply"`
+	Price           float64      `json:"price"`
+	Royalty         int          `json:"royalty"`
+	Nonce           string       `json:"nonce"`
+	Signature       string       `json:"signature"`
+	Status          string       `json:"status"`
+	TransactionHash string       `json:"transaction_hash"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+}
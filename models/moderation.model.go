@@ -0,0 +1,35 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Report struct {
+	ID          uuid.UUID      `json:"id"`
+	ReporterID  uuid.UUID      `json:"reporter_id"`
+	Reporter    User           `json:"reporter"`
+	SubjectType string         `json:"subject_type"`
+	SubjectID   uuid.UUID      `json:"subject_id"`
+	Reason      string         `json:"reason"`
+	Description sql.NullString `json:"description"`
+	Status      string         `json:"status"`
+	ResolvedBy  uuid.UUID      `json:"resolved_by"`
+	ResolvedAt  sql.NullTime   `json:"resolved_at"`
+	UpdatedAt   sql.NullTime   `json:"updated_at"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+type ModerationAction struct {
+	ID             uuid.UUID      `json:"id"`
+	ModeratorID    uuid.UUID      `json:"moderator_id"`
+	SubjectType    string         `json:"subject_type"`
+	SubjectID      uuid.UUID      `json:"subject_id"`
+	ReportID       uuid.UUID      `json:"report_id"`
+	Action         string         `json:"action"`
+	PreviousStatus sql.NullString `json:"previous_status"`
+	NewStatus      sql.NullString `json:"new_status"`
+	Reason         sql.NullString `json:"reason"`
+	CreatedAt      sql.NullTime   `json:"created_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type Notification struct {
+	ID        uuid.UUID       `json:"id"`
+	UserID    uuid.UUID       `json:"user_id"`
+	Type      string          `json:"type"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data"`
+	ReadAt    sql.NullTime    `json:"read_at"`
+	CreatedAt sql.NullTime    `json:"created_at"`
+}
+
+type OutboxEmail struct {
+	ID            uuid.UUID    `json:"id"`
+	UserID        uuid.UUID    `json:"user_id"`
+	Type          string       `json:"type"`
+	ToAddress     string       `json:"to_address"`
+	Subject       string       `json:"subject"`
+	Body          string       `json:"body"`
+	Status        string       `json:"status"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error"`
+	NextAttemptAt sql.NullTime `json:"next_attempt_at"`
+	SentAt        sql.NullTime `json:"sent_at"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+	UpdatedAt     sql.NullTime `json:"updated_at"`
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID    `json:"user_id"`
+	Type      string       `json:"type"`
+	InApp     bool         `json:"in_app"`
+	Email     bool         `json:"email"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
@@ -8,12 +8,12 @@ import (
 
 type Ownership struct {
 	ID               uuid.UUID    `json:"id"`
-	PreviousID       uuid.UUID    `json:"previous_id"`
 	TokenID          uuid.UUID    `json:"token_id"`
 	Token            Token        `json:"token"`
 	UserID           uuid.UUID    `json:"user_id"`
 	User             User         `json:"user"`
 	Quantity         int          `json:"quantity"`
+	RentedQuantity   int          `json:"rented_quantity"`
 	SalePrice        float64      `json:"sale_price"`
 	RentCost         float64      `json:"rent_cost"`
 	AvailableForSale bool         `json:"available_for_sale"`
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PricePoint struct {
+	ID            uuid.UUID    `json:"id"`
+	TransactionID uuid.UUID    `json:"transaction_id"`
+	TokenID       uuid.UUID    `json:"token_id"`
+	CollectionID  uuid.UUID    `json:"collection_id"`
+	Type          string       `json:"type"`
+	Price         float64      `json:"price"`
+	Quantity      int          `json:"quantity"`
+	Amount        float64      `json:"amount"`
+	BuyerID       uuid.UUID    `json:"buyer_id"`
+	SellerID      uuid.UUID    `json:"seller_id"`
+	CreatedAt     sql.NullTime `json:"created_at"`
+}
+
+type CollectionStat struct {
+	CollectionID     uuid.UUID       `json:"collection_id"`
+	Interval         string          `json:"interval"`
+	Bucket           time.Time       `json:"bucket"`
+	Open             sql.NullFloat64 `json:"open"`
+	High             sql.NullFloat64 `json:"high"`
+	Low              sql.NullFloat64 `json:"low"`
+	Close            sql.NullFloat64 `json:"close"`
+	Volume           float64         `json:"volume"`
+	Sales            int             `json:"sales"`
+	UniqueBuyers     int             `json:"unique_buyers"`
+	Floor            sql.NullFloat64 `json:"floor"`
+	ListedPercentage float64         `json:"listed_percentage"`
+	UpdatedAt        sql.NullTime    `json:"updated_at"`
+}
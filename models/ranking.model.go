@@ -0,0 +1,22 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Ranking struct {
+	Type             string          `json:"type"`
+	Window           string          `json:"window"`
+	Rank             int             `json:"rank"`
+	SubjectID        uuid.UUID       `json:"subject_id"`
+	Name             string          `json:"name"`
+	Image            string          `json:"image"`
+	Score            float64         `json:"score"`
+	Volume           float64         `json:"volume"`
+	Count            int             `json:"count"`
+	PreviousScore    float64         `json:"previous_score"`
+	ChangePercentage sql.NullFloat64 `json:"change_percentage"`
+	ComputedAt       sql.NullTime    `json:"computed_at"`
+}
@@ -8,7 +8,6 @@ import (
 
 type Rental struct {
 	ID              uuid.UUID    `json:"id"`
-	PreviousID      uuid.UUID    `json:"previous_id"`
 	UserID          uuid.UUID    `json:"user_id"`
 	User            User         `json:"user"`
 	OwnerID         uuid.UUID    `json:"owner_id"`
@@ -17,7 +16,13 @@ type Rental struct {
 	Token           Token        `json:"token"`
 	OwnershipID     uuid.UUID    `json:"ownership_id"`
 	Ownership       Ownership    `json:"ownership"`
+	Quantity        int          `json:"quantity"`
 	Timestamp       sql.NullTime `json:"timestamp"`
+	Days            int          `json:"days"`
+	Amount          float64      `json:"amount"`
+	RefundAmount    float64      `json:"refund_amount"`
+	StartedAt       sql.NullTime `json:"started_at"`
+	EndedAt         sql.NullTime `json:"ended_at"`
 	Status          string       `json:"status"`
 	TransactionHash string       `json:"transaction_hash"`
 	CreatedAt       sql.NullTime `json:"created_at"`
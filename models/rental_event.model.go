@@ -0,0 +1,21 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type RentalEvent struct {
+	ID           uuid.UUID    `json:"id"`
+	RentalID     uuid.UUID    `json:"rental_id"`
+	UserID       uuid.UUID    `json:"user_id"`
+	OwnerID      uuid.UUID    `json:"owner_id"`
+	TokenID      uuid.UUID    `json:"token_id"`
+	ActorID      uuid.UUID    `json:"actor_id"`
+	FromStatus   string       `json:"from_status"`
+	ToStatus     string       `json:"to_status"`
+	Note         string       `json:"note"`
+	DispatchedAt sql.NullTime `json:"dispatched_at"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+}
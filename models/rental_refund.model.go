@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type RentalRefund struct {
+	ID        uuid.UUID    `json:"id"`
+	RentalID  uuid.UUID    `json:"rental_id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	OwnerID   uuid.UUID    `json:"owner_id"`
+	Amount    float64      `json:"amount"`
+	Status    string       `json:"status"`
+	CreatedAt sql.NullTime `json:"created_at"`
+	UpdatedAt sql.NullTime `json:"updated_at"`
+}
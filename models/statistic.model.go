@@ -0,0 +1,11 @@
+package models
+
+import "github.com/google/uuid"
+
+type StatisticDiscrepancy struct {
+	Type    string    `json:"type"`
+	ID      uuid.UUID `json:"id"`
+	Field   string    `json:"field"`
+	Stored  float64   `json:"stored"`
+	Derived float64   `json:"derived"`
+}
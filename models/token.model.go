@@ -11,7 +11,6 @@ import (
 
 type Token struct {
 	ID                   uuid.UUID     `json:"id"`
-	PreviousID           uuid.UUID     `json:"previous_id"`
 	TokenIndex           int           `json:"token_index"`
 	Title                string        `json:"title"`
 	Description          string        `json:"description"`
@@ -31,9 +30,12 @@ type Token struct {
 	Views                int           `json:"views"`
 	NumberOfTransactions int           `json:"number_of_transactions"`
 	VolumeTransactions   float64       `json:"volume_transactions"`
+	FavoriteCount        int           `json:"favorite_count"`
 	CreatorID            uuid.UUID     `json:"creator_id"`
 	Creator              User          `json:"creator"`
 	Attributes           Attributes    `json:"attributes"`
+	Locked               bool          `json:"locked"`
+	ModerationStatus     string        `json:"moderation_status"`
 	Status               string        `json:"status"`
 	TransactionHash      string        `json:"transaction_hash"`
 	CreatedAt            sql.NullTime  `json:"created_at"`
@@ -8,7 +8,6 @@ import (
 
 type TokenCategory struct {
 	ID          uuid.UUID    `json:"id"`
-	PreviousID  uuid.UUID    `json:"previous_id"`
 	Title       string       `json:"title"`
 	Icon        string       `json:"icon"`
 	Description string       `json:"description"`
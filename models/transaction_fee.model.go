@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type TransactionFee struct {
+	ID              uuid.UUID    `json:"id"`
+	TransactionID   uuid.UUID    `json:"transaction_id"`
+	FractionID      uuid.UUID    `json:"fraction_id"`
+	FeeScheduleID   uuid.UUID    `json:"fee_schedule_id"`
+	TransactionType string       `json:"transaction_type"`
+	FeeType         string       `json:"fee_type"`
+	Value           float64      `json:"value"`
+	BaseAmount      float64      `json:"base_amount"`
+	Amount          float64      `json:"amount"`
+	RecipientID     uuid.UUID    `json:"recipient_id"`
+	Status          string       `json:"status"`
+	TransactionHash string       `json:"transaction_hash"`
+	CreatedAt       sql.NullTime `json:"created_at"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+}
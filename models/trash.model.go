@@ -0,0 +1,15 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeletedRecord struct {
+	Type      string          `json:"type"`
+	ID        uuid.UUID       `json:"id"`
+	Data      json.RawMessage `json:"data"`
+	DeletedAt time.Time       `json:"deleted_at"`
+}
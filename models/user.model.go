@@ -7,17 +7,18 @@ import (
 )
 
 type User struct {
-	ID         uuid.UUID    `json:"id"`
-	PreviousID uuid.UUID    `json:"previous_id"`
-	Name       string       `json:"name"`
-	Email      string       `json:"email"`
-	Photo      string       `json:"photo"`
-	Cover      string       `json:"cover"`
-	Verified   bool         `json:"verified"`
-	Role       string       `json:"role"`
-	Address    string       `json:"address"`
-	Nonce      string       `json:"nonce"`
-	Status     string       `json:"status"`
-	CreatedAt  sql.NullTime `json:"created_at"`
-	UpdatedAt  sql.NullTime `json:"updated_at"`
+	ID               uuid.UUID    `json:"id"`
+	Name             string       `json:"name"`
+	Email            string       `json:"email"`
+	Photo            string       `json:"photo"`
+	Cover            string       `json:"cover"`
+	Verified         bool         `json:"verified"`
+	EmailVerifiedAt  sql.NullTime `json:"email_verified_at"`
+	Role             string       `json:"role"`
+	Address          string       `json:"address"`
+	Nonce            string       `json:"nonce"`
+	ModerationStatus string       `json:"moderation_status"`
+	Status           string       `json:"status"`
+	CreatedAt        sql.NullTime `json:"created_at"`
+	UpdatedAt        sql.NullTime `json:"updated_at"`
 }
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type UserAddress struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	Address   string       `json:"address"`
+	Primary   bool         `json:"primary"`
+	CreatedAt sql.NullTime `json:"created_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingChange is a diff staged until its transaction is confirmed on-chain
+type PendingChange struct {
+	ID              uuid.UUID       `json:"id"`
+	ResourceType    string          `json:"resource_type"`
+	ResourceID      uuid.UUID       `json:"resource_id"`
+	Changes         json.RawMessage `json:"changes"`
+	TransactionHash string          `json:"transaction_hash"`
+	Status          string          `json:"status"`
+	AppliedAt       sql.NullTime    `json:"applied_at"`
+	UpdatedAt       sql.NullTime    `json:"updated_at"`
+	CreatedAt       sql.NullTime    `json:"created_at"`
+}
+
+// Typed diffs of the pending changes, nil fields are left unchanged
+type TokenChange struct {
+	FractionID *uuid.UUID `json:"fraction_id,omitempty"`
+	SourceID   *uuid.UUID `json:"source_id,omitempty"`
+}
+
+type OwnershipChange struct {
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	QuantityDelta    *int       `json:"quantity_delta,omitempty"`
+	SalePrice        *float64   `json:"sale_price,omitempty"`
+	RentCost         *float64   `json:"rent_cost,omitempty"`
+	AvailableForSale *bool      `json:"available_for_sale,omitempty"`
+	AvailableForRent *bool      `json:"available_for_rent,omitempty"`
+}
+
+type RentalChange struct {
+	Timestamp    *time.Time `json:"timestamp,omitempty"`
+	ExtendDays   *int       `json:"extend_days,omitempty"`
+	ExtendAmount *float64   `json:"extend_amount,omitempty"`
+	Status       *string    `json:"status,omitempty"`
+}
+
+type ResourceVersion struct {
+	ID              int64           `json:"id"`
+	ResourceType    string          `json:"resource_type"`
+	ResourceID      uuid.UUID       `json:"resource_id"`
+	Version         int             `json:"version"`
+	Data            json.RawMessage `json:"data"`
+	ChangeID        uuid.UUID       `json:"change_id"`
+	TransactionHash string          `json:"transaction_hash"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Trending struct {
+	Type       string       `json:"type"`
+	SubjectID  uuid.UUID    `json:"subject_id"`
+	Title      string       `json:"title"`
+	Image      string       `json:"image"`
+	Score      float64      `json:"score"`
+	Views      int          `json:"views"`
+	Sales      int          `json:"sales"`
+	ComputedAt sql.NullTime `json:"computed_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+type Watchlist struct {
+	ID           uuid.UUID       `json:"id"`
+	UserID       uuid.UUID       `json:"user_id"`
+	TokenID      uuid.UUID       `json:"token_id"`
+	CollectionID uuid.UUID       `json:"collection_id"`
+	NotifySale   bool            `json:"notify_sale"`
+	NotifyRent   bool            `json:"notify_rent"`
+	PriceBelow   sql.NullFloat64 `json:"price_below"`
+	NotifyFloor  bool            `json:"notify_floor"`
+	CreatedAt    sql.NullTime    `json:"created_at"`
+	UpdatedAt    sql.NullTime    `json:"updated_at"`
+}
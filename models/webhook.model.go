@@ -0,0 +1,46 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEndpoint struct {
+	ID         uuid.UUID    `json:"id"`
+	UserID     uuid.UUID    `json:"user_id"`
+	Url        string       `json:"url"`
+	Secret     string       `json:"secret,omitempty"`
+	EventTypes []string     `json:"event_types"`
+	Status     string       `json:"status"`
+	CreatedAt  sql.NullTime `json:"created_at"`
+	UpdatedAt  sql.NullTime `json:"updated_at"`
+}
+
+// WebhookEventData is everything a partner learns about an event, ids and amounts but never personal data
+type WebhookEventData struct {
+	ResourceID      uuid.UUID `json:"resource_id"`
+	TokenID         uuid.UUID `json:"token_id"`
+	CollectionID    uuid.UUID `json:"collection_id"`
+	UserFromID      uuid.UUID `json:"user_from_id"`
+	UserToID        uuid.UUID `json:"user_to_id"`
+	Quantity        int       `json:"quantity"`
+	Amount          float64   `json:"amount"`
+	TransactionHash string    `json:"transaction_hash"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	NextAttemptAt  sql.NullTime    `json:"next_attempt_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+}
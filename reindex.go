@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"metaedu-marketplace/cronjobs"
+)
+
+func runReindex(args []string) int {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	flags.Parse(args)
+
+	application := newApp("reindex")
+	defer application.Close()
+
+	cronjobs.Setup(application)
+	cronjobs.Reindex()
+
+	fmt.Println("Reindexed statistics, rankings and trending scores")
+
+	return 0
+}
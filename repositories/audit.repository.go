@@ -0,0 +1,264 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db}
+}
+
+// AuditHook is told about every change a repository makes to an audited row, with the row before and after
+type AuditHook func(ctx context.Context, table string, id uuid.UUID, action string, before []byte, after []byte)
+
+var auditHook AuditHook
+
+// SetAuditHook makes the repositories report their changes. Requests are audited by the middleware,
+// so only the worker sets it, for the changes it applies on its own.
+func SetAuditHook(hook AuditHook) {
+	auditHook = hook
+}
+
+// auditChange snapshots the row before a change and returns the func that reports it, meant to be deferred.
+// A failed change leaves the row as it was and is not reported.
+func auditChange(ctx context.Context, db *sql.DB, table string, id uuid.UUID, action string) func() {
+	if auditHook == nil {
+		return func() {}
+	}
+
+	before, err := getResourceSnapshot(ctx, db, table, id, false)
+
+	if err != nil {
+		fmt.Println("Error getting audit snapshot: ", table, ", id: ", id, ", error: ", err)
+	}
+
+	return func() {
+		// The job may be stopping, the change is committed and still has to be recorded
+		auditCtx, cancel := context.WithTimeout(helpers.WithoutCancel(ctx), helpers.AuditWriteTimeout)
+		defer cancel()
+
+		after, err := getResourceSnapshot(auditCtx, db, table, id, false)
+
+		if err != nil {
+			fmt.Println("Error getting audit snapshot: ", table, ", id: ", id, ", error: ", err)
+			return
+		}
+
+		auditHook(auditCtx, table, id, action, before, after)
+	}
+}
+
+// RecordChange is the audit hook of the worker, it appends changed rows to the same chain as the requests
+func (r *AuditRepository) RecordChange(ctx context.Context, table string, id uuid.UUID, action string, before []byte, after []byte) {
+	auditLog := models.AuditLog{
+		Action:       action,
+		Method:       helpers.AuditMethodWorker,
+		ResourceType: helpers.GetAuditResourceType(table),
+		ResourceID:   id.String(),
+	}
+
+	// Nothing changed, or there is no such row
+	if len(before) == 0 && len(after) == 0 {
+		return
+	}
+
+	auditLog.Before, auditLog.After = helpers.DiffAuditSnapshots(before, after)
+
+	if string(auditLog.After) == "{}" {
+		return
+	}
+
+	_, err := r.InsertAuditLog(ctx, auditLog)
+
+	if err != nil {
+		fmt.Println("Error recording audit log: ", action, ", id: ", id, ", error: ", err)
+	}
+}
+
+// GetResourceSnapshot reads a row as JSON, with pending the latest staged change is merged onto the row
+func (r *AuditRepository) GetResourceSnapshot(ctx context.Context, table string, id uuid.UUID, pending bool) ([]byte, error) {
+	return getResourceSnapshot(ctx, r.db, table, id, pending)
+}
+
+func getResourceSnapshot(ctx context.Context, db *sql.DB, table string, id uuid.UUID, pending bool) ([]byte, error) {
+	var snapshot []byte
+
+	sqlStatement := `SELECT row_to_json(t) FROM ` + table + ` t WHERE t.id = $1`
+
+	if pending && helpers.AuditPendingTables[table] {
+		sqlStatement = `SELECT (row_to_json(t)::JSONB || COALESCE((SELECT pending_changes.changes FROM pending_changes
+						WHERE pending_changes.resource_id = t.id AND pending_changes.status = 'pending'
+						ORDER BY pending_changes.created_at DESC LIMIT 1), '{}'::JSONB))::JSON
+						FROM ` + table + ` t WHERE t.id = $1`
+	}
+
+	err := db.QueryRowContext(ctx, sqlStatement, id).Scan(&snapshot)
+
+	if err != nil && err != sql.ErrNoRows {
+		return snapshot, err
+	}
+
+	return snapshot, nil
+}
+
+// InsertAuditLog appends an entry to the hash chain, the lock keeps concurrent requests from forking it
+func (r *AuditRepository) InsertAuditLog(ctx context.Context, auditLog models.AuditLog) (models.AuditLog, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return auditLog, err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('audit_logs'))`)
+
+	if err != nil {
+		return auditLog, err
+	}
+
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_logs ORDER BY id DESC LIMIT 1`).Scan(&auditLog.PreviousHash)
+
+	if err != nil && err != sql.ErrNoRows {
+		return auditLog, err
+	}
+
+	auditLog.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	auditLog.Hash = helpers.ComputeAuditHash(auditLog)
+
+	sqlStatement := `INSERT INTO audit_logs (
+		actor_id,
+		action,
+		method,
+		path,
+		resource_type,
+		resource_id,
+		before,
+		after,
+		request_id,
+		ip,
+		user_agent,
+		status_code,
+		previous_hash,
+		hash,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+	  )
+	  RETURNING id`
+
+	err = tx.QueryRowContext(ctx, sqlStatement, helpers.GetNullableUUIDParams(auditLog.ActorID), auditLog.Action, auditLog.Method, auditLog.Path, auditLog.ResourceType, auditLog.ResourceID, []byte(auditLog.Before), []byte(auditLog.After), auditLog.RequestID, auditLog.IP, auditLog.UserAgent, auditLog.StatusCode, auditLog.PreviousHash, auditLog.Hash, auditLog.CreatedAt).Scan(&auditLog.ID)
+
+	if err != nil {
+		return auditLog, err
+	}
+
+	return auditLog, tx.Commit()
+}
+
+const auditLogColumns = `id, actor_id, action, method, path, resource_type, resource_id, before, after, request_id, ip, user_agent, status_code, previous_hash, hash, created_at`
+
+func scanAuditLog(rows interface{ Scan(...any) error }) (models.AuditLog, error) {
+	var auditLog models.AuditLog
+	var before []byte
+	var after []byte
+
+	err := rows.Scan(&auditLog.ID, &auditLog.ActorID, &auditLog.Action, &auditLog.Method, &auditLog.Path, &auditLog.ResourceType, &auditLog.ResourceID, &before, &after, &auditLog.RequestID, &auditLog.IP, &auditLog.UserAgent, &auditLog.StatusCode, &auditLog.PreviousHash, &auditLog.Hash, &auditLog.CreatedAt)
+
+	auditLog.Before = before
+	auditLog.After = after
+
+	return auditLog, err
+}
+
+func (r *AuditRepository) GetAuditLogList(ctx context.Context, offset int, limit int, actorID *uuid.UUID, resourceType *string, resourceID *string, requestID *string, from *time.Time, to *time.Time) ([]models.AuditLog, error) {
+	var auditLogs []models.AuditLog
+
+	sqlStatement := `SELECT ` + auditLogColumns + `
+					FROM audit_logs
+					WHERE (actor_id = $1 OR $1 IS NULL) AND (resource_type = $2 OR $2 IS NULL) AND (resource_id = $3 OR $3 IS NULL) AND (request_id = $4 OR $4 IS NULL)
+						AND (created_at >= $5 OR $5 IS NULL) AND (created_at < $6 OR $6 IS NULL)
+					ORDER BY id DESC
+					OFFSET $7
+					LIMIT $8`
+
+	var fromParams interface{}
+	var toParams interface{}
+
+	if from != nil {
+		fromParams = *from
+	}
+
+	if to != nil {
+		toParams = *to
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(actorID), helpers.GetOptionalStringParams(resourceType), helpers.GetOptionalStringParams(resourceID), helpers.GetOptionalStringParams(requestID), fromParams, toParams, offset, limit)
+
+	if err != nil {
+		return auditLogs, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		auditLog, err := scanAuditLog(rows)
+
+		if err != nil {
+			return auditLogs, err
+		}
+
+		auditLogs = append(auditLogs, auditLog)
+	}
+
+	return auditLogs, nil
+}
+
+func (r *AuditRepository) GetAuditLogData(ctx context.Context, id int64) (models.AuditLog, error) {
+	return scanAuditLog(r.db.QueryRowContext(ctx, `SELECT `+auditLogColumns+` FROM audit_logs WHERE id = $1`, id))
+}
+
+// VerifyAuditChain recomputes every hash in order and stops at the first entry that does not match
+func (r *AuditRepository) VerifyAuditChain(ctx context.Context) (models.AuditChainVerification, error) {
+	verification := models.AuditChainVerification{Valid: true}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+auditLogColumns+` FROM audit_logs ORDER BY id ASC`)
+
+	if err != nil {
+		return verification, err
+	}
+
+	defer rows.Close()
+
+	previousHash := ""
+
+	for rows.Next() {
+		auditLog, err := scanAuditLog(rows)
+
+		if err != nil {
+			return verification, err
+		}
+
+		verification.Checked++
+
+		if auditLog.PreviousHash != previousHash || helpers.ComputeAuditHash(auditLog) != auditLog.Hash {
+			verification.Valid = false
+			verification.BrokenID = auditLog.ID
+			return verification, nil
+		}
+
+		previousHash = auditLog.Hash
+	}
+
+	return verification, rows.Err()
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
@@ -16,9 +17,8 @@ func NewCollectionRepository(db *sql.DB) *CollectionRepository {
 	return &CollectionRepository{db}
 }
 
-func (r *CollectionRepository) InsertCollection(collection models.Collection) (string, error) {
+func (r *CollectionRepository) InsertCollection(ctx context.Context, collection models.Collection) (string, error) {
 	sqlStatement := `INSERT INTO collections (
-		previous_id,
 		thumbnail,
 		cover,
 		title,
@@ -26,6 +26,7 @@ func (r *CollectionRepository) InsertCollection(collection models.Collection) (s
 		number_of_items,
 		number_of_transactions,
 		volume_transactions,
+		floor,
 		description,
 		category_id,
 		creator_id,
@@ -40,7 +41,7 @@ func (r *CollectionRepository) InsertCollection(collection models.Collection) (s
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement, collection.PreviousID, collection.Thumbnail, collection.Cover, collection.Title, collection.Views, collection.NumberOfItems, collection.NumberOfTransactions, collection.VolumeTransactions, collection.Description, collection.CategoryID, collection.CreatorID, collection.Status, collection.TransactionHash, collection.UpdatedAt, collection.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStatement, collection.Thumbnail, collection.Cover, collection.Title, collection.Views, collection.NumberOfItems, collection.NumberOfTransactions, collection.VolumeTransactions, collection.Floor, collection.Description, helpers.GetNullableUUIDParams(collection.CategoryID), collection.CreatorID, collection.Status, collection.TransactionHash, collection.UpdatedAt, collection.CreatedAt).Scan(&id)
 
 	if err != nil {
 		return id, err
@@ -49,21 +50,22 @@ func (r *CollectionRepository) InsertCollection(collection models.Collection) (s
 	return id, nil
 }
 
-func (r *CollectionRepository) GetCollectionList(offset int, limit int, keyword string, creatorID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Collection, error) {
+func (r *CollectionRepository) GetCollectionList(ctx context.Context, offset int, limit int, keyword string, creatorID *uuid.UUID, status *string, visibleOnly bool, orderBy string, orderOption string) ([]models.Collection, error) {
 	var collections []models.Collection
 
-	sqlStatement := `SELECT collections.id, collections.previous_id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.description, collections.creator_id, collections.category_id, collections.status, collections.transaction_hash, collections.updated_at, collections.created_at,
+	sqlStatement := `SELECT collections.id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.floor, collections.favorite_count, collections.description, collections.creator_id, collections.category_id, collections.moderation_status, collections.status, collections.transaction_hash, collections.updated_at, collections.created_at,
 						users.id, users.name, users.email, users.photo, users.role, users.address,					
 						token_categories.id, token_categories.title, token_categories.description, token_categories.icon, token_categories.updated_at, token_categories.created_at
 					FROM collections 
 					INNER JOIN users ON users.id = collections.creator_id
 					INNER JOIN token_categories ON token_categories.id = collections.category_id
-					WHERE collections.title LIKE '%' || $1 || '%' AND (collections.creator_id = $2 OR $2 IS NULL) AND (collections.status = $3 OR $3 IS NULL)
+					WHERE collections.title LIKE '%' || $1 || '%' AND (collections.creator_id = $2 OR $2 IS NULL) AND (collections.status = $3 OR $3 IS NULL) AND collections.deleted_at IS NULL
+						AND ($6 = false OR ` + visibleCollectionStatement + `)
 					ORDER BY collections.` + orderBy + ` ` + orderOption + ` 
 					OFFSET $4 
 					LIMIT $5`
 
-	rows, err := r.db.Query(sqlStatement, keyword, helpers.GetOptionalUUIDParams(creatorID), status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, keyword, helpers.GetOptionalUUIDParams(creatorID), status, offset, limit, visibleOnly)
 
 	if err != nil {
 		return collections, err
@@ -72,7 +74,7 @@ func (r *CollectionRepository) GetCollectionList(offset int, limit int, keyword
 	defer rows.Close()
 	for rows.Next() {
 		var collection models.Collection
-		err = rows.Scan(&collection.ID, &collection.PreviousID, &collection.Thumbnail, &collection.Cover, &collection.Title, &collection.Views, &collection.NumberOfItems, &collection.NumberOfTransactions, &collection.VolumeTransactions, &collection.Description, &collection.CreatorID, &collection.CategoryID, &collection.Status, &collection.TransactionHash, &collection.UpdatedAt, &collection.CreatedAt,
+		err = rows.Scan(&collection.ID, &collection.Thumbnail, &collection.Cover, &collection.Title, &collection.Views, &collection.NumberOfItems, &collection.NumberOfTransactions, &collection.VolumeTransactions, &collection.Floor, &collection.FavoriteCount, &collection.Description, &collection.CreatorID, &collection.CategoryID, &collection.ModerationStatus, &collection.Status, &collection.TransactionHash, &collection.UpdatedAt, &collection.CreatedAt,
 			&collection.Creator.ID, &collection.Creator.Name, &collection.Creator.Email, &collection.Creator.Photo, &collection.Creator.Role, &collection.Creator.Address,
 			&collection.Category.ID, &collection.Category.Title, &collection.Category.Description, &collection.Category.Icon, &collection.Category.UpdatedAt, &collection.Category.CreatedAt)
 		if err != nil {
@@ -85,15 +87,15 @@ func (r *CollectionRepository) GetCollectionList(offset int, limit int, keyword
 	return collections, nil
 }
 
-func (r *CollectionRepository) GetCollectionData(id uuid.UUID) (models.Collection, error) {
-	sqlStatement := `SELECT collections.id, collections.previous_id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.description, collections.creator_id, collections.category_id, collections.status, collections.transaction_hash, collections.updated_at, collections.created_at,
-					users.id, users.name, users.email, users.photo, users.role, users.address	
+func (r *CollectionRepository) GetCollectionData(ctx context.Context, id uuid.UUID) (models.Collection, error) {
+	sqlStatement := `SELECT collections.id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.floor, collections.favorite_count, collections.description, collections.creator_id, collections.category_id, collections.moderation_status, collections.status, collections.transaction_hash, collections.updated_at, collections.created_at,
+					users.id, users.name, users.email, users.photo, users.role, users.address, users.moderation_status
 					FROM collections 
 					INNER JOIN users ON collections.creator_id = users.id
-					WHERE collections.id = $1`
+					WHERE collections.id = $1 AND collections.deleted_at IS NULL`
 
 	var collection models.Collection
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return collection, err
@@ -102,8 +104,8 @@ func (r *CollectionRepository) GetCollectionData(id uuid.UUID) (models.Collectio
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&collection.ID, &collection.PreviousID, &collection.Thumbnail, &collection.Cover, &collection.Title, &collection.Views, &collection.NumberOfItems, &collection.NumberOfTransactions, &collection.VolumeTransactions, &collection.Description, &collection.CreatorID, &collection.CategoryID, &collection.Status, &collection.TransactionHash, &collection.UpdatedAt, &collection.CreatedAt,
-			&collection.Creator.ID, &collection.Creator.Name, &collection.Creator.Email, &collection.Creator.Photo, &collection.Creator.Role, &collection.Creator.Address)
+		err = rows.Scan(&collection.ID, &collection.Thumbnail, &collection.Cover, &collection.Title, &collection.Views, &collection.NumberOfItems, &collection.NumberOfTransactions, &collection.VolumeTransactions, &collection.Floor, &collection.FavoriteCount, &collection.Description, &collection.CreatorID, &collection.CategoryID, &collection.ModerationStatus, &collection.Status, &collection.TransactionHash, &collection.UpdatedAt, &collection.CreatedAt,
+			&collection.Creator.ID, &collection.Creator.Name, &collection.Creator.Email, &collection.Creator.Photo, &collection.Creator.Role, &collection.Creator.Address, &collection.Creator.ModerationStatus)
 
 		if err != nil {
 			return collection, err
@@ -113,40 +115,15 @@ func (r *CollectionRepository) GetCollectionData(id uuid.UUID) (models.Collectio
 	return collection, nil
 }
 
-func (r *CollectionRepository) GetPendingCollectionData(previousID uuid.UUID) (models.Collection, error) {
-	sqlStatement := `SELECT collections.id, collections.previous_id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.description, collections.creator_id, collections.category_id, collections.status, collections.transaction_hash, collections.updated_at, collections.created_at,
-					users.id, users.name, users.email, users.photo, users.role, users.address	
-					FROM collections 
-					INNER JOIN users ON collections.creator_id = users.id
-					WHERE collections.previous_id = $1`
-
-	var collection models.Collection
-	rows, err := r.db.Query(sqlStatement, previousID)
+func (r *CollectionRepository) UpdateCollection(ctx context.Context, id uuid.UUID, collection models.Collection) error {
+	defer auditChange(ctx, r.db, "collections", id, "UpdateCollection")()
 
-	if err != nil {
-		return collection, err
-	}
-
-	defer rows.Close()
-
-	for rows.Next() {
-		err = rows.Scan(&collection.ID, &collection.PreviousID, &collection.Thumbnail, &collection.Cover, &collection.Title, &collection.Views, &collection.NumberOfItems, &collection.NumberOfTransactions, &collection.VolumeTransactions, &collection.Description, &collection.CreatorID, &collection.CategoryID, &collection.Status, &collection.TransactionHash, &collection.UpdatedAt, &collection.CreatedAt,
-			&collection.Creator.ID, &collection.Creator.Name, &collection.Creator.Email, &collection.Creator.Photo, &collection.Creator.Role, &collection.Creator.Address)
-
-		if err != nil {
-			return collection, err
-		}
-	}
-
-	return collection, nil
-}
-
-func (r *CollectionRepository) UpdateCollection(id uuid.UUID, collection models.Collection) error {
+	// Views and the derived statistics are not written here, see the view and statistic repositories
 	sqlStatement := `UPDATE collections
-	SET thumbnail = $2, cover = $3, title = $4, views = $5, number_of_items = $6, number_of_transactions = $7, volume_transactions = $8, description = $9, category_id = $10, creator_id = $11, status = $12, transaction_hash = $13, updated_at = $14
+	SET thumbnail = $2, cover = $3, title = $4, description = $5, category_id = $6, creator_id = $7, status = $8, transaction_hash = $9, updated_at = $10
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, collection.Thumbnail, collection.Cover, collection.Title, collection.Views, collection.NumberOfItems, collection.NumberOfTransactions, collection.VolumeTransactions, collection.Description, collection.CategoryID, collection.CreatorID, collection.Status, collection.TransactionHash, collection.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, collection.Thumbnail, collection.Cover, collection.Title, collection.Description, helpers.GetNullableUUIDParams(collection.CategoryID), collection.CreatorID, collection.Status, collection.TransactionHash, collection.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -155,10 +132,12 @@ func (r *CollectionRepository) UpdateCollection(id uuid.UUID, collection models.
 	return nil
 }
 
-func (r *CollectionRepository) DeleteCollection(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM collections WHERE id = $1`
+func (r *CollectionRepository) DeleteCollection(ctx context.Context, id uuid.UUID) error {
+	defer auditChange(ctx, r.db, "collections", id, "DeleteCollection")()
+
+	sqlStatement := `UPDATE collections SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
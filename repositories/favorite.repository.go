@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type FavoriteRepository struct {
+	db *sql.DB
+}
+
+func NewFavoriteRepository(db *sql.DB) *FavoriteRepository {
+	return &FavoriteRepository{db}
+}
+
+// Insert the favorite and bump the counter of its target in a single transaction
+func (r *FavoriteRepository) InsertFavorite(ctx context.Context, favorite models.Favorite) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO favorites (user_id, token_id, collection_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		favorite.UserID, helpers.GetNullableUUIDParams(favorite.TokenID), helpers.GetNullableUUIDParams(favorite.CollectionID))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	err = updateFavoriteCount(ctx, tx, favorite, 1)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *FavoriteRepository) DeleteFavorite(ctx context.Context, favorite models.Favorite) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM favorites WHERE user_id = $1 AND (token_id = $2 OR $2 IS NULL) AND (collection_id = $3 OR $3 IS NULL)`,
+		favorite.UserID, helpers.GetNullableUUIDParams(favorite.TokenID), helpers.GetNullableUUIDParams(favorite.CollectionID))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	err = updateFavoriteCount(ctx, tx, favorite, -1)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func updateFavoriteCount(ctx context.Context, tx *sql.Tx, favorite models.Favorite, delta int) error {
+	var err error
+
+	if favorite.TokenID != helpers.GetEmptyUUID() {
+		_, err = tx.ExecContext(ctx, `UPDATE tokens SET favorite_count = GREATEST(favorite_count + $2, 0) WHERE id = $1`, favorite.TokenID, delta)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE collections SET favorite_count = GREATEST(favorite_count + $2, 0) WHERE id = $1`, favorite.CollectionID, delta)
+	}
+
+	return err
+}
+
+func (r *FavoriteRepository) GetFavoriteList(ctx context.Context, offset int, limit int, userID uuid.UUID, favoriteType *string) ([]models.Favorite, error) {
+	var favorites []models.Favorite
+
+	sqlStatement := `SELECT favorites.id, favorites.user_id, favorites.token_id, favorites.collection_id, favorites.created_at,
+						COALESCE(tokens.title, ''), COALESCE(tokens.image, ''), COALESCE(tokens.last_price, 0), COALESCE(tokens.favorite_count, 0),
+						collections.thumbnail, collections.title, collections.floor, collections.favorite_count
+					FROM favorites
+					LEFT JOIN tokens ON tokens.id = favorites.token_id
+					LEFT JOIN collections ON collections.id = favorites.collection_id
+					WHERE favorites.user_id = $1 AND (tokens.id IS NULL OR ` + visibleTokenStatement + `) AND (collections.id IS NULL OR ` + visibleCollectionStatement + `) AND ($2::VARCHAR IS NULL OR ($2 = 'token' AND favorites.token_id IS NOT NULL) OR ($2 = 'collection' AND favorites.collection_id IS NOT NULL))
+					ORDER BY favorites.created_at DESC
+					OFFSET $3
+					LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, userID, helpers.GetOptionalStringParams(favoriteType), offset, limit)
+
+	if err != nil {
+		return favorites, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var favorite models.Favorite
+		err = rows.Scan(&favorite.ID, &favorite.UserID, &favorite.TokenID, &favorite.CollectionID, &favorite.CreatedAt,
+			&favorite.Token.Title, &favorite.Token.Image, &favorite.Token.LastPrice, &favorite.Token.FavoriteCount,
+			&favorite.Collection.Thumbnail, &favorite.Collection.Title, &favorite.Collection.Floor, &favorite.Collection.FavoriteCount)
+
+		if err != nil {
+			return favorites, err
+		}
+
+		favorite.Token.ID = favorite.TokenID
+		favorite.Collection.ID = favorite.CollectionID
+
+		favorites = append(favorites, favorite)
+	}
+
+	return favorites, nil
+}
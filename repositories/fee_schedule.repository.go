@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FeeScheduleRepository struct {
+	db *sql.DB
+}
+
+func NewFeeScheduleRepository(db *sql.DB) *FeeScheduleRepository {
+	return &FeeScheduleRepository{db}
+}
+
+func (r *FeeScheduleRepository) InsertFeeSchedule(ctx context.Context, feeSchedule models.FeeSchedule) (string, error) {
+	sqlStatement := `INSERT INTO fee_schedules (
+		title,
+		transaction_type,
+		fee_type,
+		value,
+		category_id,
+		collection_id,
+		recipient_id,
+		promotion,
+		starts_at,
+		ends_at,
+		status,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, feeSchedule.Title, feeSchedule.TransactionType, feeSchedule.FeeType, feeSchedule.Value, helpers.GetNullableUUIDParams(feeSchedule.CategoryID), helpers.GetNullableUUIDParams(feeSchedule.CollectionID), helpers.GetNullableUUIDParams(feeSchedule.RecipientID), feeSchedule.Promotion, feeSchedule.StartsAt, feeSchedule.EndsAt, feeSchedule.Status, feeSchedule.UpdatedAt, feeSchedule.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *FeeScheduleRepository) GetFeeScheduleList(ctx context.Context, offset int, limit int, transactionType *string, categoryID *uuid.UUID, collectionID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.FeeSchedule, error) {
+	var feeSchedules []models.FeeSchedule
+
+	sqlStatement := `SELECT id, title, transaction_type, fee_type, value, category_id, collection_id, recipient_id, promotion, starts_at, ends_at, status, updated_at, created_at
+					FROM fee_schedules
+					WHERE (transaction_type = $1 OR $1 IS NULL) AND (category_id = $2 OR $2 IS NULL) AND (collection_id = $3 OR $3 IS NULL) AND (status = $4 OR $4 IS NULL)
+					ORDER BY ` + orderBy + ` ` + orderOption + `
+					OFFSET $5
+					LIMIT $6`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalStringParams(transactionType), helpers.GetOptionalUUIDParams(categoryID), helpers.GetOptionalUUIDParams(collectionID), helpers.GetOptionalStringParams(status), offset, limit)
+
+	if err != nil {
+		return feeSchedules, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var feeSchedule models.FeeSchedule
+		err = rows.Scan(&feeSchedule.ID, &feeSchedule.Title, &feeSchedule.TransactionType, &feeSchedule.FeeType, &feeSchedule.Value, &feeSchedule.CategoryID, &feeSchedule.CollectionID, &feeSchedule.RecipientID, &feeSchedule.Promotion, &feeSchedule.StartsAt, &feeSchedule.EndsAt, &feeSchedule.Status, &feeSchedule.UpdatedAt, &feeSchedule.CreatedAt)
+
+		if err != nil {
+			return feeSchedules, err
+		}
+
+		feeSchedules = append(feeSchedules, feeSchedule)
+	}
+
+	return feeSchedules, nil
+}
+
+func (r *FeeScheduleRepository) GetApplicableFeeScheduleList(ctx context.Context, transactionType string, categoryID uuid.UUID, collectionID uuid.UUID, at time.Time) ([]models.FeeSchedule, error) {
+	var feeSchedules []models.FeeSchedule
+
+	sqlStatement := `SELECT id, title, transaction_type, fee_type, value, category_id, collection_id, recipient_id, promotion, starts_at, ends_at, status, updated_at, created_at
+					FROM fee_schedules
+					WHERE transaction_type = $1 AND status = 'active'
+					AND (category_id IS NULL OR category_id = $2)
+					AND (collection_id IS NULL OR collection_id = $3)
+					AND (starts_at IS NULL OR starts_at <= $4)
+					AND (ends_at IS NULL OR ends_at > $4)
+					ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, transactionType, categoryID, collectionID, at)
+
+	if err != nil {
+		return feeSchedules, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var feeSchedule models.FeeSchedule
+		err = rows.Scan(&feeSchedule.ID, &feeSchedule.Title, &feeSchedule.TransactionType, &feeSchedule.FeeType, &feeSchedule.Value, &feeSchedule.CategoryID, &feeSchedule.CollectionID, &feeSchedule.RecipientID, &feeSchedule.Promotion, &feeSchedule.StartsAt, &feeSchedule.EndsAt, &feeSchedule.Status, &feeSchedule.UpdatedAt, &feeSchedule.CreatedAt)
+
+		if err != nil {
+			return feeSchedules, err
+		}
+
+		feeSchedules = append(feeSchedules, feeSchedule)
+	}
+
+	return feeSchedules, nil
+}
+
+func (r *FeeScheduleRepository) GetFeeScheduleData(ctx context.Context, id uuid.UUID) (models.FeeSchedule, error) {
+	sqlStatement := `SELECT id, title, transaction_type, fee_type, value, category_id, collection_id, recipient_id, promotion, starts_at, ends_at, status, updated_at, created_at FROM fee_schedules WHERE id = $1`
+
+	var feeSchedule models.FeeSchedule
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return feeSchedule, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&feeSchedule.ID, &feeSchedule.Title, &feeSchedule.TransactionType, &feeSchedule.FeeType, &feeSchedule.Value, &feeSchedule.CategoryID, &feeSchedule.CollectionID, &feeSchedule.RecipientID, &feeSchedule.Promotion, &feeSchedule.StartsAt, &feeSchedule.EndsAt, &feeSchedule.Status, &feeSchedule.UpdatedAt, &feeSchedule.CreatedAt)
+
+		if err != nil {
+			return feeSchedule, err
+		}
+	}
+
+	return feeSchedule, nil
+}
+
+func (r *FeeScheduleRepository) UpdateFeeSchedule(ctx context.Context, id uuid.UUID, feeSchedule models.FeeSchedule) error {
+	sqlStatement := `UPDATE fee_schedules
+	SET title = $2, transaction_type = $3, fee_type = $4, value = $5, category_id = $6, collection_id = $7, recipient_id = $8, promotion = $9, starts_at = $10, ends_at = $11, status = $12, updated_at = $13
+	WHERE id = $1;`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, feeSchedule.Title, feeSchedule.TransactionType, feeSchedule.FeeType, feeSchedule.Value, helpers.GetNullableUUIDParams(feeSchedule.CategoryID), helpers.GetNullableUUIDParams(feeSchedule.CollectionID), helpers.GetNullableUUIDParams(feeSchedule.RecipientID), feeSchedule.Promotion, feeSchedule.StartsAt, feeSchedule.EndsAt, feeSchedule.Status, feeSchedule.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *FeeScheduleRepository) DeleteFeeSchedule(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `DELETE FROM fee_schedules WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
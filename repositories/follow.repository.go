@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type FollowRepository struct {
+	db *sql.DB
+}
+
+func NewFollowRepository(db *sql.DB) *FollowRepository {
+	return &FollowRepository{db}
+}
+
+func (r *FollowRepository) InsertFollow(ctx context.Context, follow models.Follow) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO follows (follower_id, user_id, collection_id) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+		follow.FollowerID, helpers.GetNullableUUIDParams(follow.UserID), helpers.GetNullableUUIDParams(follow.CollectionID))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *FollowRepository) DeleteFollow(ctx context.Context, follow models.Follow) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM follows WHERE follower_id = $1 AND (user_id = $2 OR $2 IS NULL) AND (collection_id = $3 OR $3 IS NULL)`,
+		follow.FollowerID, helpers.GetNullableUUIDParams(follow.UserID), helpers.GetNullableUUIDParams(follow.CollectionID))
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (r *FollowRepository) GetFollowList(ctx context.Context, offset int, limit int, followerID uuid.UUID) ([]models.Follow, error) {
+	var follows []models.Follow
+
+	sqlStatement := `SELECT follows.id, follows.follower_id, follows.user_id, follows.collection_id, follows.created_at,
+						COALESCE(users.name, ''), COALESCE(users.photo, ''), COALESCE(users.address, ''),
+						collections.thumbnail, collections.title
+					FROM follows
+					LEFT JOIN users ON users.id = follows.user_id
+					LEFT JOIN collections ON collections.id = follows.collection_id
+					WHERE follows.follower_id = $1
+					ORDER BY follows.created_at DESC
+					OFFSET $2
+					LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, followerID, offset, limit)
+
+	if err != nil {
+		return follows, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var follow models.Follow
+		err = rows.Scan(&follow.ID, &follow.FollowerID, &follow.UserID, &follow.CollectionID, &follow.CreatedAt,
+			&follow.User.Name, &follow.User.Photo, &follow.User.Address,
+			&follow.Collection.Thumbnail, &follow.Collection.Title)
+
+		if err != nil {
+			return follows, err
+		}
+
+		follow.User.ID = follow.UserID
+		follow.Collection.ID = follow.CollectionID
+
+		follows = append(follows, follow)
+	}
+
+	return follows, nil
+}
+
+// Assemble the feed of a follower on read from the tokens, ownerships and transactions tables
+func (r *FollowRepository) GetFeed(ctx context.Context, offset int, limit int, followerID uuid.UUID) ([]models.FeedItem, error) {
+	var feedItems []models.FeedItem
+
+	sqlStatement := `WITH followed_users AS (
+						SELECT user_id FROM follows WHERE follower_id = $1 AND user_id IS NOT NULL
+					), followed_collections AS (
+						SELECT collection_id FROM follows WHERE follower_id = $1 AND collection_id IS NOT NULL
+					)
+					SELECT feed.type, feed.reference_id, feed.token_id, feed.title, feed.image, feed.collection_id, feed.actor_id, feed.price, feed.created_at
+					FROM (
+						SELECT 'mint' AS type, tokens.id AS reference_id, tokens.id AS token_id, tokens.title, tokens.image, tokens.collection_id, tokens.creator_id AS actor_id, tokens.initial_price AS price, tokens.created_at
+						FROM tokens
+						WHERE tokens.status IN ('active', 'lazy') AND tokens.creator_id IN (SELECT user_id FROM followed_users) AND ` + visibleTokenStatement + `
+						UNION ALL
+						SELECT 'listing', ownerships.id, tokens.id, tokens.title, tokens.image, tokens.collection_id, ownerships.user_id,
+							CASE WHEN ownerships.available_for_sale THEN ownerships.sale_price ELSE ownerships.rent_cost END, ownerships.updated_at
+						FROM ownerships
+						INNER JOIN tokens ON tokens.id = ownerships.token_id
+						WHERE ownerships.status = 'active' AND (ownerships.available_for_sale OR ownerships.available_for_rent) AND ownerships.user_id <> $1 AND ownerships.deleted_at IS NULL
+							AND tokens.collection_id IN (SELECT collection_id FROM followed_collections) AND ` + visibleTokenStatement + `
+						UNION ALL
+						SELECT 'sale', transactions.id, tokens.id, tokens.title, tokens.image, tokens.collection_id, transactions.user_from_id, transactions.amount, transactions.created_at
+						FROM transactions
+						INNER JOIN tokens ON tokens.id = transactions.token_id
+						WHERE transactions.type = 'purchase' AND transactions.status = 'active' AND transactions.deleted_at IS NULL
+							AND (tokens.collection_id IN (SELECT collection_id FROM followed_collections) OR tokens.creator_id IN (SELECT user_id FROM followed_users)) AND ` + visibleTokenStatement + `
+					) feed
+					ORDER BY feed.created_at DESC
+					OFFSET $2
+					LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, followerID, offset, limit)
+
+	if err != nil {
+		return feedItems, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var feedItem models.FeedItem
+		err = rows.Scan(&feedItem.Type, &feedItem.ReferenceID, &feedItem.TokenID, &feedItem.Token.Title, &feedItem.Token.Image, &feedItem.CollectionID, &feedItem.ActorID, &feedItem.Price, &feedItem.CreatedAt)
+
+		if err != nil {
+			return feedItems, err
+		}
+
+		feedItem.Token.ID = feedItem.TokenID
+		feedItem.Token.CollectionID = feedItem.CollectionID
+
+		feedItems = append(feedItems, feedItem)
+	}
+
+	return feedItems, nil
+}
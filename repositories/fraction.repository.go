@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
@@ -16,23 +17,27 @@ func NewFractionRepository(db *sql.DB) *FractionRepository {
 	return &FractionRepository{db}
 }
 
-func (r *FractionRepository) InsertFraction(fraction models.Fraction) (string, error) {
+func (r *FractionRepository) InsertFraction(ctx context.Context, fraction models.Fraction) (string, error) {
 	sqlStatement := `INSERT INTO fractions (
-		previous_id,
 		token_parent_id,
 		token_fraction_id,
+		owner_id,
+		ownership_id,
+		total_shares,
+		share_price,
+		reserve_price,
 		status,
 		transaction_hash,
 		updated_at,
 		created_at
 	  ) VALUES (
-		$1, $2, $3, $4, $5, $6, $7
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 	  )
 	  RETURNING id`
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement, fraction.PreviousID, fraction.TokenParentID, fraction.TokenFractionID, fraction.Status, fraction.TransactionHash, fraction.UpdatedAt, fraction.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStatement, fraction.TokenParentID, fraction.TokenFractionID, helpers.GetNullableUUIDParams(fraction.OwnerID), helpers.GetNullableUUIDParams(fraction.OwnershipID), fraction.TotalShares, fraction.SharePrice, fraction.ReservePrice, fraction.Status, fraction.TransactionHash, fraction.UpdatedAt, fraction.CreatedAt).Scan(&id)
 
 	if err != nil {
 		return id, err
@@ -41,19 +46,19 @@ func (r *FractionRepository) InsertFraction(fraction models.Fraction) (string, e
 	return id, nil
 }
 
-func (r *FractionRepository) GetFractionList(offset int, limit int, keyword string, creatorID *uuid.UUID, creator *string, status *string, orderBy string, orderOption string) ([]models.Fraction, error) {
+func (r *FractionRepository) GetFractionList(ctx context.Context, offset int, limit int, keyword string, creatorID *uuid.UUID, creator *string, status *string, orderBy string, orderOption string) ([]models.Fraction, error) {
 	var fractions []models.Fraction
 
-	sqlStatement := `SELECT fractions.id, fractions.previous_id, fractions.token_parent_id, fractions.token_fraction_id, fractions.status, fractions.transaction_hash, fractions.updated_at, fractions.created_at 
+	sqlStatement := `SELECT fractions.id, fractions.token_parent_id, fractions.token_fraction_id, fractions.owner_id, fractions.ownership_id, fractions.total_shares, fractions.share_price, fractions.reserve_price, fractions.settled_at, fractions.status, fractions.transaction_hash, fractions.updated_at, fractions.created_at 
 					FROM fractions 
 					INNER JOIN tokens ON fractions.token_parent_id=tokens.id
 					INNER JOIN users creators ON tokens.creator_id=creators.id
-					WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (tokens.creator_id = $2 OR $2 IS NULL) AND (creators.address = $3 OR $3 IS NULL) AND fractions.status=$4
+					WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (tokens.creator_id = $2 OR $2 IS NULL) AND (creators.address = $3 OR $3 IS NULL) AND fractions.status=$4 AND fractions.deleted_at IS NULL
 					ORDER BY ` + orderBy + ` ` + orderOption + ` 
 					OFFSET $5 
 					LIMIT $6`
 
-	rows, err := r.db.Query(sqlStatement, keyword, helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, keyword, helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), status, offset, limit)
 
 	if err != nil {
 		return fractions, err
@@ -62,7 +67,7 @@ func (r *FractionRepository) GetFractionList(offset int, limit int, keyword stri
 	defer rows.Close()
 	for rows.Next() {
 		var fraction models.Fraction
-		err = rows.Scan(&fraction.ID, &fraction.PreviousID, &fraction.TokenParentID, &fraction.TokenFractionID, &fraction.Status, &fraction.TransactionHash, &fraction.UpdatedAt, &fraction.CreatedAt)
+		err = rows.Scan(&fraction.ID, &fraction.TokenParentID, &fraction.TokenFractionID, &fraction.OwnerID, &fraction.OwnershipID, &fraction.TotalShares, &fraction.SharePrice, &fraction.ReservePrice, &fraction.SettledAt, &fraction.Status, &fraction.TransactionHash, &fraction.UpdatedAt, &fraction.CreatedAt)
 
 		if err != nil {
 			return fractions, err
@@ -74,11 +79,21 @@ func (r *FractionRepository) GetFractionList(offset int, limit int, keyword stri
 	return fractions, nil
 }
 
-func (r *FractionRepository) GetFractionData(id uuid.UUID) (models.Fraction, error) {
-	sqlStatement := `SELECT id, previous_id, token_parent_id, token_fraction_id, status, transaction_hash, updated_at, created_at FROM fractions WHERE id = $1`
+func (r *FractionRepository) GetFractionData(ctx context.Context, id uuid.UUID) (models.Fraction, error) {
+	sqlStatement := `SELECT id, token_parent_id, token_fraction_id, owner_id, ownership_id, total_shares, share_price, reserve_price, settled_at, status, transaction_hash, updated_at, created_at FROM fractions WHERE id = $1 AND deleted_at IS NULL`
 
+	return r.getFraction(ctx, sqlStatement, id)
+}
+
+func (r *FractionRepository) GetFractionByTokenFractionID(ctx context.Context, tokenFractionID uuid.UUID) (models.Fraction, error) {
+	sqlStatement := `SELECT id, token_parent_id, token_fraction_id, owner_id, ownership_id, total_shares, share_price, reserve_price, settled_at, status, transaction_hash, updated_at, created_at FROM fractions WHERE token_fraction_id = $1 AND deleted_at IS NULL LIMIT 1`
+
+	return r.getFraction(ctx, sqlStatement, tokenFractionID)
+}
+
+func (r *FractionRepository) getFraction(ctx context.Context, sqlStatement string, id uuid.UUID) (models.Fraction, error) {
 	var fraction models.Fraction
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return fraction, err
@@ -87,7 +102,7 @@ func (r *FractionRepository) GetFractionData(id uuid.UUID) (models.Fraction, err
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&fraction.ID, &fraction.TokenParentID, &fraction.TokenFractionID, &fraction.Status, &fraction.TransactionHash, &fraction.UpdatedAt, &fraction.CreatedAt)
+		err = rows.Scan(&fraction.ID, &fraction.TokenParentID, &fraction.TokenFractionID, &fraction.OwnerID, &fraction.OwnershipID, &fraction.TotalShares, &fraction.SharePrice, &fraction.ReservePrice, &fraction.SettledAt, &fraction.Status, &fraction.TransactionHash, &fraction.UpdatedAt, &fraction.CreatedAt)
 
 		if err != nil {
 			return fraction, err
@@ -97,12 +112,14 @@ func (r *FractionRepository) GetFractionData(id uuid.UUID) (models.Fraction, err
 	return fraction, nil
 }
 
-func (r *FractionRepository) UpdateFraction(id uuid.UUID, fraction models.Fraction) error {
+func (r *FractionRepository) UpdateFraction(ctx context.Context, id uuid.UUID, fraction models.Fraction) error {
+	defer auditChange(ctx, r.db, "fractions", id, "UpdateFraction")()
+
 	sqlStatement := `UPDATE fractions
-	SET token_parent_id = $2, token_fraction_id = $3, status = $4, updated_at = $5
+	SET token_parent_id = $2, token_fraction_id = $3, owner_id = $4, ownership_id = $5, total_shares = $6, share_price = $7, reserve_price = $8, settled_at = $9, status = $10, updated_at = $11
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, fraction.TokenParentID, fraction.TokenFractionID, fraction.Status, fraction.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, fraction.TokenParentID, fraction.TokenFractionID, helpers.GetNullableUUIDParams(fraction.OwnerID), helpers.GetNullableUUIDParams(fraction.OwnershipID), fraction.TotalShares, fraction.SharePrice, fraction.ReservePrice, fraction.SettledAt, fraction.Status, fraction.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -111,10 +128,12 @@ func (r *FractionRepository) UpdateFraction(id uuid.UUID, fraction models.Fracti
 	return nil
 }
 
-func (r *FractionRepository) DeleteFraction(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM fractions WHERE id = $1`
+func (r *FractionRepository) DeleteFraction(ctx context.Context, id uuid.UUID) error {
+	defer auditChange(ctx, r.db, "fractions", id, "DeleteFraction")()
+
+	sqlStatement := `UPDATE fractions SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
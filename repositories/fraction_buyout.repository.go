@@ -0,0 +1,310 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type FractionBuyoutRepository struct {
+	db *sql.DB
+}
+
+func NewFractionBuyoutRepository(db *sql.DB) *FractionBuyoutRepository {
+	return &FractionBuyoutRepository{db}
+}
+
+func (r *FractionBuyoutRepository) InsertFractionBuyout(ctx context.Context, fractionBuyout models.FractionBuyout) (string, error) {
+	sqlStatement := `INSERT INTO fraction_buyouts (
+		fraction_id,
+		user_id,
+		type,
+		price_per_share,
+		amount,
+		status,
+		transaction_hash,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, fractionBuyout.FractionID, fractionBuyout.UserID, fractionBuyout.Type, fractionBuyout.PricePerShare, fractionBuyout.Amount, fractionBuyout.Status, fractionBuyout.TransactionHash, fractionBuyout.UpdatedAt, fractionBuyout.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *FractionBuyoutRepository) GetFractionBuyoutList(ctx context.Context, offset int, limit int, fractionID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.FractionBuyout, error) {
+	var fractionBuyouts []models.FractionBuyout
+
+	sqlStatement := `SELECT id, fraction_id, user_id, type, price_per_share, amount, status, transaction_hash, confirmed_at, updated_at, created_at 
+				FROM fraction_buyouts 
+				WHERE (fraction_id = $1 OR $1 IS NULL) AND (status = $2 OR $2 IS NULL)
+				ORDER BY ` + orderBy + ` ` + orderOption + ` 
+				OFFSET $3
+				LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(fractionID), helpers.GetOptionalStringParams(status), offset, limit)
+
+	if err != nil {
+		return fractionBuyouts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var fractionBuyout models.FractionBuyout
+		err = rows.Scan(&fractionBuyout.ID, &fractionBuyout.FractionID, &fractionBuyout.UserID, &fractionBuyout.Type, &fractionBuyout.PricePerShare, &fractionBuyout.Amount, &fractionBuyout.Status, &fractionBuyout.TransactionHash, &fractionBuyout.ConfirmedAt, &fractionBuyout.UpdatedAt, &fractionBuyout.CreatedAt)
+
+		if err != nil {
+			return fractionBuyouts, err
+		}
+
+		fractionBuyouts = append(fractionBuyouts, fractionBuyout)
+	}
+
+	return fractionBuyouts, nil
+}
+
+func (r *FractionBuyoutRepository) UpdateFractionBuyoutStatus(ctx context.Context, id uuid.UUID, status string) error {
+	sqlStatement := `UPDATE fraction_buyouts SET status = $2, updated_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, status)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConfirmFractionBuyout opens the offer once its escrow is on chain, the closing window restarts from it
+func (r *FractionBuyoutRepository) ConfirmFractionBuyout(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `UPDATE fraction_buyouts SET status = 'confirmed', confirmed_at = NOW(), updated_at = NOW() WHERE id = $1 AND status = 'waiting_confirmation'`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetClosedFractionIDList returns the vaults whose last confirmed offer is older than the closing window
+func (r *FractionBuyoutRepository) GetClosedFractionIDList(ctx context.Context, before time.Time) ([]uuid.UUID, error) {
+	var fractionIDs []uuid.UUID
+
+	sqlStatement := `SELECT fraction_id FROM fraction_buyouts WHERE status = 'confirmed' GROUP BY fraction_id HAVING MAX(confirmed_at) < $1`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, before)
+
+	if err != nil {
+		return fractionIDs, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var fractionID uuid.UUID
+		err = rows.Scan(&fractionID)
+
+		if err != nil {
+			return fractionIDs, err
+		}
+
+		fractionIDs = append(fractionIDs, fractionID)
+	}
+
+	return fractionIDs, nil
+}
+
+// RejectFractionBuyouts closes offers that cannot win and records the refund of their escrow
+func (r *FractionBuyoutRepository) RejectFractionBuyouts(ctx context.Context, ids []uuid.UUID, refunds []models.FractionPayout) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = rejectFractionBuyouts(ctx, tx, ids, refunds)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *FractionBuyoutRepository) GetFractionPayoutList(ctx context.Context, offset int, limit int, fractionID *uuid.UUID, userID *uuid.UUID) ([]models.FractionPayout, error) {
+	var fractionPayouts []models.FractionPayout
+
+	sqlStatement := `SELECT id, buyout_id, fraction_id, user_id, type, shares, amount, status, updated_at, created_at 
+				FROM fraction_payouts 
+				WHERE (fraction_id = $1 OR $1 IS NULL) AND (user_id = $2 OR $2 IS NULL)
+				ORDER BY created_at DESC 
+				OFFSET $3
+				LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(fractionID), helpers.GetOptionalUUIDParams(userID), offset, limit)
+
+	if err != nil {
+		return fractionPayouts, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var fractionPayout models.FractionPayout
+		err = rows.Scan(&fractionPayout.ID, &fractionPayout.BuyoutID, &fractionPayout.FractionID, &fractionPayout.UserID, &fractionPayout.Type, &fractionPayout.Shares, &fractionPayout.Amount, &fractionPayout.Status, &fractionPayout.UpdatedAt, &fractionPayout.CreatedAt)
+
+		if err != nil {
+			return fractionPayouts, err
+		}
+
+		fractionPayouts = append(fractionPayouts, fractionPayout)
+	}
+
+	return fractionPayouts, nil
+}
+
+// Reunify the parent token in a single transaction, the losing offers are rejected and refunded with it.
+// Reports false when the vault was settled in the meantime.
+func (r *FractionBuyoutRepository) SettleFractionBuyout(ctx context.Context, fractionBuyout models.FractionBuyout, fraction models.Fraction, payouts []models.FractionPayout, shares []models.FractionShare, rejectedIDs []uuid.UUID, refunds []models.FractionPayout) (bool, error) {
+	defer auditChange(ctx, r.db, "fractions", fraction.ID, "SettleFractionBuyout")()
+	defer auditChange(ctx, r.db, "ownerships", fraction.OwnershipID, "SettleFractionBuyout")()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	// Lock the vault so a concurrent settlement waits and then finds it settled
+	res, err := tx.ExecContext(ctx, `UPDATE fractions SET updated_at = NOW() WHERE id = $1 AND status = 'active'`, fraction.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if count == 0 {
+		return false, nil
+	}
+
+	err = insertFractionPayouts(ctx, tx, payouts)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, share := range shares {
+		_, err = tx.ExecContext(ctx, `INSERT INTO fraction_shares (fraction_id, user_id, quantity, reason, reference_id, transaction_hash) VALUES ($1, $2, $3, $4, $5, $6)`,
+			share.FractionID, share.UserID, share.Quantity, share.Reason, share.ReferenceID, share.TransactionHash)
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE fraction_buyouts SET status = 'settled', updated_at = NOW() WHERE id = $1`, fractionBuyout.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	// Offers still waiting for their escrow are rejected and refunded when they confirm on a settled vault
+	err = rejectFractionBuyouts(ctx, tx, rejectedIDs, refunds)
+
+	if err != nil {
+		return false, err
+	}
+
+	fractionStatus := "redeemed"
+
+	if fractionBuyout.Type == helpers.FractionBuyoutTypeBuyout {
+		fractionStatus = "bought_out"
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE fractions SET status = $2, settled_at = $3, updated_at = $3 WHERE id = $1`, fraction.ID, fractionStatus, time.Now())
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tokens SET locked = false, updated_at = NOW() WHERE id = $1`, fraction.TokenParentID)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE tokens SET locked = true, updated_at = NOW() WHERE id = $1`, fraction.TokenFractionID)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE ownerships SET user_id = $2, status = 'active', available_for_sale = false, available_for_rent = false, updated_at = NOW() WHERE id = $1`, fraction.OwnershipID, fractionBuyout.UserID)
+
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE ownerships SET quantity = 0, status = 'inactive', available_for_sale = false, available_for_rent = false, updated_at = NOW() WHERE token_id = $1`, fraction.TokenFractionID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func rejectFractionBuyouts(ctx context.Context, tx *sql.Tx, ids []uuid.UUID, refunds []models.FractionPayout) error {
+	var idParams []string
+
+	for _, id := range ids {
+		idParams = append(idParams, id.String())
+	}
+
+	_, err := tx.ExecContext(ctx, `UPDATE fraction_buyouts SET status = 'rejected', updated_at = NOW() WHERE id = ANY($1::uuid[]) AND status IN ('waiting_confirmation', 'confirmed')`, pq.Array(idParams))
+
+	if err != nil {
+		return err
+	}
+
+	return insertFractionPayouts(ctx, tx, refunds)
+}
+
+func insertFractionPayouts(ctx context.Context, tx *sql.Tx, payouts []models.FractionPayout) error {
+	for _, payout := range payouts {
+		_, err := tx.ExecContext(ctx, `INSERT INTO fraction_payouts (buyout_id, fraction_id, user_id, type, shares, amount, status, updated_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			payout.BuyoutID, payout.FractionID, payout.UserID, payout.Type, payout.Shares, payout.Amount, payout.Status, payout.UpdatedAt, payout.CreatedAt)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type FractionShareRepository struct {
+	db *sql.DB
+}
+
+func NewFractionShareRepository(db *sql.DB) *FractionShareRepository {
+	return &FractionShareRepository{db}
+}
+
+func (r *FractionShareRepository) InsertFractionShare(ctx context.Context, fractionShare models.FractionShare) (string, error) {
+	sqlStatement := `INSERT INTO fraction_shares (
+		fraction_id,
+		user_id,
+		quantity,
+		reason,
+		reference_id,
+		transaction_hash
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, fractionShare.FractionID, fractionShare.UserID, fractionShare.Quantity, fractionShare.Reason, helpers.GetNullableUUIDParams(fractionShare.ReferenceID), fractionShare.TransactionHash).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *FractionShareRepository) GetFractionShareBalanceList(ctx context.Context, fractionID uuid.UUID) ([]models.FractionShareBalance, error) {
+	var balances []models.FractionShareBalance
+
+	sqlStatement := `SELECT fraction_shares.user_id, SUM(fraction_shares.quantity), users.name, users.photo, users.address 
+				FROM fraction_shares 
+				LEFT JOIN users ON fraction_shares.user_id = users.id 
+				WHERE fraction_shares.fraction_id = $1 
+				GROUP BY fraction_shares.user_id, users.name, users.photo, users.address 
+				HAVING SUM(fraction_shares.quantity) > 0 
+				ORDER BY SUM(fraction_shares.quantity) DESC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, fractionID)
+
+	if err != nil {
+		return balances, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var balance models.FractionShareBalance
+		err = rows.Scan(&balance.UserID, &balance.Balance, &balance.User.Name, &balance.User.Photo, &balance.User.Address)
+
+		if err != nil {
+			return balances, err
+		}
+
+		balance.User.ID = balance.UserID
+		balances = append(balances, balance)
+	}
+
+	return balances, nil
+}
@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type MintVoucherRepository struct {
+	db *sql.DB
+}
+
+func NewMintVoucherRepository(db *sql.DB) *MintVoucherRepository {
+	return &MintVoucherRepository{db}
+}
+
+func (r *MintVoucherRepository) InsertMintVoucher(ctx context.Context, mintVoucher models.MintVoucher) (string, error) {
+	sqlStatement := `INSERT INTO mint_vouchers (
+		token_id,
+		creator_id,
+		token_index,
+		uri,
+		supply,
+		price,
+		royalty,
+		nonce,
+		signature,
+		status,
+		updated_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, mintVoucher.TokenID, mintVoucher.CreatorID, mintVoucher.TokenIndex, mintVoucher.Uri, mintVoucher.Supply, mintVoucher.Price, mintVoucher.Royalty, mintVoucher.Nonce, mintVoucher.Signature, mintVoucher.Status, mintVoucher.UpdatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// UpdatePendingMintVoucher replaces the signed terms of a voucher that is not redeemed yet, and reports whether it was still pending
+func (r *MintVoucherRepository) UpdatePendingMintVoucher(ctx context.Context, id uuid.UUID, mintVoucher models.MintVoucher) (bool, error) {
+	sqlStatement := `UPDATE mint_vouchers SET price = $2, royalty = $3, nonce = $4, signature = $5, updated_at = $6 WHERE id = $1 AND status = 'pending'`
+
+	res, err := r.db.ExecContext(ctx, sqlStatement, id, mintVoucher.Price, mintVoucher.Royalty, mintVoucher.Nonce, mintVoucher.Signature, mintVoucher.UpdatedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsMintVoucherNonceUsed reports whether the creator already signed another token's voucher with the nonce
+func (r *MintVoucherRepository) IsMintVoucherNonceUsed(ctx context.Context, creatorID uuid.UUID, nonce string, tokenID uuid.UUID) (bool, error) {
+	var isUsed bool
+
+	sqlStatement := `SELECT EXISTS(SELECT 1 FROM mint_vouchers WHERE creator_id = $1 AND nonce = $2 AND token_id <> $3)`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, creatorID, nonce, tokenID).Scan(&isUsed)
+
+	if err != nil {
+		return false, err
+	}
+
+	return isUsed, nil
+}
+
+func (r *MintVoucherRepository) GetMintVoucherByTokenID(ctx context.Context, tokenID uuid.UUID) (models.MintVoucher, error) {
+	var mintVoucher models.MintVoucher
+
+	sqlStatement := `SELECT id, token_id, creator_id, token_index, uri, supply, price, royalty, nonce, signature, status, transaction_hash, created_at, updated_at FROM mint_vouchers WHERE token_id = $1`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, tokenID).Scan(&mintVoucher.ID, &mintVoucher.TokenID, &mintVoucher.CreatorID, &mintVoucher.TokenIndex, &mintVoucher.Uri, &mintVoucher.Supply, &mintVoucher.Price, &mintVoucher.Royalty, &mintVoucher.Nonce, &mintVoucher.Signature, &mintVoucher.Status, &mintVoucher.TransactionHash, &mintVoucher.CreatedAt, &mintVoucher.UpdatedAt)
+
+	if err != nil && err != sql.ErrNoRows {
+		return mintVoucher, err
+	}
+
+	return mintVoucher, nil
+}
+
+func (r *MintVoucherRepository) UpdateMintVoucherStatus(ctx context.Context, id uuid.UUID, status string, transactionHash string) error {
+	sqlStatement := `UPDATE mint_vouchers SET status = $2, transaction_hash = $3, updated_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, status, transactionHash)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,289 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+// Public lists leave out deleted, hidden or flagged tokens, tokens of hidden collections and the content of banned users
+const visibleTokenStatement = `(tokens.moderation_status = 'visible' AND tokens.deleted_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM users moderated_users WHERE moderated_users.id = tokens.creator_id AND moderated_users.moderation_status = 'banned')
+				AND NOT EXISTS (SELECT 1 FROM collections moderated_collections WHERE moderated_collections.id = tokens.collection_id AND moderated_collections.moderation_status = 'hidden'))`
+
+const visibleCollectionStatement = `(collections.moderation_status = 'visible' AND collections.deleted_at IS NULL
+				AND NOT EXISTS (SELECT 1 FROM users moderated_users WHERE moderated_users.id = collections.creator_id AND moderated_users.moderation_status = 'banned'))`
+
+type ModerationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) *ModerationRepository {
+	return &ModerationRepository{db}
+}
+
+func getModerationTable(subjectType string) string {
+	switch subjectType {
+	case helpers.ModerationSubjectCollection:
+		return "collections"
+	case helpers.ModerationSubjectUser:
+		return "users"
+	}
+
+	return "tokens"
+}
+
+// InsertReport returns sql.ErrNoRows when the reporter already has an open report on the subject
+func (r *ModerationRepository) InsertReport(ctx context.Context, report models.Report) (string, error) {
+	sqlStatement := `INSERT INTO reports (
+		reporter_id,
+		subject_type,
+		subject_id,
+		reason,
+		description
+	  ) VALUES (
+		$1, $2, $3, $4, $5
+	  )
+	  ON CONFLICT (reporter_id, subject_type, subject_id) WHERE status = 'open' DO NOTHING
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, report.ReporterID, report.SubjectType, report.SubjectID, report.Reason, report.Description).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *ModerationRepository) GetReportList(ctx context.Context, offset int, limit int, status *string, subjectType *string, subjectID *uuid.UUID) ([]models.Report, error) {
+	var reports []models.Report
+
+	sqlStatement := `SELECT reports.id, reports.reporter_id, reports.subject_type, reports.subject_id, reports.reason, reports.description, reports.status, reports.resolved_by, reports.resolved_at, reports.updated_at, reports.created_at,
+						users.id, users.name, users.photo, users.address
+					FROM reports
+					INNER JOIN users ON users.id = reports.reporter_id
+					WHERE (reports.status = $1 OR $1 IS NULL) AND (reports.subject_type = $2 OR $2 IS NULL) AND (reports.subject_id = $3 OR $3 IS NULL)
+					ORDER BY reports.created_at ASC
+					OFFSET $4
+					LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalStringParams(status), helpers.GetOptionalStringParams(subjectType), helpers.GetOptionalUUIDParams(subjectID), offset, limit)
+
+	if err != nil {
+		return reports, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var report models.Report
+		err = rows.Scan(&report.ID, &report.ReporterID, &report.SubjectType, &report.SubjectID, &report.Reason, &report.Description, &report.Status, &report.ResolvedBy, &report.ResolvedAt, &report.UpdatedAt, &report.CreatedAt,
+			&report.Reporter.ID, &report.Reporter.Name, &report.Reporter.Photo, &report.Reporter.Address)
+
+		if err != nil {
+			return reports, err
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (r *ModerationRepository) GetReportData(ctx context.Context, id uuid.UUID) (models.Report, error) {
+	var report models.Report
+
+	sqlStatement := `SELECT id, reporter_id, subject_type, subject_id, reason, description, status, resolved_by, resolved_at, updated_at, created_at FROM reports WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, id).Scan(&report.ID, &report.ReporterID, &report.SubjectType, &report.SubjectID, &report.Reason, &report.Description, &report.Status, &report.ResolvedBy, &report.ResolvedAt, &report.UpdatedAt, &report.CreatedAt)
+
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+func (r *ModerationRepository) GetOpenReportCount(ctx context.Context, subjectType string, subjectID uuid.UUID) (int, error) {
+	var count int
+
+	sqlStatement := `SELECT COUNT(*) FROM reports WHERE subject_type = $1 AND subject_id = $2 AND status = 'open'`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, subjectType, subjectID).Scan(&count)
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// GetModerationStatus returns sql.ErrNoRows when the subject does not exist
+func (r *ModerationRepository) GetModerationStatus(ctx context.Context, subjectType string, subjectID uuid.UUID) (string, error) {
+	var status string
+
+	err := r.db.QueryRowContext(ctx, `SELECT moderation_status FROM `+getModerationTable(subjectType)+` WHERE id = $1`, subjectID).Scan(&status)
+
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+func insertModerationAction(ctx context.Context, tx *sql.Tx, moderationAction models.ModerationAction) (models.ModerationAction, error) {
+	sqlStatement := `INSERT INTO moderation_actions (
+		moderator_id,
+		subject_type,
+		subject_id,
+		report_id,
+		action,
+		previous_status,
+		new_status,
+		reason
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8
+	  )
+	  RETURNING id, created_at`
+
+	err := tx.QueryRowContext(ctx, sqlStatement, helpers.GetNullableUUIDParams(moderationAction.ModeratorID), moderationAction.SubjectType, moderationAction.SubjectID, helpers.GetNullableUUIDParams(moderationAction.ReportID), moderationAction.Action, moderationAction.PreviousStatus, moderationAction.NewStatus, moderationAction.Reason).Scan(&moderationAction.ID, &moderationAction.CreatedAt)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	return moderationAction, nil
+}
+
+// UpdateModerationStatus changes the status of a subject, resolves its open reports and writes the decision to the audit trail
+func (r *ModerationRepository) UpdateModerationStatus(ctx context.Context, moderationAction models.ModerationAction) (models.ModerationAction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	defer tx.Rollback()
+
+	table := getModerationTable(moderationAction.SubjectType)
+
+	err = tx.QueryRowContext(ctx, `SELECT moderation_status FROM `+table+` WHERE id = $1 FOR UPDATE`, moderationAction.SubjectID).Scan(&moderationAction.PreviousStatus)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE `+table+` SET moderation_status = $2 WHERE id = $1`, moderationAction.SubjectID, moderationAction.NewStatus)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	// Automatic flags leave the reports open for a moderator
+	if moderationAction.ModeratorID != helpers.GetEmptyUUID() {
+		_, err = tx.ExecContext(ctx, `UPDATE reports SET status = 'resolved', resolved_by = $3, resolved_at = NOW(), updated_at = NOW()
+						WHERE subject_type = $1 AND subject_id = $2 AND status = 'open'`, moderationAction.SubjectType, moderationAction.SubjectID, moderationAction.ModeratorID)
+
+		if err != nil {
+			return moderationAction, err
+		}
+	}
+
+	moderationAction, err = insertModerationAction(ctx, tx, moderationAction)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	return moderationAction, tx.Commit()
+}
+
+// UpdateReportStatus closes one report without changing its subject
+func (r *ModerationRepository) UpdateReportStatus(ctx context.Context, moderationAction models.ModerationAction, status string) (models.ModerationAction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE reports SET status = $2, resolved_by = $3, resolved_at = NOW(), updated_at = NOW() WHERE id = $1 AND status = 'open'`, moderationAction.ReportID, status, moderationAction.ModeratorID)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	if count == 0 {
+		return moderationAction, sql.ErrNoRows
+	}
+
+	moderationAction, err = insertModerationAction(ctx, tx, moderationAction)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	return moderationAction, tx.Commit()
+}
+
+// InsertModerationAction records a decision that has been applied elsewhere, such as a deletion
+func (r *ModerationRepository) InsertModerationAction(ctx context.Context, moderationAction models.ModerationAction) (models.ModerationAction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	defer tx.Rollback()
+
+	moderationAction, err = insertModerationAction(ctx, tx, moderationAction)
+
+	if err != nil {
+		return moderationAction, err
+	}
+
+	return moderationAction, tx.Commit()
+}
+
+func (r *ModerationRepository) GetModerationActionList(ctx context.Context, offset int, limit int, subjectType *string, subjectID *uuid.UUID, moderatorID *uuid.UUID) ([]models.ModerationAction, error) {
+	var moderationActions []models.ModerationAction
+
+	sqlStatement := `SELECT id, moderator_id, subject_type, subject_id, report_id, action, previous_status, new_status, reason, created_at
+					FROM moderation_actions
+					WHERE (subject_type = $1 OR $1 IS NULL) AND (subject_id = $2 OR $2 IS NULL) AND (moderator_id = $3 OR $3 IS NULL)
+					ORDER BY created_at DESC
+					OFFSET $4
+					LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalStringParams(subjectType), helpers.GetOptionalUUIDParams(subjectID), helpers.GetOptionalUUIDParams(moderatorID), offset, limit)
+
+	if err != nil {
+		return moderationActions, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var moderationAction models.ModerationAction
+		err = rows.Scan(&moderationAction.ID, &moderationAction.ModeratorID, &moderationAction.SubjectType, &moderationAction.SubjectID, &moderationAction.ReportID, &moderationAction.Action, &moderationAction.PreviousStatus, &moderationAction.NewStatus, &moderationAction.Reason, &moderationAction.CreatedAt)
+
+		if err != nil {
+			return moderationActions, err
+		}
+
+		moderationActions = append(moderationActions, moderationAction)
+	}
+
+	return moderationActions, nil
+}
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db}
+}
+
+func (r *NotificationRepository) InsertNotification(ctx context.Context, notification models.Notification) (string, error) {
+	sqlStatement := `INSERT INTO notifications (
+		user_id,
+		type,
+		title,
+		body,
+		data
+	  ) VALUES (
+		$1, $2, $3, $4, $5
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, notification.UserID, notification.Type, notification.Title, notification.Body, []byte(notification.Data)).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *NotificationRepository) GetNotificationList(ctx context.Context, offset int, limit int, userID uuid.UUID, unread bool) ([]models.Notification, error) {
+	var notifications []models.Notification
+
+	sqlStatement := `SELECT id, user_id, type, title, body, data, read_at, created_at
+					FROM notifications
+					WHERE user_id = $1 AND (read_at IS NULL OR $2 = false)
+					ORDER BY created_at DESC
+					OFFSET $3
+					LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, userID, unread, offset, limit)
+
+	if err != nil {
+		return notifications, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var notification models.Notification
+		err = rows.Scan(&notification.ID, &notification.UserID, &notification.Type, &notification.Title, &notification.Body, &notification.Data, &notification.ReadAt, &notification.CreatedAt)
+
+		if err != nil {
+			return notifications, err
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) GetUnreadNotificationCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+
+	sqlStatement := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, userID).Scan(&count)
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// ReadNotification marks one notification, or every notification of the user when id is nil
+func (r *NotificationRepository) ReadNotification(ctx context.Context, userID uuid.UUID, id *uuid.UUID) (int64, error) {
+	sqlStatement := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND (id = $2 OR $2 IS NULL) AND read_at IS NULL`
+
+	var idParams interface{}
+
+	if id != nil {
+		idParams = *id
+	}
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, userID, idParams)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (r *NotificationRepository) GetNotificationPreferenceList(ctx context.Context, userID uuid.UUID) ([]models.NotificationPreference, error) {
+	var notificationPreferences []models.NotificationPreference
+
+	sqlStatement := `SELECT user_id, type, in_app, email, updated_at FROM notification_preferences WHERE user_id = $1`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, userID)
+
+	if err != nil {
+		return notificationPreferences, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var notificationPreference models.NotificationPreference
+		err = rows.Scan(&notificationPreference.UserID, &notificationPreference.Type, &notificationPreference.InApp, &notificationPreference.Email, &notificationPreference.UpdatedAt)
+
+		if err != nil {
+			return notificationPreferences, err
+		}
+
+		notificationPreferences = append(notificationPreferences, notificationPreference)
+	}
+
+	return notificationPreferences, nil
+}
+
+// GetNotificationPreference falls back to both channels enabled when the user has no preference
+func (r *NotificationRepository) GetNotificationPreference(ctx context.Context, userID uuid.UUID, notificationType string) (models.NotificationPreference, error) {
+	notificationPreference := models.NotificationPreference{UserID: userID, Type: notificationType, InApp: true, Email: true}
+
+	sqlStatement := `SELECT in_app, email, updated_at FROM notification_preferences WHERE user_id = $1 AND type = $2`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, userID, notificationType).Scan(&notificationPreference.InApp, &notificationPreference.Email, &notificationPreference.UpdatedAt)
+
+	if err != nil && err != sql.ErrNoRows {
+		return notificationPreference, err
+	}
+
+	return notificationPreference, nil
+}
+
+func (r *NotificationRepository) UpsertNotificationPreference(ctx context.Context, notificationPreference models.NotificationPreference) error {
+	sqlStatement := `INSERT INTO notification_preferences (user_id, type, in_app, email, updated_at)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (user_id, type) DO UPDATE SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, updated_at = EXCLUDED.updated_at`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, notificationPreference.UserID, notificationPreference.Type, notificationPreference.InApp, notificationPreference.Email, notificationPreference.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) InsertOutboxEmail(ctx context.Context, outboxEmail models.OutboxEmail) (string, error) {
+	sqlStatement := `INSERT INTO email_outbox (
+		user_id,
+		type,
+		to_address,
+		subject,
+		body,
+		status,
+		next_attempt_at,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, outboxEmail.UserID, outboxEmail.Type, outboxEmail.ToAddress, outboxEmail.Subject, outboxEmail.Body, outboxEmail.Status, outboxEmail.NextAttemptAt, outboxEmail.UpdatedAt, outboxEmail.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// GetDueOutboxEmailList returns pending and failed emails whose next attempt is due
+func (r *NotificationRepository) GetDueOutboxEmailList(ctx context.Context, at time.Time, limit int) ([]models.OutboxEmail, error) {
+	var outboxEmails []models.OutboxEmail
+
+	sqlStatement := `SELECT id, user_id, type, to_address, subject, body, status, attempts, last_error, next_attempt_at, sent_at, updated_at, created_at
+					FROM email_outbox
+					WHERE status IN ('pending', 'failed') AND next_attempt_at <= $1
+					ORDER BY next_attempt_at ASC
+					LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, at, limit)
+
+	if err != nil {
+		return outboxEmails, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var outboxEmail models.OutboxEmail
+		err = rows.Scan(&outboxEmail.ID, &outboxEmail.UserID, &outboxEmail.Type, &outboxEmail.ToAddress, &outboxEmail.Subject, &outboxEmail.Body, &outboxEmail.Status, &outboxEmail.Attempts, &outboxEmail.LastError, &outboxEmail.NextAttemptAt, &outboxEmail.SentAt, &outboxEmail.UpdatedAt, &outboxEmail.CreatedAt)
+
+		if err != nil {
+			return outboxEmails, err
+		}
+
+		outboxEmails = append(outboxEmails, outboxEmail)
+	}
+
+	return outboxEmails, nil
+}
+
+func (r *NotificationRepository) UpdateOutboxEmail(ctx context.Context, id uuid.UUID, outboxEmail models.OutboxEmail) error {
+	sqlStatement := `UPDATE email_outbox
+	SET status = $2, attempts = $3, last_error = $4, next_attempt_at = $5, sent_at = $6, updated_at = $7
+	WHERE id = $1;`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, outboxEmail.Status, outboxEmail.Attempts, outboxEmail.LastError, outboxEmail.NextAttemptAt, outboxEmail.SentAt, outboxEmail.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
@@ -8,6 +9,14 @@ import (
 	"github.com/google/uuid"
 )
 
+// Units held by pending or running rentals
+const rentedQuantityStatement = `(SELECT COALESCE(SUM(rentals.quantity), 0) FROM rentals 
+				WHERE rentals.ownership_id = ownerships.id AND rentals.status IN ('pending', 'active', 'extended') AND rentals.timestamp > NOW() AND rentals.deleted_at IS NULL)`
+
+// Units sold by purchases that are not confirmed yet
+const pendingSoldQuantityStatement = `(SELECT COALESCE(-SUM((pending_changes.changes->>'quantity_delta')::INTEGER), 0) FROM pending_changes 
+				WHERE pending_changes.resource_type = 'ownership' AND pending_changes.resource_id = ownerships.id AND pending_changes.status = 'pending' AND (pending_changes.changes->>'quantity_delta')::INTEGER < 0)`
+
 type OwnershipRepository struct {
 	db *sql.DB
 }
@@ -16,9 +25,8 @@ func NewOwnershipRepository(db *sql.DB) *OwnershipRepository {
 	return &OwnershipRepository{db}
 }
 
-func (r *OwnershipRepository) InsertOwnership(ownership models.Ownership) (string, error) {
+func (r *OwnershipRepository) InsertOwnership(ctx context.Context, ownership models.Ownership) (string, error) {
 	sqlStatement := `INSERT INTO ownerships (
-		previous_id,
 		token_id,
 		user_id,
 		quantity,
@@ -31,13 +39,13 @@ func (r *OwnershipRepository) InsertOwnership(ownership models.Ownership) (strin
 		updated_at,
 		created_at
 	  ) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 	  )
 	  RETURNING id`
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement, ownership.PreviousID, ownership.TokenID, ownership.UserID, ownership.Quantity, ownership.SalePrice, ownership.RentCost, ownership.AvailableForSale, ownership.AvailableForRent, ownership.Status, ownership.TransactionHash, ownership.UpdatedAt, ownership.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStatement, ownership.TokenID, helpers.GetNullableUUIDParams(ownership.UserID), ownership.Quantity, ownership.SalePrice, ownership.RentCost, ownership.AvailableForSale, ownership.AvailableForRent, ownership.Status, ownership.TransactionHash, ownership.UpdatedAt, ownership.CreatedAt).Scan(&id)
 
 	if err != nil {
 		return id, err
@@ -46,10 +54,10 @@ func (r *OwnershipRepository) InsertOwnership(ownership models.Ownership) (strin
 	return id, nil
 }
 
-func (r *OwnershipRepository) GetOwnershipList(offset int, limit int, keyword string, userID *uuid.UUID, user *string, creatorID *uuid.UUID, creator *string, tokenID *uuid.UUID, status string, orderBy string, orderOption string) ([]models.Ownership, error) {
+func (r *OwnershipRepository) GetOwnershipList(ctx context.Context, offset int, limit int, keyword string, userID *uuid.UUID, user *string, creatorID *uuid.UUID, creator *string, tokenID *uuid.UUID, status string, orderBy string, orderOption string) ([]models.Ownership, error) {
 	var ownerships []models.Ownership
 
-	sqlStatement := `SELECT ownerships.id, ownerships.previous_id, ownerships.token_id, ownerships.user_id, ownerships.quantity, ownerships.sale_price, ownerships.rent_cost, ownerships.available_for_sale, ownerships.available_for_rent, ownerships.updated_at, ownerships.created_at, ownerships.status, ownerships.transaction_hash,
+	sqlStatement := `SELECT ownerships.id, ownerships.token_id, ownerships.user_id, ownerships.quantity, ` + rentedQuantityStatement + `, ownerships.sale_price, ownerships.rent_cost, ownerships.available_for_sale, ownerships.available_for_rent, ownerships.updated_at, ownerships.created_at, ownerships.status, ownerships.transaction_hash,
 				tokens.id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.image, tokens.uri, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price, 
 				owners.id, owners.name, owners.email, owners.photo, owners.verified, owners.role, owners.address,
 				creators.id, creators.name, creators.email, creators.photo, creators.verified, creators.role, creators.address
@@ -57,12 +65,12 @@ func (r *OwnershipRepository) GetOwnershipList(offset int, limit int, keyword st
 				INNER JOIN tokens ON ownerships.token_id=tokens.id 
 				LEFT JOIN users owners ON ownerships.user_id=owners.id 
 				LEFT JOIN users creators ON tokens.creator_id=creators.id 
-				WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (owners.id = $2 OR $2 IS NULL) AND (owners.address = $3 OR $3 IS NULL) AND (creators.id = $4 OR $4 IS NULL) AND (creators.address = $5 OR $5 IS NULL) AND (tokens.id = $6 OR $6 IS NULL) AND (ownerships.status = $7 OR $7 IS NULL)
+				WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (owners.id = $2 OR $2 IS NULL) AND (owners.address = $3 OR $3 IS NULL) AND (creators.id = $4 OR $4 IS NULL) AND (creators.address = $5 OR $5 IS NULL) AND (tokens.id = $6 OR $6 IS NULL) AND (ownerships.status = $7 OR $7 IS NULL) AND ownerships.deleted_at IS NULL
 				ORDER BY tokens.` + orderBy + ` ` + orderOption + ` 
 				OFFSET $8 
 				LIMIT $9`
 
-	rows, err := r.db.Query(sqlStatement, keyword, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalStringParams(user), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), helpers.GetOptionalUUIDParams(tokenID), status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, keyword, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalStringParams(user), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), helpers.GetOptionalUUIDParams(tokenID), status, offset, limit)
 
 	if err != nil {
 		return ownerships, err
@@ -71,7 +79,7 @@ func (r *OwnershipRepository) GetOwnershipList(offset int, limit int, keyword st
 	defer rows.Close()
 	for rows.Next() {
 		var ownership models.Ownership
-		err = rows.Scan(&ownership.ID, &ownership.PreviousID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.UpdatedAt, &ownership.CreatedAt, &ownership.Status, &ownership.TransactionHash,
+		err = rows.Scan(&ownership.ID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.RentedQuantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.UpdatedAt, &ownership.CreatedAt, &ownership.Status, &ownership.TransactionHash,
 			&ownership.Token.ID, &ownership.Token.TokenIndex, &ownership.Token.Title, &ownership.Token.Description, &ownership.Token.CategoryID, &ownership.Token.CollectionID, &ownership.Token.Image, &ownership.Token.Uri, &ownership.Token.FractionID, &ownership.Token.Supply, &ownership.Token.LastPrice, &ownership.Token.InitialPrice,
 			&ownership.User.ID, &ownership.User.Name, &ownership.User.Email, &ownership.User.Photo, &ownership.User.Verified, &ownership.User.Role, &ownership.User.Address,
 			&ownership.Token.Creator.ID, &ownership.Token.Creator.Name, &ownership.Token.Creator.Email, &ownership.Token.Creator.Photo, &ownership.Token.Creator.Verified, &ownership.Token.Creator.Role, &ownership.Token.Creator.Address)
@@ -86,11 +94,11 @@ func (r *OwnershipRepository) GetOwnershipList(offset int, limit int, keyword st
 	return ownerships, nil
 }
 
-func (r *OwnershipRepository) GetOwnershipByTokenAndUser(tokenID uuid.UUID, userID uuid.UUID) (models.Ownership, error) {
-	sqlStatement := `SELECT id, previous_id, token_id, user_id, quantity, sale_price, rent_cost, available_for_sale, available_for_rent, status, transaction_hash, updated_at, created_at FROM ownerships WHERE token_id = $1 AND user_id = $2`
+func (r *OwnershipRepository) GetOwnershipByTokenAndUser(ctx context.Context, tokenID uuid.UUID, userID uuid.UUID) (models.Ownership, error) {
+	sqlStatement := `SELECT id, token_id, user_id, quantity, sale_price, rent_cost, available_for_sale, available_for_rent, status, transaction_hash, updated_at, created_at FROM ownerships WHERE token_id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	var ownership models.Ownership
-	rows, err := r.db.Query(sqlStatement, tokenID, userID)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, tokenID, userID)
 
 	if err != nil {
 		return ownership, err
@@ -99,7 +107,7 @@ func (r *OwnershipRepository) GetOwnershipByTokenAndUser(tokenID uuid.UUID, user
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&ownership.ID, &ownership.PreviousID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.Status, &ownership.TransactionHash, &ownership.UpdatedAt, &ownership.CreatedAt)
+		err = rows.Scan(&ownership.ID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.Status, &ownership.TransactionHash, &ownership.UpdatedAt, &ownership.CreatedAt)
 
 		if err != nil {
 			return ownership, err
@@ -109,11 +117,11 @@ func (r *OwnershipRepository) GetOwnershipByTokenAndUser(tokenID uuid.UUID, user
 	return ownership, nil
 }
 
-func (r *OwnershipRepository) GetOwnershipData(id uuid.UUID) (models.Ownership, error) {
-	sqlStatement := `SELECT id, previous_id, token_id, user_id, quantity, sale_price, rent_cost, available_for_sale, available_for_rent, status, transaction_hash, updated_at, created_at FROM ownerships WHERE id = $1`
+func (r *OwnershipRepository) GetOwnershipData(ctx context.Context, id uuid.UUID) (models.Ownership, error) {
+	sqlStatement := `SELECT id, token_id, user_id, quantity, ` + rentedQuantityStatement + `, sale_price, rent_cost, available_for_sale, available_for_rent, status, transaction_hash, updated_at, created_at FROM ownerships WHERE id = $1 AND deleted_at IS NULL`
 
 	var ownership models.Ownership
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return ownership, err
@@ -122,7 +130,7 @@ func (r *OwnershipRepository) GetOwnershipData(id uuid.UUID) (models.Ownership,
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&ownership.ID, &ownership.PreviousID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.Status, &ownership.TransactionHash, &ownership.UpdatedAt, &ownership.CreatedAt)
+		err = rows.Scan(&ownership.ID, &ownership.TokenID, &ownership.UserID, &ownership.Quantity, &ownership.RentedQuantity, &ownership.SalePrice, &ownership.RentCost, &ownership.AvailableForSale, &ownership.AvailableForRent, &ownership.Status, &ownership.TransactionHash, &ownership.UpdatedAt, &ownership.CreatedAt)
 
 		if err != nil {
 			return ownership, err
@@ -132,12 +140,14 @@ func (r *OwnershipRepository) GetOwnershipData(id uuid.UUID) (models.Ownership,
 	return ownership, nil
 }
 
-func (r *OwnershipRepository) UpdateOwnership(id uuid.UUID, ownership models.Ownership) error {
+func (r *OwnershipRepository) UpdateOwnership(ctx context.Context, id uuid.UUID, ownership models.Ownership) error {
+	defer auditChange(ctx, r.db, "ownerships", id, "UpdateOwnership")()
+
 	sqlStatement := `UPDATE ownerships
 	SET token_id = $2, user_id = $3, quantity = $4, sale_price = $5, rent_cost = $6, available_for_sale = $7, available_for_rent = $8, status = $9, transaction_hash = $10, updated_at = $11
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, ownership.TokenID, ownership.UserID, ownership.Quantity, ownership.SalePrice, ownership.RentCost, ownership.AvailableForSale, ownership.AvailableForRent, ownership.Status, ownership.TransactionHash, ownership.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, ownership.TokenID, ownership.UserID, ownership.Quantity, ownership.SalePrice, ownership.RentCost, ownership.AvailableForSale, ownership.AvailableForRent, ownership.Status, ownership.TransactionHash, ownership.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -146,10 +156,61 @@ func (r *OwnershipRepository) UpdateOwnership(id uuid.UUID, ownership models.Own
 	return nil
 }
 
-func (r *OwnershipRepository) DeleteOwnership(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM ownerships WHERE id = $1`
+// InsertPendingSale reserves the sold units under the ownership lock, and reports false when they are no longer available
+func (r *OwnershipRepository) InsertPendingSale(ctx context.Context, ownershipID uuid.UUID, quantity int, transactionHash string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	availableQuantity, err := lockAvailableQuantity(ctx, tx, ownershipID, helpers.GetEmptyUUID())
+
+	if err != nil || quantity > availableQuantity {
+		return false, err
+	}
+
+	// Sold units leave the seller once the transaction is confirmed
+	quantityDelta := -quantity
+
+	_, err = insertPendingChange(ctx, tx, helpers.VersionResourceOwnership, ownershipID, models.OwnershipChange{QuantityDelta: &quantityDelta}, transactionHash)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// lockAvailableQuantity holds the ownership row until the transaction ends, so concurrent rentals and purchases of the same units queue up
+func lockAvailableQuantity(ctx context.Context, tx *sql.Tx, ownershipID uuid.UUID, rentalID uuid.UUID) (int, error) {
+	var quantity, rentedQuantity, soldQuantity int
+
+	sqlStatement := `SELECT quantity, (SELECT COALESCE(SUM(rentals.quantity), 0) FROM rentals 
+				WHERE rentals.ownership_id = ownerships.id AND rentals.id <> $2 AND rentals.status IN ('pending', 'active', 'extended') AND rentals.timestamp > NOW() AND rentals.deleted_at IS NULL), ` + pendingSoldQuantityStatement + `
+				FROM ownerships WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+
+	err := tx.QueryRowContext(ctx, sqlStatement, ownershipID, rentalID).Scan(&quantity, &rentedQuantity, &soldQuantity)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity - rentedQuantity - soldQuantity, nil
+}
+
+func (r *OwnershipRepository) DeleteOwnership(ctx context.Context, id uuid.UUID) error {
+	defer auditChange(ctx, r.db, "ownerships", id, "DeleteOwnership")()
+
+	sqlStatement := `UPDATE ownerships SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
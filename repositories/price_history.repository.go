@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryRepository(db *sql.DB) *PriceHistoryRepository {
+	return &PriceHistoryRepository{db}
+}
+
+func (r *PriceHistoryRepository) InsertPricePoint(ctx context.Context, pricePoint models.PricePoint) error {
+	sqlStatement := `INSERT INTO price_points (
+		transaction_id,
+		token_id,
+		collection_id,
+		type,
+		price,
+		quantity,
+		amount,
+		buyer_id,
+		seller_id
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9
+	  )
+	  ON CONFLICT (transaction_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, pricePoint.TransactionID, pricePoint.TokenID, helpers.GetNullableUUIDParams(pricePoint.CollectionID), pricePoint.Type, pricePoint.Price, pricePoint.Quantity, pricePoint.Amount, pricePoint.BuyerID, pricePoint.SellerID)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PriceHistoryRepository) GetPricePointList(ctx context.Context, offset int, limit int, tokenID uuid.UUID, pricePointType *string, from time.Time) ([]models.PricePoint, error) {
+	var pricePoints []models.PricePoint
+
+	sqlStatement := `SELECT id, transaction_id, token_id, collection_id, type, price, quantity, amount, buyer_id, seller_id, created_at
+					FROM price_points
+					WHERE token_id = $1 AND (type = $2 OR $2 IS NULL) AND created_at >= $3
+					ORDER BY created_at DESC
+					OFFSET $4
+					LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, tokenID, helpers.GetOptionalStringParams(pricePointType), from, offset, limit)
+
+	if err != nil {
+		return pricePoints, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var pricePoint models.PricePoint
+		err = rows.Scan(&pricePoint.ID, &pricePoint.TransactionID, &pricePoint.TokenID, &pricePoint.CollectionID, &pricePoint.Type, &pricePoint.Price, &pricePoint.Quantity, &pricePoint.Amount, &pricePoint.BuyerID, &pricePoint.SellerID, &pricePoint.CreatedAt)
+
+		if err != nil {
+			return pricePoints, err
+		}
+
+		pricePoints = append(pricePoints, pricePoint)
+	}
+
+	return pricePoints, nil
+}
+
+// Aggregate the sales of one bucket for every active collection, the listing snapshot is only refreshed for the open bucket
+func (r *PriceHistoryRepository) RollupCollectionStats(ctx context.Context, interval string, bucket time.Time, end time.Time, isOpenBucket bool) error {
+	sqlStatement := `INSERT INTO collection_stats (collection_id, "interval", bucket, open, high, low, close, volume, sales, unique_buyers, floor, listed_percentage, updated_at)
+					SELECT collections.id, $1, $2, sales.open, sales.high, sales.low, sales.close, COALESCE(sales.volume, 0), COALESCE(sales.sales, 0), COALESCE(sales.unique_buyers, 0), listings.floor, COALESCE(listings.listed_percentage, 0), NOW()
+					FROM collections
+					LEFT JOIN (
+						SELECT collection_id,
+							(ARRAY_AGG(price ORDER BY created_at ASC))[1] AS open,
+							MAX(price) AS high,
+							MIN(price) AS low,
+							(ARRAY_AGG(price ORDER BY created_at DESC))[1] AS close,
+							SUM(amount) AS volume,
+							COUNT(*) AS sales,
+							COUNT(DISTINCT buyer_id) AS unique_buyers
+						FROM price_points
+						WHERE type = 'sale' AND created_at >= $2 AND created_at < $3
+						GROUP BY collection_id
+					) sales ON sales.collection_id = collections.id
+					LEFT JOIN (
+						SELECT tokens.collection_id,
+							MIN(ownerships.sale_price) FILTER (WHERE ownerships.status = 'active' AND ownerships.available_for_sale) AS floor,
+							100.0 * COUNT(DISTINCT tokens.id) FILTER (WHERE ownerships.status = 'active' AND (ownerships.available_for_sale OR ownerships.available_for_rent)) / GREATEST(COUNT(DISTINCT tokens.id), 1) AS listed_percentage
+						FROM tokens
+						LEFT JOIN ownerships ON ownerships.token_id = tokens.id AND ownerships.deleted_at IS NULL
+						WHERE tokens.status = 'active' AND tokens.deleted_at IS NULL
+						GROUP BY tokens.collection_id
+					) listings ON listings.collection_id = collections.id
+					WHERE collections.status = 'active' AND collections.deleted_at IS NULL
+					ON CONFLICT (collection_id, "interval", bucket) DO UPDATE
+					SET open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low, close = EXCLUDED.close, volume = EXCLUDED.volume, sales = EXCLUDED.sales, unique_buyers = EXCLUDED.unique_buyers,
+						floor = CASE WHEN $4::BOOLEAN THEN EXCLUDED.floor ELSE collection_stats.floor END,
+						listed_percentage = CASE WHEN $4::BOOLEAN THEN EXCLUDED.listed_percentage ELSE collection_stats.listed_percentage END,
+						updated_at = NOW()`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, interval, bucket, end, isOpenBucket)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PriceHistoryRepository) GetCollectionStatList(ctx context.Context, collectionID uuid.UUID, interval string, from time.Time) ([]models.CollectionStat, error) {
+	var collectionStats []models.CollectionStat
+
+	sqlStatement := `SELECT collection_id, "interval", bucket, open, high, low, close, volume, sales, unique_buyers, floor, listed_percentage, updated_at
+					FROM collection_stats
+					WHERE collection_id = $1 AND "interval" = $2 AND bucket >= $3
+					ORDER BY bucket ASC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, collectionID, interval, from)
+
+	if err != nil {
+		return collectionStats, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var collectionStat models.CollectionStat
+		err = rows.Scan(&collectionStat.CollectionID, &collectionStat.Interval, &collectionStat.Bucket, &collectionStat.Open, &collectionStat.High, &collectionStat.Low, &collectionStat.Close, &collectionStat.Volume, &collectionStat.Sales, &collectionStat.UniqueBuyers, &collectionStat.Floor, &collectionStat.ListedPercentage, &collectionStat.UpdatedAt)
+
+		if err != nil {
+			return collectionStats, err
+		}
+
+		collectionStats = append(collectionStats, collectionStat)
+	}
+
+	return collectionStats, nil
+}
@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RankingRepository struct {
+	db *sql.DB
+}
+
+func NewRankingRepository(db *sql.DB) *RankingRepository {
+	return &RankingRepository{db}
+}
+
+// Add a confirmed transaction to the hourly bucket of a subject
+func (r *RankingRepository) IncrementRankingBucket(ctx context.Context, rankingType string, subjectID uuid.UUID, timestamp time.Time, volume float64, count int) error {
+	sqlStatement := `INSERT INTO ranking_buckets (type, subject_id, bucket, volume, count)
+					VALUES ($1, $2, DATE_TRUNC('hour', $3::TIMESTAMP), $4, $5)
+					ON CONFLICT (type, subject_id, bucket) DO UPDATE
+					SET volume = ranking_buckets.volume + EXCLUDED.volume, count = ranking_buckets.count + EXCLUDED.count`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, rankingType, subjectID, timestamp.UTC(), volume, count)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Rank the subjects of a window from the hourly buckets and compare them with the previous window
+func (r *RankingRepository) RecomputeRankings(ctx context.Context, rankingType string, window string, now time.Time) error {
+	duration := helpers.RankingWindows[window]
+	start := now.UTC().Add(-duration)
+	previousStart := start.Add(-duration)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM rankings WHERE type = $1 AND "window" = $2`, rankingType, window)
+
+	if err != nil {
+		return err
+	}
+
+	// Learners are ranked by the number of purchases and rentals, the others by volume
+	sqlStatement := `INSERT INTO rankings (type, "window", rank, subject_id, score, volume, count, previous_score, change_percentage, computed_at)
+					SELECT $1, $2, ROW_NUMBER() OVER (ORDER BY current.score DESC, current.volume DESC), current.subject_id, current.score, current.volume, current.count,
+						COALESCE(previous.score, 0),
+						CASE WHEN COALESCE(previous.score, 0) = 0 THEN NULL ELSE (current.score - previous.score) / previous.score * 100 END,
+						NOW()
+					FROM (
+						SELECT subject_id, SUM(volume) AS volume, SUM(count) AS count,
+							CASE WHEN $1 = 'users' THEN SUM(count)::DOUBLE PRECISION ELSE SUM(volume) END AS score
+						FROM ranking_buckets
+						WHERE type = $1 AND bucket >= $3 AND bucket <= $5
+						GROUP BY subject_id
+					) current
+					LEFT JOIN (
+						SELECT subject_id,
+							CASE WHEN $1 = 'users' THEN SUM(count)::DOUBLE PRECISION ELSE SUM(volume) END AS score
+						FROM ranking_buckets
+						WHERE type = $1 AND bucket >= $4 AND bucket < $3
+						GROUP BY subject_id
+					) previous ON previous.subject_id = current.subject_id
+					WHERE current.score > 0
+					ORDER BY current.score DESC, current.volume DESC
+					LIMIT $6`
+
+	_, err = tx.ExecContext(ctx, sqlStatement, rankingType, window, start, previousStart, now.UTC(), helpers.RankingSize)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *RankingRepository) GetRankingList(ctx context.Context, rankingType string, window string, limit int) ([]models.Ranking, error) {
+	var rankings []models.Ranking
+
+	sqlStatement := `SELECT rankings.type, rankings."window", rankings.rank, rankings.subject_id, COALESCE(collections.title, users.name, ''), COALESCE(collections.thumbnail, users.photo, ''),
+						rankings.score, rankings.volume, rankings.count, rankings.previous_score, rankings.change_percentage, rankings.computed_at
+					FROM rankings
+					LEFT JOIN collections ON rankings.type = 'collections' AND collections.id = rankings.subject_id
+					LEFT JOIN users ON rankings.type <> 'collections' AND users.id = rankings.subject_id
+					WHERE rankings.type = $1 AND rankings."window" = $2
+						AND (collections.id IS NULL OR ` + visibleCollectionStatement + `) AND (users.id IS NULL OR users.moderation_status <> 'banned')
+					ORDER BY rankings.rank ASC
+					LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, rankingType, window, limit)
+
+	if err != nil {
+		return rankings, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var ranking models.Ranking
+		err = rows.Scan(&ranking.Type, &ranking.Window, &ranking.Rank, &ranking.SubjectID, &ranking.Name, &ranking.Image,
+			&ranking.Score, &ranking.Volume, &ranking.Count, &ranking.PreviousScore, &ranking.ChangePercentage, &ranking.ComputedAt)
+
+		if err != nil {
+			return rankings, err
+		}
+
+		rankings = append(rankings, ranking)
+	}
+
+	return rankings, nil
+}
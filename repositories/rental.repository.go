@@ -1,9 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,38 +18,58 @@ func NewRentalRepository(db *sql.DB) *RentalRepository {
 	return &RentalRepository{db}
 }
 
-func (r *RentalRepository) InsertRental(rental models.Rental) (string, error) {
+// InsertRental locks the ownership while it checks the units that are not rented yet, and reports false when too few are left
+func (r *RentalRepository) InsertRental(ctx context.Context, rental models.Rental) (string, bool, error) {
+	var id string
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return id, false, err
+	}
+
+	defer tx.Rollback()
+
+	availableQuantity, err := lockAvailableQuantity(ctx, tx, rental.OwnershipID, rental.ID)
+
+	if err != nil || rental.Quantity > availableQuantity {
+		return id, false, err
+	}
+
 	sqlStatement := `INSERT INTO rentals (
-		previous_id,
 		user_id,
 		owner_id,
 		token_id,
 		ownership_id,
+		quantity,
 		timestamp,
+		days,
+		amount,
+		refund_amount,
+		started_at,
+		ended_at,
 		status,
 		transaction_hash,
 		updated_at,
 		created_at
 	  ) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
 	  )
 	  RETURNING id`
 
-	var id string
-
-	err := r.db.QueryRow(sqlStatement, rental.PreviousID, rental.UserID, rental.OwnerID, rental.TokenID, rental.OwnershipID, rental.Timestamp, rental.Status, rental.TransactionHash, rental.UpdatedAt, rental.CreatedAt).Scan(&id)
+	err = tx.QueryRowContext(ctx, sqlStatement, rental.UserID, rental.OwnerID, rental.TokenID, rental.OwnershipID, rental.Quantity, rental.Timestamp, rental.Days, rental.Amount, rental.RefundAmount, rental.StartedAt, rental.EndedAt, rental.Status, rental.TransactionHash, rental.UpdatedAt, rental.CreatedAt).Scan(&id)
 
 	if err != nil {
-		return id, err
+		return id, false, err
 	}
 
-	return id, nil
+	return id, true, tx.Commit()
 }
 
-func (r *RentalRepository) GetRentalList(offset int, limit int, keyword string, userID *uuid.UUID, user *string, ownerID *uuid.UUID, owner *string, creatorID *uuid.UUID, creator *string, tokenID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Rental, error) {
+func (r *RentalRepository) GetRentalList(ctx context.Context, offset int, limit int, keyword string, userID *uuid.UUID, user *string, ownerID *uuid.UUID, owner *string, creatorID *uuid.UUID, creator *string, tokenID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Rental, error) {
 	var rentals []models.Rental
 
-	sqlStatement := `SELECT rentals.id, rentals.previous_id, rentals.token_id, rentals.ownership_id, rentals.user_id, rentals.owner_id, rentals.timestamp, rentals.status, rentals.transaction_hash, rentals.updated_at, rentals.created_at, 
+	sqlStatement := `SELECT rentals.id, rentals.token_id, rentals.ownership_id, rentals.user_id, rentals.owner_id, rentals.quantity, rentals.timestamp, rentals.days, rentals.amount, rentals.refund_amount, rentals.started_at, rentals.ended_at, rentals.status, rentals.transaction_hash, rentals.updated_at, rentals.created_at, 
 				tokens.id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.image, tokens.uri, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price,
 				users.id, users.name, users.email, users.photo, users.verified, users.role, users.address,
 				owners.id, owners.name, owners.email, owners.photo, owners.verified, owners.role, owners.address,
@@ -57,11 +79,11 @@ func (r *RentalRepository) GetRentalList(offset int, limit int, keyword string,
 				LEFT JOIN users owners ON rentals.owner_id=owners.id 
 				LEFT JOIN users users ON rentals.user_id=users.id 
 				LEFT JOIN users creators ON tokens.creator_id=creators.id 
-				WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (users.id = $2 OR $2 IS NULL) AND (users.address = $3 OR $3 IS NULL) AND (owners.id = $4 OR $4 IS NULL) AND (owners.address = $5 OR $5 IS NULL) AND (creators.id = $6 OR $6 IS NULL) AND (creators.address = $7 OR $7 IS NULL) AND (tokens.id = $8 OR $8 IS NULL) AND (rentals.status = $9 OR $9 IS NULL)
+				WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (users.id = $2 OR $2 IS NULL) AND (users.address = $3 OR $3 IS NULL) AND (owners.id = $4 OR $4 IS NULL) AND (owners.address = $5 OR $5 IS NULL) AND (creators.id = $6 OR $6 IS NULL) AND (creators.address = $7 OR $7 IS NULL) AND (tokens.id = $8 OR $8 IS NULL) AND (rentals.status = $9 OR $9 IS NULL) AND rentals.deleted_at IS NULL
 				ORDER BY tokens.` + orderBy + ` ` + orderOption + ` 
 				OFFSET $10
 				LIMIT $11`
-	rows, err := r.db.Query(sqlStatement, keyword, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalStringParams(user), helpers.GetOptionalUUIDParams(ownerID), helpers.GetOptionalStringParams(owner), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), helpers.GetOptionalUUIDParams(tokenID), status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, keyword, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalStringParams(user), helpers.GetOptionalUUIDParams(ownerID), helpers.GetOptionalStringParams(owner), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), helpers.GetOptionalUUIDParams(tokenID), status, offset, limit)
 
 	if err != nil {
 		return rentals, err
@@ -70,7 +92,7 @@ func (r *RentalRepository) GetRentalList(offset int, limit int, keyword string,
 	defer rows.Close()
 	for rows.Next() {
 		var rental models.Rental
-		err = rows.Scan(&rental.ID, &rental.PreviousID, &rental.TokenID, &rental.OwnershipID, &rental.UserID, &rental.OwnerID, &rental.Timestamp, &rental.Status, &rental.TransactionHash, &rental.UpdatedAt, &rental.CreatedAt,
+		err = rows.Scan(&rental.ID, &rental.TokenID, &rental.OwnershipID, &rental.UserID, &rental.OwnerID, &rental.Quantity, &rental.Timestamp, &rental.Days, &rental.Amount, &rental.RefundAmount, &rental.StartedAt, &rental.EndedAt, &rental.Status, &rental.TransactionHash, &rental.UpdatedAt, &rental.CreatedAt,
 			&rental.Token.ID, &rental.Token.TokenIndex, &rental.Token.Title, &rental.Token.Description, &rental.Token.CategoryID, &rental.Token.CollectionID, &rental.Token.Image, &rental.Token.Uri, &rental.Token.FractionID, &rental.Token.Supply, &rental.Token.LastPrice, &rental.Token.InitialPrice,
 			&rental.User.ID, &rental.User.Name, &rental.User.Email, &rental.User.Photo, &rental.User.Verified, &rental.User.Role, &rental.User.Address,
 			&rental.Owner.ID, &rental.Owner.Name, &rental.Owner.Email, &rental.Owner.Photo, &rental.Owner.Verified, &rental.Owner.Role, &rental.Owner.Address,
@@ -85,11 +107,11 @@ func (r *RentalRepository) GetRentalList(offset int, limit int, keyword string,
 	return rentals, nil
 }
 
-func (r *RentalRepository) GetRentalData(id uuid.UUID) (models.Rental, error) {
-	sqlStatement := `SELECT id, previous_id, user_id, owner_id, token_id, ownership_id, timestamp, status, transaction_hash, updated_at, created_at FROM rentals WHERE id = $1`
+func (r *RentalRepository) GetRentalData(ctx context.Context, id uuid.UUID) (models.Rental, error) {
+	sqlStatement := `SELECT id, user_id, owner_id, token_id, ownership_id, quantity, timestamp, days, amount, refund_amount, started_at, ended_at, status, transaction_hash, updated_at, created_at FROM rentals WHERE id = $1 AND deleted_at IS NULL`
 
 	var rental models.Rental
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return rental, err
@@ -98,7 +120,7 @@ func (r *RentalRepository) GetRentalData(id uuid.UUID) (models.Rental, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&rental.ID, &rental.PreviousID, &rental.UserID, &rental.OwnerID, &rental.TokenID, &rental.OwnershipID, &rental.Timestamp, &rental.Status, &rental.TransactionHash, &rental.UpdatedAt, &rental.CreatedAt)
+		err = rows.Scan(&rental.ID, &rental.UserID, &rental.OwnerID, &rental.TokenID, &rental.OwnershipID, &rental.Quantity, &rental.Timestamp, &rental.Days, &rental.Amount, &rental.RefundAmount, &rental.StartedAt, &rental.EndedAt, &rental.Status, &rental.TransactionHash, &rental.UpdatedAt, &rental.CreatedAt)
 
 		if err != nil {
 			return rental, err
@@ -108,12 +130,83 @@ func (r *RentalRepository) GetRentalData(id uuid.UUID) (models.Rental, error) {
 	return rental, nil
 }
 
-func (r *RentalRepository) UpdateRental(id uuid.UUID, rental models.Rental) error {
+func (r *RentalRepository) GetActiveRentalCount(ctx context.Context, tokenID uuid.UUID, at time.Time) (int, error) {
+	sqlStatement := `SELECT COUNT(*) FROM rentals WHERE token_id = $1 AND status IN ('active', 'extended') AND timestamp > $2 AND deleted_at IS NULL`
+
+	var count int
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, tokenID, at).Scan(&count)
+
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+func (r *RentalRepository) GetExpiredRentalList(ctx context.Context, at time.Time) ([]models.Rental, error) {
+	sqlStatement := `SELECT id, user_id, owner_id, token_id, ownership_id, quantity, timestamp, days, amount, refund_amount, started_at, ended_at, status, transaction_hash, updated_at, created_at 
+				FROM rentals 
+				WHERE status IN ('active', 'extended') AND timestamp <= $1 AND deleted_at IS NULL
+				ORDER BY timestamp ASC`
+
+	var rentals []models.Rental
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, at)
+
+	if err != nil {
+		return rentals, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rental models.Rental
+		err = rows.Scan(&rental.ID, &rental.UserID, &rental.OwnerID, &rental.TokenID, &rental.OwnershipID, &rental.Quantity, &rental.Timestamp, &rental.Days, &rental.Amount, &rental.RefundAmount, &rental.StartedAt, &rental.EndedAt, &rental.Status, &rental.TransactionHash, &rental.UpdatedAt, &rental.CreatedAt)
+
+		if err != nil {
+			return rentals, err
+		}
+
+		rentals = append(rentals, rental)
+	}
+
+	return rentals, nil
+}
+
+// UpdateAvailableRental checks the units of the ownership the rental moves to under the same lock as InsertRental
+func (r *RentalRepository) UpdateAvailableRental(ctx context.Context, rental models.Rental) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	availableQuantity, err := lockAvailableQuantity(ctx, tx, rental.OwnershipID, rental.ID)
+
+	if err != nil || rental.Quantity > availableQuantity {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE rentals SET user_id = $2, token_id = $3, ownership_id = $4, updated_at = $5 WHERE id = $1`, rental.ID, rental.UserID, rental.TokenID, rental.OwnershipID, rental.UpdatedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *RentalRepository) UpdateRental(ctx context.Context, id uuid.UUID, rental models.Rental) error {
+	defer auditChange(ctx, r.db, "rentals", id, "UpdateRental")()
+
 	sqlStatement := `UPDATE rentals
-	SET user_id = $2, owner_id = $3, token_id = $4, ownership_id = $5, timestamp = $6, status = $7, updated_at = $8
+	SET user_id = $2, owner_id = $3, token_id = $4, ownership_id = $5, quantity = $6, timestamp = $7, days = $8, amount = $9, refund_amount = $10, started_at = $11, ended_at = $12, status = $13, updated_at = $14
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, rental.UserID, rental.OwnerID, rental.TokenID, rental.OwnershipID, rental.Timestamp, rental.Status, rental.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, rental.UserID, rental.OwnerID, rental.TokenID, rental.OwnershipID, rental.Quantity, rental.Timestamp, rental.Days, rental.Amount, rental.RefundAmount, rental.StartedAt, rental.EndedAt, rental.Status, rental.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -122,10 +215,81 @@ func (r *RentalRepository) UpdateRental(id uuid.UUID, rental models.Rental) erro
 	return nil
 }
 
-func (r *RentalRepository) DeleteRental(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM rentals WHERE id = $1`
+// ReturnRental ends the rental and records the refund together, and reports false when it was no longer in progress
+func (r *RentalRepository) ReturnRental(ctx context.Context, rental models.Rental, refund models.RentalRefund) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE rentals SET refund_amount = $2, ended_at = $3, status = $4, updated_at = $5 WHERE id = $1 AND status IN ('active', 'extended')`,
+		rental.ID, rental.RefundAmount, rental.EndedAt, rental.Status, rental.UpdatedAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := res.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	if count == 0 {
+		return false, nil
+	}
+
+	if refund.Amount > 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO rental_refunds (rental_id, user_id, owner_id, amount, status, updated_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			refund.RentalID, refund.UserID, refund.OwnerID, refund.Amount, refund.Status, refund.UpdatedAt, refund.CreatedAt)
+
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *RentalRepository) GetRentalRefundList(ctx context.Context, offset int, limit int, userID *uuid.UUID, ownerID *uuid.UUID) ([]models.RentalRefund, error) {
+	var rentalRefunds []models.RentalRefund
+
+	sqlStatement := `SELECT id, rental_id, user_id, owner_id, amount, status, updated_at, created_at 
+				FROM rental_refunds 
+				WHERE (user_id = $1 OR $1 IS NULL) AND (owner_id = $2 OR $2 IS NULL)
+				ORDER BY created_at DESC 
+				OFFSET $3
+				LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalUUIDParams(ownerID), offset, limit)
+
+	if err != nil {
+		return rentalRefunds, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rentalRefund models.RentalRefund
+		err = rows.Scan(&rentalRefund.ID, &rentalRefund.RentalID, &rentalRefund.UserID, &rentalRefund.OwnerID, &rentalRefund.Amount, &rentalRefund.Status, &rentalRefund.UpdatedAt, &rentalRefund.CreatedAt)
+
+		if err != nil {
+			return rentalRefunds, err
+		}
+
+		rentalRefunds = append(rentalRefunds, rentalRefund)
+	}
+
+	return rentalRefunds, nil
+}
+
+func (r *RentalRepository) DeleteRental(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `UPDATE rentals SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type RentalEventRepository struct {
+	db *sql.DB
+}
+
+func NewRentalEventRepository(db *sql.DB) *RentalEventRepository {
+	return &RentalEventRepository{db}
+}
+
+func (r *RentalEventRepository) InsertRentalEvent(ctx context.Context, rentalEvent models.RentalEvent) (string, error) {
+	sqlStatement := `INSERT INTO rental_events (
+		rental_id,
+		user_id,
+		owner_id,
+		token_id,
+		actor_id,
+		from_status,
+		to_status,
+		note,
+		dispatched_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, rentalEvent.RentalID, rentalEvent.UserID, rentalEvent.OwnerID, rentalEvent.TokenID, helpers.GetNullableUUIDParams(rentalEvent.ActorID), rentalEvent.FromStatus, rentalEvent.ToStatus, rentalEvent.Note, rentalEvent.DispatchedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *RentalEventRepository) GetRentalEventList(ctx context.Context, offset int, limit int, rentalID *uuid.UUID, participantID *uuid.UUID, orderOption string) ([]models.RentalEvent, error) {
+	var rentalEvents []models.RentalEvent
+
+	sqlStatement := `SELECT id, rental_id, user_id, owner_id, token_id, actor_id, from_status, to_status, COALESCE(note, ''), dispatched_at, created_at 
+				FROM rental_events 
+				WHERE (rental_id = $1 OR $1 IS NULL) AND (user_id = $2 OR owner_id = $2 OR $2 IS NULL)
+				ORDER BY created_at ` + orderOption + ` 
+				OFFSET $3
+				LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(rentalID), helpers.GetOptionalUUIDParams(participantID), offset, limit)
+
+	if err != nil {
+		return rentalEvents, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rentalEvent models.RentalEvent
+		err = rows.Scan(&rentalEvent.ID, &rentalEvent.RentalID, &rentalEvent.UserID, &rentalEvent.OwnerID, &rentalEvent.TokenID, &rentalEvent.ActorID, &rentalEvent.FromStatus, &rentalEvent.ToStatus, &rentalEvent.Note, &rentalEvent.DispatchedAt, &rentalEvent.CreatedAt)
+
+		if err != nil {
+			return rentalEvents, err
+		}
+
+		rentalEvents = append(rentalEvents, rentalEvent)
+	}
+
+	return rentalEvents, nil
+}
+
+// GetUndispatchedRentalEventList returns the state changes whose notifications and webhooks have not been emitted yet, oldest first
+func (r *RentalEventRepository) GetUndispatchedRentalEventList(ctx context.Context, limit int) ([]models.RentalEvent, error) {
+	var rentalEvents []models.RentalEvent
+
+	sqlStatement := `SELECT id, rental_id, user_id, owner_id, token_id, actor_id, from_status, to_status, COALESCE(note, ''), dispatched_at, created_at 
+				FROM rental_events 
+				WHERE dispatched_at IS NULL
+				ORDER BY created_at ASC 
+				LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, limit)
+
+	if err != nil {
+		return rentalEvents, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var rentalEvent models.RentalEvent
+		err = rows.Scan(&rentalEvent.ID, &rentalEvent.RentalID, &rentalEvent.UserID, &rentalEvent.OwnerID, &rentalEvent.TokenID, &rentalEvent.ActorID, &rentalEvent.FromStatus, &rentalEvent.ToStatus, &rentalEvent.Note, &rentalEvent.DispatchedAt, &rentalEvent.CreatedAt)
+
+		if err != nil {
+			return rentalEvents, err
+		}
+
+		rentalEvents = append(rentalEvents, rentalEvent)
+	}
+
+	return rentalEvents, nil
+}
+
+func (r *RentalEventRepository) UpdateRentalEventDispatched(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `UPDATE rental_events SET dispatched_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
+
+	return err
+}
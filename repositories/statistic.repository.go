@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type StatisticRepository struct {
+	db *sql.DB
+}
+
+func NewStatisticRepository(db *sql.DB) *StatisticRepository {
+	return &StatisticRepository{db}
+}
+
+// Compare the stored counters with the statistics derived from the source tables
+func (r *StatisticRepository) GetStatisticDiscrepancyList(ctx context.Context) ([]models.StatisticDiscrepancy, error) {
+	var statisticDiscrepancies []models.StatisticDiscrepancy
+
+	sqlStatement := `SELECT 'token', tokens.id, fields.field, fields.stored, fields.derived
+					FROM tokens
+					INNER JOIN token_statistics ON token_statistics.token_id = tokens.id
+					CROSS JOIN LATERAL (VALUES
+						('number_of_transactions', tokens.number_of_transactions::DOUBLE PRECISION, token_statistics.number_of_transactions::DOUBLE PRECISION),
+						('volume_transactions', tokens.volume_transactions::DOUBLE PRECISION, token_statistics.volume_transactions::DOUBLE PRECISION),
+						('last_price', tokens.last_price::DOUBLE PRECISION, token_statistics.last_price::DOUBLE PRECISION)
+					) AS fields(field, stored, derived)
+					WHERE ABS(fields.stored - fields.derived) > 0.000000001
+					UNION ALL
+					SELECT 'collection', collections.id, fields.field, fields.stored, fields.derived
+					FROM collections
+					INNER JOIN collection_statistics ON collection_statistics.collection_id = collections.id
+					CROSS JOIN LATERAL (VALUES
+						('number_of_items', collections.number_of_items::DOUBLE PRECISION, collection_statistics.number_of_items::DOUBLE PRECISION),
+						('number_of_transactions', collections.number_of_transactions::DOUBLE PRECISION, collection_statistics.number_of_transactions::DOUBLE PRECISION),
+						('volume_transactions', collections.volume_transactions::DOUBLE PRECISION, collection_statistics.volume_transactions::DOUBLE PRECISION),
+						('floor', collections.floor::DOUBLE PRECISION, collection_statistics.floor::DOUBLE PRECISION)
+					) AS fields(field, stored, derived)
+					WHERE ABS(fields.stored - fields.derived) > 0.000000001`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement)
+
+	if err != nil {
+		return statisticDiscrepancies, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var statisticDiscrepancy models.StatisticDiscrepancy
+		err = rows.Scan(&statisticDiscrepancy.Type, &statisticDiscrepancy.ID, &statisticDiscrepancy.Field, &statisticDiscrepancy.Stored, &statisticDiscrepancy.Derived)
+
+		if err != nil {
+			return statisticDiscrepancies, err
+		}
+
+		statisticDiscrepancies = append(statisticDiscrepancies, statisticDiscrepancy)
+	}
+
+	return statisticDiscrepancies, nil
+}
+
+// Repair the stored token counters from the derived statistics, for every token when id is nil
+func (r *StatisticRepository) ReconcileTokenStatistics(ctx context.Context, id *uuid.UUID) (int64, error) {
+	sqlStatement := `UPDATE tokens
+					SET number_of_transactions = token_statistics.number_of_transactions, volume_transactions = token_statistics.volume_transactions, last_price = token_statistics.last_price
+					FROM token_statistics
+					WHERE tokens.id = token_statistics.token_id AND (tokens.id = $1 OR $1 IS NULL)
+						AND (tokens.number_of_transactions <> token_statistics.number_of_transactions
+							OR ABS(tokens.volume_transactions::DOUBLE PRECISION - token_statistics.volume_transactions::DOUBLE PRECISION) > 0.000000001
+							OR ABS(tokens.last_price - token_statistics.last_price) > 0.000000001)`
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(id))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// Repair the stored collection counters from the derived statistics, for every collection when id is nil
+func (r *StatisticRepository) ReconcileCollectionStatistics(ctx context.Context, id *uuid.UUID) (int64, error) {
+	sqlStatement := `UPDATE collections
+					SET number_of_items = collection_statistics.number_of_items, number_of_transactions = collection_statistics.number_of_transactions, volume_transactions = collection_statistics.volume_transactions, floor = collection_statistics.floor
+					FROM collection_statistics
+					WHERE collections.id = collection_statistics.collection_id AND (collections.id = $1 OR $1 IS NULL)
+						AND (collections.number_of_items <> collection_statistics.number_of_items
+							OR collections.number_of_transactions <> collection_statistics.number_of_transactions
+							OR ABS(collections.volume_transactions::DOUBLE PRECISION - collection_statistics.volume_transactions::DOUBLE PRECISION) > 0.000000001
+							OR ABS(collections.floor::DOUBLE PRECISION - collection_statistics.floor::DOUBLE PRECISION) > 0.000000001)`
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(id))
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	helpers "metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
@@ -16,9 +17,8 @@ func NewTokenRepository(db *sql.DB) *TokenRepository {
 	return &TokenRepository{db}
 }
 
-func (r *TokenRepository) InsertToken(token models.Token, attributesBytes []byte) (models.Token, error) {
+func (r *TokenRepository) InsertToken(ctx context.Context, token models.Token, attributesBytes []byte) (models.Token, error) {
 	sqlStatement := `INSERT INTO tokens (
-		previous_id,
 		token_index,
 		title,
 		description,
@@ -41,11 +41,11 @@ func (r *TokenRepository) InsertToken(token models.Token, attributesBytes []byte
 		updated_at,
 		created_at
 	  ) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21
 	  )
 	  RETURNING id, uri, supply, token_index`
 
-	err := r.db.QueryRow(sqlStatement, token.PreviousID, token.TokenIndex, token.Title, token.Description, token.CategoryID, token.CollectionID, token.Image, token.Uri, token.SourceID, token.FractionID, token.Supply, token.LastPrice, token.InitialPrice, token.Views, token.NumberOfTransactions, token.VolumeTransactions, token.CreatorID, token.Attributes, token.Status, token.TransactionHash, token.UpdatedAt, token.CreatedAt).Scan(&token.ID, &token.Uri, &token.Supply, &token.TokenIndex)
+	err := r.db.QueryRowContext(ctx, sqlStatement, token.TokenIndex, token.Title, token.Description, helpers.GetNullableUUIDParams(token.CategoryID), helpers.GetNullableUUIDParams(token.CollectionID), token.Image, token.Uri, helpers.GetNullableUUIDParams(token.SourceID), helpers.GetNullableUUIDParams(token.FractionID), token.Supply, token.LastPrice, token.InitialPrice, token.Views, token.NumberOfTransactions, token.VolumeTransactions, token.CreatorID, token.Attributes, token.Status, token.TransactionHash, token.UpdatedAt, token.CreatedAt).Scan(&token.ID, &token.Uri, &token.Supply, &token.TokenIndex)
 
 	if err != nil {
 		return token, err
@@ -54,14 +54,15 @@ func (r *TokenRepository) InsertToken(token models.Token, attributesBytes []byte
 	return token, nil
 }
 
-func (r *TokenRepository) GetTokenList(offset int, limit int, keyword string, category *uuid.UUID, collection *uuid.UUID, creatorID *uuid.UUID, creator *string, minPrice int, maxPrice int, status *string, orderBy string, orderOption string) ([]models.Token, error) {
+func (r *TokenRepository) GetTokenList(ctx context.Context, offset int, limit int, keyword string, category *uuid.UUID, collection *uuid.UUID, creatorID *uuid.UUID, creator *string, minPrice int, maxPrice int, status *string, visibleOnly bool, orderBy string, orderOption string) ([]models.Token, error) {
 	var tokens []models.Token
 
-	sqlStatement := `SELECT tokens.id, tokens.previous_id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.image, tokens.uri, tokens.source_id, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price, tokens.views, tokens.number_of_transactions, tokens.volume_transactions, tokens.creator_id, tokens.attributes, tokens.status, tokens.transaction_hash, tokens.updated_at, tokens.created_at,
+	sqlStatement := `SELECT tokens.id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.image, tokens.uri, tokens.source_id, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price, tokens.views, tokens.number_of_transactions, tokens.volume_transactions, tokens.favorite_count, tokens.creator_id, tokens.attributes, tokens.moderation_status, tokens.status, tokens.transaction_hash, tokens.updated_at, tokens.created_at,
 					users.id, users.name, users.email, users.photo, users.role, users.address	
 					FROM tokens 
 					INNER JOIN users ON tokens.creator_id=users.id
-					WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (tokens.category_id = $2 OR $2 IS NULL) AND (tokens.collection_id = $3 OR $3 IS NULL) AND (tokens.creator_id = $4 OR $4 IS NULL) AND (users.address = $5 OR $5 IS NULL) AND tokens.status=$6 AND tokens.last_price >= $7 AND tokens.last_price <= $8
+					WHERE LOWER(tokens.title) LIKE '%' || LOWER($1) || '%' AND (tokens.category_id = $2 OR $2 IS NULL) AND (tokens.collection_id = $3 OR $3 IS NULL) AND (tokens.creator_id = $4 OR $4 IS NULL) AND (users.address = $5 OR $5 IS NULL) AND tokens.status=$6 AND tokens.last_price >= $7 AND tokens.last_price <= $8 AND tokens.deleted_at IS NULL
+						AND ($11 = false OR ` + visibleTokenStatement + `)
 					ORDER BY tokens.` + orderBy + ` ` + orderOption + `
 					OFFSET $9
 					LIMIT $10`
@@ -69,7 +70,7 @@ func (r *TokenRepository) GetTokenList(offset int, limit int, keyword string, ca
 	var rows *sql.Rows
 	var err error
 
-	rows, err = r.db.Query(sqlStatement, keyword, helpers.GetOptionalUUIDParams(category), helpers.GetOptionalUUIDParams(collection), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), status, minPrice, maxPrice, offset, limit)
+	rows, err = r.db.QueryContext(ctx, sqlStatement, keyword, helpers.GetOptionalUUIDParams(category), helpers.GetOptionalUUIDParams(collection), helpers.GetOptionalUUIDParams(creatorID), helpers.GetOptionalStringParams(creator), status, minPrice, maxPrice, offset, limit, visibleOnly)
 
 	if err != nil {
 		return tokens, err
@@ -78,7 +79,7 @@ func (r *TokenRepository) GetTokenList(offset int, limit int, keyword string, ca
 	defer rows.Close()
 	for rows.Next() {
 		var token models.Token
-		err = rows.Scan(&token.ID, &token.PreviousID, &token.TokenIndex, &token.Title, &token.Description, &token.CategoryID, &token.CollectionID, &token.Image, &token.Uri, &token.SourceID, &token.FractionID, &token.Supply, &token.LastPrice, &token.InitialPrice, &token.Views, &token.NumberOfTransactions, &token.VolumeTransactions, &token.CreatorID, &token.Attributes, &token.Status, &token.TransactionHash, &token.UpdatedAt, &token.CreatedAt,
+		err = rows.Scan(&token.ID, &token.TokenIndex, &token.Title, &token.Description, &token.CategoryID, &token.CollectionID, &token.Image, &token.Uri, &token.SourceID, &token.FractionID, &token.Supply, &token.LastPrice, &token.InitialPrice, &token.Views, &token.NumberOfTransactions, &token.VolumeTransactions, &token.FavoriteCount, &token.CreatorID, &token.Attributes, &token.ModerationStatus, &token.Status, &token.TransactionHash, &token.UpdatedAt, &token.CreatedAt,
 			&token.Creator.ID, &token.Creator.Name, &token.Creator.Email, &token.Creator.Photo, &token.Creator.Role, &token.Creator.Address)
 
 		if err != nil {
@@ -91,7 +92,7 @@ func (r *TokenRepository) GetTokenList(offset int, limit int, keyword string, ca
 	return tokens, nil
 }
 
-func (r *TokenRepository) GetLastTokenIndex() (int, error) {
+func (r *TokenRepository) GetLastTokenIndex(ctx context.Context) (int, error) {
 	var tokenIndex int
 
 	sqlStatement := `SELECT token_index 
@@ -100,7 +101,7 @@ func (r *TokenRepository) GetLastTokenIndex() (int, error) {
 					ORDER BY token_index 
 					DESC LIMIT 1`
 
-	rows, err := r.db.Query(sqlStatement)
+	rows, err := r.db.QueryContext(ctx, sqlStatement)
 
 	if err != nil {
 		return tokenIndex, err
@@ -118,19 +119,19 @@ func (r *TokenRepository) GetLastTokenIndex() (int, error) {
 	return tokenIndex, nil
 }
 
-func (r *TokenRepository) GetTokenData(id uuid.UUID) (models.Token, error) {
-	sqlStatement := `SELECT tokens.id, tokens.previous_id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.fraction_id, tokens.source_id, tokens.image, tokens.uri, tokens.source_id, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price, tokens.views, tokens.number_of_transactions, tokens.volume_transactions, tokens.creator_id, tokens.attributes, tokens.status, tokens.transaction_hash, tokens.updated_at, tokens.created_at,
-					users.id, users.name, users.email, users.photo, users.role, users.address,
+func (r *TokenRepository) GetTokenData(ctx context.Context, id uuid.UUID) (models.Token, error) {
+	sqlStatement := `SELECT tokens.id, tokens.token_index, tokens.title, tokens.description, tokens.category_id, tokens.collection_id, tokens.fraction_id, tokens.source_id, tokens.image, tokens.uri, tokens.source_id, tokens.fraction_id, tokens.supply, tokens.last_price, tokens.initial_price, tokens.views, tokens.number_of_transactions, tokens.volume_transactions, tokens.favorite_count, tokens.creator_id, tokens.attributes, tokens.locked, tokens.moderation_status, tokens.status, tokens.transaction_hash, tokens.updated_at, tokens.created_at,
+					users.id, users.name, users.email, users.photo, users.role, users.address, users.moderation_status,
 					token_categories.id, token_categories.title, token_categories.description, token_categories.icon, token_categories.updated_at, token_categories.created_at,
-					collections.id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.description, collections.creator_id, collections.category_id, collections.updated_at, collections.created_at
+					collections.id, collections.thumbnail, collections.cover, collections.title, collections.views, collections.number_of_items, collections.number_of_transactions, collections.volume_transactions, collections.description, collections.creator_id, collections.category_id, COALESCE(collections.moderation_status, ''), collections.updated_at, collections.created_at
 					FROM tokens 
 					INNER JOIN token_categories ON tokens.category_id = token_categories.id 
 					INNER JOIN users ON tokens.creator_id = users.id
 					LEFT JOIN collections ON tokens.collection_id = collections.id 
-					WHERE tokens.id = $1`
+					WHERE tokens.id = $1 AND tokens.deleted_at IS NULL`
 
 	var token models.Token
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return token, err
@@ -139,9 +140,9 @@ func (r *TokenRepository) GetTokenData(id uuid.UUID) (models.Token, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&token.ID, &token.PreviousID, &token.TokenIndex, &token.Title, &token.Description, &token.CategoryID, &token.CollectionID, &token.FractionID, &token.SourceID, &token.Image, &token.Uri, &token.SourceID, &token.FractionID, &token.Supply, &token.LastPrice, &token.InitialPrice, &token.Views, &token.NumberOfTransactions, &token.VolumeTransactions, &token.CreatorID, &token.Attributes, &token.Status, &token.TransactionHash, &token.UpdatedAt, &token.CreatedAt,
-			&token.Creator.ID, &token.Creator.Name, &token.Creator.Email, &token.Creator.Photo, &token.Creator.Role, &token.Creator.Address,
-			&token.Category.ID, &token.Category.Title, &token.Category.Description, &token.Category.Icon, &token.Category.UpdatedAt, &token.Category.CreatedAt, &token.Collection.ID, &token.Collection.Thumbnail, &token.Collection.Cover, &token.Collection.Title, &token.Collection.Views, &token.Collection.NumberOfItems, &token.Collection.NumberOfTransactions, &token.Collection.VolumeTransactions, &token.Collection.Description, &token.Collection.CreatorID, &token.Collection.CategoryID, &token.Collection.UpdatedAt, &token.Collection.CreatedAt)
+		err = rows.Scan(&token.ID, &token.TokenIndex, &token.Title, &token.Description, &token.CategoryID, &token.CollectionID, &token.FractionID, &token.SourceID, &token.Image, &token.Uri, &token.SourceID, &token.FractionID, &token.Supply, &token.LastPrice, &token.InitialPrice, &token.Views, &token.NumberOfTransactions, &token.VolumeTransactions, &token.FavoriteCount, &token.CreatorID, &token.Attributes, &token.Locked, &token.ModerationStatus, &token.Status, &token.TransactionHash, &token.UpdatedAt, &token.CreatedAt,
+			&token.Creator.ID, &token.Creator.Name, &token.Creator.Email, &token.Creator.Photo, &token.Creator.Role, &token.Creator.Address, &token.Creator.ModerationStatus,
+			&token.Category.ID, &token.Category.Title, &token.Category.Description, &token.Category.Icon, &token.Category.UpdatedAt, &token.Category.CreatedAt, &token.Collection.ID, &token.Collection.Thumbnail, &token.Collection.Cover, &token.Collection.Title, &token.Collection.Views, &token.Collection.NumberOfItems, &token.Collection.NumberOfTransactions, &token.Collection.VolumeTransactions, &token.Collection.Description, &token.Collection.CreatorID, &token.Collection.CategoryID, &token.Collection.ModerationStatus, &token.Collection.UpdatedAt, &token.Collection.CreatedAt)
 
 		if err != nil {
 			return token, err
@@ -151,12 +152,15 @@ func (r *TokenRepository) GetTokenData(id uuid.UUID) (models.Token, error) {
 	return token, nil
 }
 
-func (r *TokenRepository) UpdateToken(id uuid.UUID, token models.Token) error {
+func (r *TokenRepository) UpdateToken(ctx context.Context, id uuid.UUID, token models.Token) error {
+	defer auditChange(ctx, r.db, "tokens", id, "UpdateToken")()
+
+	// Views and the derived statistics are not written here, see the view and statistic repositories
 	sqlStatement := `UPDATE tokens
-	SET title = $2, description = $3, category_id = $4, collection_id = $5, image = $6, uri = $7, source_id = $8, fraction_id = $9, supply = $10, last_price = $11, initial_price = $12, views = $13, number_of_transactions = $14, volume_transactions = $15, creator_id = $16, status = $17, transaction_hash = $18, updated_at = $19
+	SET title = $2, description = $3, category_id = $4, collection_id = $5, image = $6, uri = $7, source_id = $8, fraction_id = $9, supply = $10, initial_price = $11, creator_id = $12, status = $13, transaction_hash = $14, updated_at = $15
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, token.Title, token.Description, token.CategoryID, token.CollectionID, token.Image, token.Uri, token.SourceID, token.FractionID, token.Supply, token.LastPrice, token.InitialPrice, token.Views, token.NumberOfTransactions, token.VolumeTransactions, token.CreatorID, token.Status, token.TransactionHash, token.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, token.Title, token.Description, helpers.GetNullableUUIDParams(token.CategoryID), helpers.GetNullableUUIDParams(token.CollectionID), token.Image, token.Uri, helpers.GetNullableUUIDParams(token.SourceID), helpers.GetNullableUUIDParams(token.FractionID), token.Supply, token.InitialPrice, token.CreatorID, token.Status, token.TransactionHash, token.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -165,10 +169,60 @@ func (r *TokenRepository) UpdateToken(id uuid.UUID, token models.Token) error {
 	return nil
 }
 
-func (r *TokenRepository) DeleteToken(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM tokens WHERE id = $1`
+// ActivateLazyToken marks a lazy minted token and the creator's ownership active together once the mint confirms,
+// it reports false when the token was not lazy anymore
+func (r *TokenRepository) ActivateLazyToken(ctx context.Context, id uuid.UUID, transactionHash string) (bool, error) {
+	defer auditChange(ctx, r.db, "tokens", id, "ActivateLazyToken")()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE tokens SET status = 'active', transaction_hash = $2, updated_at = NOW() WHERE id = $1 AND status = 'lazy'`, id, transactionHash)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil || count == 0 {
+		return false, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE ownerships SET status = 'active', updated_at = NOW() WHERE token_id = $1 AND status = 'lazy'`, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *TokenRepository) UpdateTokenLocked(ctx context.Context, id uuid.UUID, locked bool) error {
+	defer auditChange(ctx, r.db, "tokens", id, "UpdateTokenLocked")()
+
+	sqlStatement := `UPDATE tokens SET locked = $2, updated_at = NOW() WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, locked)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TokenRepository) DeleteToken(ctx context.Context, id uuid.UUID) error {
+	defer auditChange(ctx, r.db, "tokens", id, "DeleteToken")()
+
+	sqlStatement := `UPDATE tokens SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	models "metaedu-marketplace/models"
 
@@ -15,7 +16,7 @@ func NewTokenCategoryRepository(db *sql.DB) *TokenCategoryRepository {
 	return &TokenCategoryRepository{db}
 }
 
-func (r *TokenCategoryRepository) InsertTokenCategory(tokenCategory models.TokenCategory) (string, error) {
+func (r *TokenCategoryRepository) InsertTokenCategory(ctx context.Context, tokenCategory models.TokenCategory) (string, error) {
 	sqlStatement := `INSERT INTO token_categories (
 		title,
 		description,
@@ -30,7 +31,7 @@ func (r *TokenCategoryRepository) InsertTokenCategory(tokenCategory models.Token
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement, tokenCategory.Title, tokenCategory.Description, tokenCategory.Icon, tokenCategory.Status, tokenCategory.UpdatedAt, tokenCategory.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStatement, tokenCategory.Title, tokenCategory.Description, tokenCategory.Icon, tokenCategory.Status, tokenCategory.UpdatedAt, tokenCategory.CreatedAt).Scan(&id)
 
 	if err != nil {
 		return id, err
@@ -39,17 +40,17 @@ func (r *TokenCategoryRepository) InsertTokenCategory(tokenCategory models.Token
 	return id, nil
 }
 
-func (r *TokenCategoryRepository) GetTokenCategoryList(offset int, limit int, keyword string, status *string, orderBy string, orderOption string) ([]models.TokenCategory, error) {
+func (r *TokenCategoryRepository) GetTokenCategoryList(ctx context.Context, offset int, limit int, keyword string, status *string, orderBy string, orderOption string) ([]models.TokenCategory, error) {
 	var tokenCategories []models.TokenCategory
 
 	sqlStatement := `SELECT id, title, description, icon, updated_at, created_at 
 					FROM token_categories 
-					WHERE title LIKE '%' || $1 || '%' AND (status=$2 OR $2 IS NULL)
+					WHERE title LIKE '%' || $1 || '%' AND (status=$2 OR $2 IS NULL) AND deleted_at IS NULL
 					ORDER BY ` + orderBy + ` ` + orderOption + ` 
 					OFFSET $3 
 					LIMIT $4`
 
-	rows, err := r.db.Query(sqlStatement, keyword, status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, keyword, status, offset, limit)
 
 	if err != nil {
 		return tokenCategories, err
@@ -70,11 +71,11 @@ func (r *TokenCategoryRepository) GetTokenCategoryList(offset int, limit int, ke
 	return tokenCategories, nil
 }
 
-func (r *TokenCategoryRepository) GetTokenCategoryData(id uuid.UUID) (models.TokenCategory, error) {
-	sqlStatement := `SELECT id, title, description, icon, updated_at, created_at FROM token_categories WHERE id = $1`
+func (r *TokenCategoryRepository) GetTokenCategoryData(ctx context.Context, id uuid.UUID) (models.TokenCategory, error) {
+	sqlStatement := `SELECT id, title, description, icon, updated_at, created_at FROM token_categories WHERE id = $1 AND deleted_at IS NULL`
 
 	var tokenCategory models.TokenCategory
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return tokenCategory, err
@@ -93,12 +94,12 @@ func (r *TokenCategoryRepository) GetTokenCategoryData(id uuid.UUID) (models.Tok
 	return tokenCategory, nil
 }
 
-func (r *TokenCategoryRepository) UpdateTokenCategory(id uuid.UUID, tokenCategory models.TokenCategory) error {
+func (r *TokenCategoryRepository) UpdateTokenCategory(ctx context.Context, id uuid.UUID, tokenCategory models.TokenCategory) error {
 	sqlStatement := `UPDATE token_categories
 	SET title = $2, description = $3, icon = $4, status = $5, updated_at = $6
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, tokenCategory.Title, tokenCategory.Description, tokenCategory.Icon, tokenCategory.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, tokenCategory.Title, tokenCategory.Description, tokenCategory.Icon, tokenCategory.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -107,10 +108,10 @@ func (r *TokenCategoryRepository) UpdateTokenCategory(id uuid.UUID, tokenCategor
 	return nil
 }
 
-func (r *TokenCategoryRepository) DeleteTokenCategory(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM token_categories WHERE id = $1`
+func (r *TokenCategoryRepository) DeleteTokenCategory(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `UPDATE token_categories SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"metaedu-marketplace/helpers"
 	models "metaedu-marketplace/models"
@@ -16,9 +17,8 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db}
 }
 
-func (r *TransactionRepository) InsertTransaction(transaction models.Transaction) (string, error) {
+func (r *TransactionRepository) InsertTransaction(ctx context.Context, transaction models.Transaction) (string, error) {
 	sqlStatement := `INSERT INTO transactions (
-		previous_id,
 		user_from_id,
 		user_to_id,
 		ownership_id,
@@ -33,19 +33,18 @@ func (r *TransactionRepository) InsertTransaction(transaction models.Transaction
 		updated_at,
 		created_at
 	  ) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 	  )
 	  RETURNING id`
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement,
-		transaction.PreviousID,
+	err := r.db.QueryRowContext(ctx, sqlStatement,
 		transaction.UserFromID,
 		transaction.UserToID,
-		transaction.OwnershipID,
+		helpers.GetNullableUUIDParams(transaction.OwnershipID),
 		transaction.TokenID,
-		transaction.CollectionID,
+		helpers.GetNullableUUIDParams(transaction.CollectionID),
 		transaction.Type,
 		transaction.Quantity,
 		transaction.Amount,
@@ -62,10 +61,10 @@ func (r *TransactionRepository) InsertTransaction(transaction models.Transaction
 	return id, nil
 }
 
-func (r *TransactionRepository) GetTransactionList(offset int, limit int, userID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
+func (r *TransactionRepository) GetTransactionList(ctx context.Context, offset int, limit int, userID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
 
-	sqlStatement := `SELECT transactions.id, transactions.previous_id, transactions.user_from_id, transactions.user_to_id, transactions.ownership_id, transactions.Token_id, transactions.type, transactions.quantity, transactions.amount, transactions.gas_fee, transactions.status, transactions.transaction_hash, transactions.updated_at, transactions.created_at, 
+	sqlStatement := `SELECT transactions.id, transactions.user_from_id, transactions.user_to_id, transactions.ownership_id, transactions.Token_id, transactions.type, transactions.quantity, transactions.amount, transactions.gas_fee, transactions.status, transactions.transaction_hash, transactions.updated_at, transactions.created_at, 
 					ownerships.id, ownerships.Token_id, ownerships.user_id, ownerships.quantity, ownerships.sale_price, ownerships.rent_cost, ownerships.available_for_sale, ownerships.available_for_rent, ownerships.updated_at, ownerships.created_at, 
 					rentals.id, rentals.user_id, rentals.owner_id, rentals.Token_id, rentals.ownership_id, rentals.updated_at, rentals.created_at,
 					user_from.id, user_from.name, user_from.email, user_from.photo, user_from.role, user_from.address,
@@ -79,12 +78,12 @@ func (r *TransactionRepository) GetTransactionList(offset int, limit int, userID
 					LEFT JOIN users user_to ON transactions.user_to_id=user_to.id 
 					LEFT JOIN collections ON transactions.collection_id=collections.id
 					INNER JOIN tokens ON transactions.token_id=tokens.id
-					WHERE (transactions.status = $2 OR $2 IS NULL) AND ((transactions.user_from_id=$1 OR $1 IS NULL) OR (transactions.user_to_id=$1 OR $1 IS NULL))
+					WHERE (transactions.status = $2 OR $2 IS NULL) AND ((transactions.user_from_id=$1 OR $1 IS NULL) OR (transactions.user_to_id=$1 OR $1 IS NULL)) AND transactions.deleted_at IS NULL
 					ORDER BY transactions.` + orderBy + ` ` + orderOption + ` 
 					OFFSET $3 
 					LIMIT $4`
 
-	rows, err := r.db.Query(sqlStatement, helpers.GetOptionalUUIDParams(userID), status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(userID), status, offset, limit)
 
 	if err != nil {
 		return transactions, err
@@ -93,7 +92,7 @@ func (r *TransactionRepository) GetTransactionList(offset int, limit int, userID
 	defer rows.Close()
 	for rows.Next() {
 		var transaction models.Transaction
-		err = rows.Scan(&transaction.ID, &transaction.PreviousID, &transaction.UserFromID, &transaction.UserToID, &transaction.OwnershipID, &transaction.TokenID, &transaction.Type, &transaction.Quantity, &transaction.Amount, &transaction.GasFee, &transaction.Status, &transaction.TransactionHash, &transaction.UpdatedAt, &transaction.CreatedAt,
+		err = rows.Scan(&transaction.ID, &transaction.UserFromID, &transaction.UserToID, &transaction.OwnershipID, &transaction.TokenID, &transaction.Type, &transaction.Quantity, &transaction.Amount, &transaction.GasFee, &transaction.Status, &transaction.TransactionHash, &transaction.UpdatedAt, &transaction.CreatedAt,
 			&transaction.Ownership.ID, &transaction.Ownership.TokenID, &transaction.Ownership.UserID, &transaction.Ownership.Quantity, &transaction.Ownership.SalePrice, &transaction.Ownership.RentCost, &transaction.Ownership.AvailableForSale, &transaction.Ownership.AvailableForRent, &transaction.Ownership.UpdatedAt, &transaction.Ownership.CreatedAt,
 			&transaction.Rental.ID, &transaction.Rental.UserID, &transaction.Rental.OwnerID, &transaction.Rental.TokenID, &transaction.Rental.OwnershipID, &transaction.Rental.UpdatedAt, &transaction.Rental.CreatedAt,
 			&transaction.UserFrom.ID, &transaction.UserFrom.Name, &transaction.UserFrom.Email, &transaction.UserFrom.Photo, &transaction.UserFrom.Role, &transaction.UserFrom.Address,
@@ -111,7 +110,7 @@ func (r *TransactionRepository) GetTransactionList(offset int, limit int, userID
 	return transactions, nil
 }
 
-func (r *TransactionRepository) GetTransactionListByToken(offset int, limit int, tokenID uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
+func (r *TransactionRepository) GetTransactionListByToken(ctx context.Context, offset int, limit int, tokenID uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
 
 	sqlStatement := `SELECT transactions.id, transactions.user_from_id, transactions.user_to_id, transactions.ownership_id, transactions.Token_id, transactions.type, transactions.quantity, transactions.amount, transactions.gas_fee, transactions.transaction_hash, transactions.updated_at, transactions.created_at, 
@@ -126,12 +125,12 @@ func (r *TransactionRepository) GetTransactionListByToken(offset int, limit int,
 					LEFT JOIN users user_from ON transactions.user_from_id=user_from.id 
 					LEFT JOIN users user_to ON transactions.user_to_id=user_to.id 
 					INNER JOIN tokens ON transactions.token_id=tokens.id
-					WHERE transactions.token_id = $1 AND (transactions.status=$2 OR $2 IS NULL)
+					WHERE transactions.token_id = $1 AND (transactions.status=$2 OR $2 IS NULL) AND transactions.deleted_at IS NULL
 					ORDER BY transactions.` + orderBy + ` ` + orderOption + ` 
 					OFFSET $3 
 					LIMIT $4`
 
-	rows, err := r.db.Query(sqlStatement, tokenID, status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, tokenID, status, offset, limit)
 
 	if err != nil {
 		return transactions, err
@@ -157,7 +156,7 @@ func (r *TransactionRepository) GetTransactionListByToken(offset int, limit int,
 	return transactions, nil
 }
 
-func (r *TransactionRepository) GetTransactionListByCollection(offset int, limit int, collectionID uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
+func (r *TransactionRepository) GetTransactionListByCollection(ctx context.Context, offset int, limit int, collectionID uuid.UUID, status *string, orderBy string, orderOption string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
 
 	sqlStatement := `SELECT transactions.id, transactions.user_from_id, transactions.user_to_id, transactions.ownership_id, transactions.Token_id, transactions.type, transactions.quantity, transactions.amount, transactions.gas_fee, transactions.transaction_hash, transactions.updated_at, transactions.created_at, 
@@ -172,12 +171,12 @@ func (r *TransactionRepository) GetTransactionListByCollection(offset int, limit
 					LEFT JOIN users user_from ON transactions.user_from_id=user_from.id 
 					LEFT JOIN users user_to ON transactions.user_to_id=user_to.id 
 					INNER JOIN tokens ON transactions.token_id=tokens.id
-					WHERE transactions.collection_id = $1 AND (transactions.status = $2 OR $2 IS NULL)
+					WHERE transactions.collection_id = $1 AND (transactions.status = $2 OR $2 IS NULL) AND transactions.deleted_at IS NULL
 					ORDER BY transactions.` + orderBy + ` ` + orderOption + ` 
 					OFFSET $3 
 					LIMIT $4`
 
-	rows, err := r.db.Query(sqlStatement, collectionID, status, offset, limit)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, collectionID, status, offset, limit)
 
 	if err != nil {
 		return transactions, err
@@ -203,11 +202,11 @@ func (r *TransactionRepository) GetTransactionListByCollection(offset int, limit
 	return transactions, nil
 }
 
-func (r *TransactionRepository) GetTransactionData(id uuid.UUID) (models.Transaction, error) {
-	sqlStatement := `SELECT id, previous_id, user_from_id, user_to_id, ownership_id, rental_id, token_id, collection_id, type, quantity, amount, gas_fee, status, transaction_hash, updated_at, created_at FROM transactions WHERE id = $1`
+func (r *TransactionRepository) GetTransactionData(ctx context.Context, id uuid.UUID) (models.Transaction, error) {
+	sqlStatement := `SELECT id, user_from_id, user_to_id, ownership_id, rental_id, token_id, collection_id, type, quantity, amount, gas_fee, status, transaction_hash, updated_at, created_at FROM transactions WHERE id = $1 AND deleted_at IS NULL`
 
 	var transaction models.Transaction
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return transaction, err
@@ -226,12 +225,14 @@ func (r *TransactionRepository) GetTransactionData(id uuid.UUID) (models.Transac
 	return transaction, nil
 }
 
-func (r *TransactionRepository) UpdateTransaction(id uuid.UUID, transaction models.Transaction) error {
+func (r *TransactionRepository) UpdateTransaction(ctx context.Context, id uuid.UUID, transaction models.Transaction) error {
+	defer auditChange(ctx, r.db, "transactions", id, "UpdateTransaction")()
+
 	sqlStatement := `UPDATE transactions
 	SET user_from_id = $2, user_to_id = $3, ownership_id = $4, token_id = $5, type = $6, quantity = $7, amount = $8, gas_fee = $9, status = $10, transaction_hash = $11, updated_at = $12
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, id, transaction.UserFromID, transaction.UserToID, transaction.OwnershipID, transaction.TokenID, transaction.Type, transaction.Quantity, transaction.Amount, transaction.GasFee, transaction.Status, transaction.TransactionHash, transaction.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, transaction.UserFromID, transaction.UserToID, transaction.OwnershipID, transaction.TokenID, transaction.Type, transaction.Quantity, transaction.Amount, transaction.GasFee, transaction.Status, transaction.TransactionHash, transaction.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -240,10 +241,12 @@ func (r *TransactionRepository) UpdateTransaction(id uuid.UUID, transaction mode
 	return nil
 }
 
-func (r *TransactionRepository) DeleteTransaction(id uuid.UUID) error {
-	sqlStatement := `DELETE FROM transactions WHERE id = $1`
+func (r *TransactionRepository) DeleteTransaction(ctx context.Context, id uuid.UUID) error {
+	defer auditChange(ctx, r.db, "transactions", id, "DeleteTransaction")()
+
+	sqlStatement := `UPDATE transactions SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(sqlStatement, id)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return err
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type TransactionFeeRepository struct {
+	db *sql.DB
+}
+
+func NewTransactionFeeRepository(db *sql.DB) *TransactionFeeRepository {
+	return &TransactionFeeRepository{db}
+}
+
+func (r *TransactionFeeRepository) InsertTransactionFee(ctx context.Context, transactionFee models.TransactionFee) (string, error) {
+	sqlStatement := `INSERT INTO transaction_fees (
+		transaction_id,
+		fraction_id,
+		fee_schedule_id,
+		transaction_type,
+		fee_type,
+		value,
+		base_amount,
+		amount,
+		recipient_id,
+		status,
+		transaction_hash,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, helpers.GetNullableUUIDParams(transactionFee.TransactionID), helpers.GetNullableUUIDParams(transactionFee.FractionID), transactionFee.FeeScheduleID, transactionFee.TransactionType, transactionFee.FeeType, transactionFee.Value, transactionFee.BaseAmount, transactionFee.Amount, helpers.GetNullableUUIDParams(transactionFee.RecipientID), transactionFee.Status, transactionFee.TransactionHash, transactionFee.UpdatedAt, transactionFee.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *TransactionFeeRepository) GetTransactionFeeList(ctx context.Context, offset int, limit int, transactionID *uuid.UUID, fractionID *uuid.UUID, feeScheduleID *uuid.UUID, status *string, orderBy string, orderOption string) ([]models.TransactionFee, error) {
+	var transactionFees []models.TransactionFee
+
+	sqlStatement := `SELECT id, transaction_id, fraction_id, fee_schedule_id, transaction_type, fee_type, value, base_amount, amount, recipient_id, status, transaction_hash, updated_at, created_at
+					FROM transaction_fees
+					WHERE (transaction_id = $1 OR $1 IS NULL) AND (fraction_id = $2 OR $2 IS NULL) AND (fee_schedule_id = $3 OR $3 IS NULL) AND (status = $4 OR $4 IS NULL)
+					ORDER BY ` + orderBy + ` ` + orderOption + `
+					OFFSET $5
+					LIMIT $6`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(transactionID), helpers.GetOptionalUUIDParams(fractionID), helpers.GetOptionalUUIDParams(feeScheduleID), helpers.GetOptionalStringParams(status), offset, limit)
+
+	if err != nil {
+		return transactionFees, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var transactionFee models.TransactionFee
+		err = rows.Scan(&transactionFee.ID, &transactionFee.TransactionID, &transactionFee.FractionID, &transactionFee.FeeScheduleID, &transactionFee.TransactionType, &transactionFee.FeeType, &transactionFee.Value, &transactionFee.BaseAmount, &transactionFee.Amount, &transactionFee.RecipientID, &transactionFee.Status, &transactionFee.TransactionHash, &transactionFee.UpdatedAt, &transactionFee.CreatedAt)
+
+		if err != nil {
+			return transactionFees, err
+		}
+
+		transactionFees = append(transactionFees, transactionFee)
+	}
+
+	return transactionFees, nil
+}
+
+func (r *TransactionFeeRepository) UpdateTransactionFeeStatusByHash(ctx context.Context, transactionHash string, status string) error {
+	sqlStatement := `UPDATE transaction_fees SET status = $2, updated_at = NOW() WHERE transaction_hash = $1`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, transactionHash, status)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
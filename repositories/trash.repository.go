@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TrashRepository struct {
+	db *sql.DB
+}
+
+func NewTrashRepository(db *sql.DB) *TrashRepository {
+	return &TrashRepository{db}
+}
+
+// The table names come from helpers.SoftDeleteTables, never from the request
+func (r *TrashRepository) GetDeletedList(ctx context.Context, recordType string, table string, offset int, limit int) ([]models.DeletedRecord, error) {
+	var deletedRecords []models.DeletedRecord
+
+	sqlStatement := fmt.Sprintf(`SELECT id, row_to_json(%[1]s), deleted_at FROM %[1]s
+					WHERE deleted_at IS NOT NULL
+					ORDER BY deleted_at DESC OFFSET $1 LIMIT $2`, table)
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, offset, limit)
+
+	if err != nil {
+		return deletedRecords, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var deletedRecord models.DeletedRecord
+		var data []byte
+
+		err = rows.Scan(&deletedRecord.ID, &data, &deletedRecord.DeletedAt)
+
+		if err != nil {
+			return deletedRecords, err
+		}
+
+		deletedRecord.Type = recordType
+		deletedRecord.Data = data
+
+		deletedRecords = append(deletedRecords, deletedRecord)
+	}
+
+	return deletedRecords, nil
+}
+
+// Restore returns false when the row does not exist or is not deleted
+func (r *TrashRepository) Restore(ctx context.Context, table string, id uuid.UUID) (bool, error) {
+	sqlStatement := fmt.Sprintf(`UPDATE %s SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`, table)
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// PurgeDeleted permanently removes the rows deleted before the given time
+func (r *TrashRepository) PurgeDeleted(ctx context.Context, table string, before time.Time) (int64, error) {
+	sqlStatement := fmt.Sprintf(`DELETE FROM %s WHERE deleted_at IS NOT NULL AND deleted_at < $1`, table)
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, before)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	models "metaedu-marketplace/models"
@@ -16,7 +17,7 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	return &UserRepository{db}
 }
 
-func (r *UserRepository) InsertUser(user models.User) string {
+func (r *UserRepository) InsertUser(ctx context.Context, user models.User) string {
 	sqlStatement := `INSERT INTO users (
 		name,
 		email,
@@ -36,7 +37,14 @@ func (r *UserRepository) InsertUser(user models.User) string {
 
 	var id string
 
-	err := r.db.QueryRow(sqlStatement, user.Name, user.Email, user.Photo, user.Cover, user.Verified, user.Role, user.Address, user.Nonce, user.Status, user.UpdatedAt, user.CreatedAt).Scan(&id)
+	err := r.db.QueryRowContext(ctx, sqlStatement, user.Name, user.Email, user.Photo, user.Cover, user.Verified, user.Role, user.Address, user.Nonce, user.Status, user.UpdatedAt, user.CreatedAt).Scan(&id)
+
+	if err != nil {
+		log.Fatalf("Query is cannot executed %v", err)
+	}
+
+	// Sign up address is the primary linked address
+	_, err = r.db.ExecContext(ctx, `INSERT INTO user_addresses (user_id, address, "primary") VALUES ($1, LOWER($2), true)`, id, user.Address)
 
 	if err != nil {
 		log.Fatalf("Query is cannot executed %v", err)
@@ -45,12 +53,12 @@ func (r *UserRepository) InsertUser(user models.User) string {
 	return id
 }
 
-func (r *UserRepository) GetUserByID(id uuid.UUID) (models.User, error) {
-	sqlStatement := `SELECT id, name, email, photo, cover, role, address, nonce, updated_at, created_at FROM users where id = $1 LIMIT 1`
+func (r *UserRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, error) {
+	sqlStatement := `SELECT id, name, email, photo, cover, verified, email_verified_at, role, address, nonce, moderation_status, status, updated_at, created_at FROM users where id = $1 LIMIT 1`
 
 	var user models.User
 
-	rows, err := r.db.Query(sqlStatement, id)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
 
 	if err != nil {
 		return user, err
@@ -59,7 +67,7 @@ func (r *UserRepository) GetUserByID(id uuid.UUID) (models.User, error) {
 	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.Photo, &user.Cover, &user.Role, &user.Address, &user.Nonce, &user.UpdatedAt, &user.CreatedAt)
+		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.Photo, &user.Cover, &user.Verified, &user.EmailVerifiedAt, &user.Role, &user.Address, &user.Nonce, &user.ModerationStatus, &user.Status, &user.UpdatedAt, &user.CreatedAt)
 		if err != nil {
 			return user, err
 		}
@@ -68,7 +76,7 @@ func (r *UserRepository) GetUserByID(id uuid.UUID) (models.User, error) {
 	return user, err
 }
 
-func (r *UserRepository) GetSystemUser() (models.User, error) {
+func (r *UserRepository) GetSystemUser(ctx context.Context) (models.User, error) {
 	sqlStatement := `SELECT id, name, email, photo, cover, role, address, nonce, updated_at, created_at 
 					FROM users 
 					WHERE role='admin' 
@@ -76,7 +84,7 @@ func (r *UserRepository) GetSystemUser() (models.User, error) {
 
 	var user models.User
 
-	rows, err := r.db.Query(sqlStatement)
+	rows, err := r.db.QueryContext(ctx, sqlStatement)
 
 	if err != nil {
 		return user, err
@@ -94,16 +102,20 @@ func (r *UserRepository) GetSystemUser() (models.User, error) {
 	return user, err
 }
 
-func (r *UserRepository) GetUserByAddress(address string) models.User {
-	sqlStatement := `SELECT id, name, email, photo, role, address, nonce, created_at, updated_at FROM users where address = $1 LIMIT 1`
+func (r *UserRepository) GetUserByAddress(ctx context.Context, address string) models.User {
+	sqlStatement := `SELECT users.id, users.name, users.email, users.photo, users.verified, users.email_verified_at, users.role, users.address, users.nonce, users.moderation_status, users.status, users.created_at, users.updated_at 
+					FROM users 
+					INNER JOIN user_addresses ON user_addresses.user_id = users.id 
+					WHERE user_addresses.address = LOWER($1) 
+					LIMIT 1`
 
 	var user models.User
 
-	rows, err := r.db.Query(sqlStatement, address)
+	rows, err := r.db.QueryContext(ctx, sqlStatement, address)
 
 	defer rows.Close()
 	for rows.Next() {
-		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.Photo, &user.Role, &user.Address, &user.Nonce, &user.CreatedAt, &user.UpdatedAt)
+		err = rows.Scan(&user.ID, &user.Name, &user.Email, &user.Photo, &user.Verified, &user.EmailVerifiedAt, &user.Role, &user.Address, &user.Nonce, &user.ModerationStatus, &user.Status, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
 			return user
 		}
@@ -112,12 +124,12 @@ func (r *UserRepository) GetUserByAddress(address string) models.User {
 	return user
 }
 
-func (r *UserRepository) UpdateUser(id uuid.UUID, user models.User) error {
+func (r *UserRepository) UpdateUser(ctx context.Context, id uuid.UUID, user models.User) error {
 	sqlStatement := `UPDATE users
-	SET name = $2, email = $3, photo = $4, cover = $5, verified = $6, role = $7, address = $8, nonce = $9, status = $10, updated_at = $11
+	SET name = $2, email = $3, photo = $4, cover = $5, verified = $6, email_verified_at = $7, role = $8, address = $9, nonce = $10, status = $11, updated_at = $12
 	WHERE id = $1;`
 
-	_, err := r.db.Exec(sqlStatement, user.ID, user.Name, user.Email, user.Photo, user.Cover, user.Verified, user.Role, user.Address, user.Nonce, user.Status, user.UpdatedAt)
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, user.Name, user.Email, user.Photo, user.Cover, user.Verified, user.EmailVerifiedAt, user.Role, user.Address, user.Nonce, user.Status, user.UpdatedAt)
 
 	if err != nil {
 		log.Fatalf("Query is cannot executed %v", err)
@@ -125,3 +137,87 @@ func (r *UserRepository) UpdateUser(id uuid.UUID, user models.User) error {
 
 	return err
 }
+
+func (r *UserRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (bool, error) {
+	sqlStatement := `UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, id, role)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	return count > 0, err
+}
+
+// VerifyUserEmail only verifies the email the link was issued for
+func (r *UserRepository) VerifyUserEmail(ctx context.Context, id uuid.UUID, email string) (bool, error) {
+	sqlStatement := `UPDATE users SET verified = true, email_verified_at = NOW(), updated_at = NOW() WHERE id = $1 AND email = $2`
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, id, email)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	return count > 0, err
+}
+
+func (r *UserRepository) GetUserAddressList(ctx context.Context, userID uuid.UUID) ([]models.UserAddress, error) {
+	var userAddresses []models.UserAddress
+
+	sqlStatement := `SELECT id, user_id, address, "primary", created_at FROM user_addresses WHERE user_id = $1 ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, userID)
+
+	if err != nil {
+		return userAddresses, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var userAddress models.UserAddress
+		err = rows.Scan(&userAddress.ID, &userAddress.UserID, &userAddress.Address, &userAddress.Primary, &userAddress.CreatedAt)
+
+		if err != nil {
+			return userAddresses, err
+		}
+
+		userAddresses = append(userAddresses, userAddress)
+	}
+
+	return userAddresses, nil
+}
+
+func (r *UserRepository) InsertUserAddress(ctx context.Context, userAddress models.UserAddress) (string, error) {
+	sqlStatement := `INSERT INTO user_addresses (user_id, address, "primary") VALUES ($1, LOWER($2), $3) RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, userAddress.UserID, userAddress.Address, userAddress.Primary).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// DeleteUserAddress unlinks a secondary address, the primary address can not be removed
+func (r *UserRepository) DeleteUserAddress(ctx context.Context, userID uuid.UUID, address string) (bool, error) {
+	sqlStatement := `DELETE FROM user_addresses WHERE user_id = $1 AND address = LOWER($2) AND "primary" = false`
+
+	result, err := r.db.ExecContext(ctx, sqlStatement, userID, address)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	return count > 0, err
+}
@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+// Each statement applies a typed diff from $2, fields missing from the diff keep their value
+var pendingChangeStatements = map[string]string{
+	helpers.VersionResourceToken: `UPDATE tokens
+	SET fraction_id = COALESCE(($2::JSONB->>'fraction_id')::UUID, fraction_id),
+		source_id = COALESCE(($2::JSONB->>'source_id')::UUID, source_id),
+		updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL`,
+	helpers.VersionResourceOwnership: `UPDATE ownerships
+	SET user_id = COALESCE(($2::JSONB->>'user_id')::UUID, user_id),
+		quantity = quantity + COALESCE(($2::JSONB->>'quantity_delta')::NUMERIC, 0),
+		sale_price = COALESCE(($2::JSONB->>'sale_price')::DOUBLE PRECISION, sale_price),
+		rent_cost = COALESCE(($2::JSONB->>'rent_cost')::DOUBLE PRECISION, rent_cost),
+		available_for_sale = COALESCE(($2::JSONB->>'available_for_sale')::BOOLEAN, available_for_sale),
+		available_for_rent = COALESCE(($2::JSONB->>'available_for_rent')::BOOLEAN, available_for_rent),
+		status = CASE WHEN quantity + COALESCE(($2::JSONB->>'quantity_delta')::NUMERIC, 0) > 0 THEN 'active' ELSE 'inactive' END,
+		updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL`,
+	helpers.VersionResourceRental: `UPDATE rentals
+	SET timestamp = COALESCE(($2::JSONB->>'timestamp')::TIMESTAMP, timestamp),
+		days = days + COALESCE(($2::JSONB->>'extend_days')::INTEGER, 0),
+		amount = amount + COALESCE(($2::JSONB->>'extend_amount')::DOUBLE PRECISION, 0),
+		status = COALESCE($2::JSONB->>'status', status),
+		updated_at = NOW()
+	WHERE id = $1 AND deleted_at IS NULL`,
+}
+
+type VersionRepository struct {
+	db *sql.DB
+}
+
+func NewVersionRepository(db *sql.DB) *VersionRepository {
+	return &VersionRepository{db}
+}
+
+func (r *VersionRepository) InsertPendingChange(ctx context.Context, resourceType string, resourceID uuid.UUID, changes any, transactionHash string) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return id, err
+	}
+
+	defer tx.Rollback()
+
+	id, err = insertPendingChange(ctx, tx, resourceType, resourceID, changes, transactionHash)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, tx.Commit()
+}
+
+// insertPendingChange lets a repository queue a change in the transaction that checked it
+func insertPendingChange(ctx context.Context, tx *sql.Tx, resourceType string, resourceID uuid.UUID, changes any, transactionHash string) (uuid.UUID, error) {
+	var id uuid.UUID
+
+	encodedChanges, err := json.Marshal(changes)
+
+	if err != nil {
+		return id, err
+	}
+
+	sqlStatement := `INSERT INTO pending_changes (
+		resource_type,
+		resource_id,
+		changes,
+		transaction_hash,
+		status
+	  ) VALUES (
+		$1, $2, $3, $4, $5
+	  )
+	  RETURNING id`
+
+	err = tx.QueryRowContext(ctx, sqlStatement, resourceType, resourceID, encodedChanges, transactionHash, helpers.PendingChangeStatusPending).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *VersionRepository) GetPendingChangeList(ctx context.Context, offset int, limit int, resourceType *string, resourceID *uuid.UUID, status *string) ([]models.PendingChange, error) {
+	var pendingChanges []models.PendingChange
+
+	sqlStatement := `SELECT id, resource_type, resource_id, changes, transaction_hash, status, applied_at, updated_at, created_at
+					FROM pending_changes
+					WHERE (resource_type = $3 OR $3 IS NULL) AND (resource_id = $4 OR $4 IS NULL) AND (status = $5 OR $5 IS NULL)
+					ORDER BY created_at ASC OFFSET $1 LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, offset, limit, helpers.GetOptionalStringParams(resourceType), helpers.GetOptionalUUIDParams(resourceID), helpers.GetOptionalStringParams(status))
+
+	if err != nil {
+		return pendingChanges, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var pendingChange models.PendingChange
+		var changes []byte
+
+		err = rows.Scan(&pendingChange.ID, &pendingChange.ResourceType, &pendingChange.ResourceID, &changes, &pendingChange.TransactionHash, &pendingChange.Status, &pendingChange.AppliedAt, &pendingChange.UpdatedAt, &pendingChange.CreatedAt)
+
+		if err != nil {
+			return pendingChanges, err
+		}
+
+		pendingChange.Changes = changes
+
+		pendingChanges = append(pendingChanges, pendingChange)
+	}
+
+	return pendingChanges, nil
+}
+
+func (r *VersionRepository) UpdatePendingChangeStatus(ctx context.Context, id uuid.UUID, status string) error {
+	sqlStatement := `UPDATE pending_changes SET status = $2, updated_at = NOW() WHERE id = $1 AND status = 'pending'`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, status)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func insertResourceVersion(ctx context.Context, tx *sql.Tx, resourceType string, resourceID uuid.UUID, changeID uuid.UUID, transactionHash string) error {
+	table := helpers.VersionTables[resourceType]
+
+	sqlStatement := `INSERT INTO resource_versions (resource_type, resource_id, version, data, change_id, transaction_hash)
+					SELECT $1, t.id, COALESCE((SELECT MAX(version) FROM resource_versions WHERE resource_type = $1 AND resource_id = t.id), 0) + 1, row_to_json(t)::JSONB, $3, $4
+					FROM ` + table + ` t WHERE t.id = $2`
+
+	_, err := tx.ExecContext(ctx, sqlStatement, resourceType, resourceID, helpers.GetNullableUUIDParams(changeID), transactionHash)
+
+	return err
+}
+
+// ApplyPendingChange writes the diff onto the resource and records the new version in one transaction,
+// sql.ErrNoRows is returned when the change is no longer pending or the resource is gone
+func (r *VersionRepository) ApplyPendingChange(ctx context.Context, pendingChange models.PendingChange) error {
+	defer auditChange(ctx, r.db, helpers.VersionTables[pendingChange.ResourceType], pendingChange.ResourceID, "ApplyPendingChange")()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var status string
+
+	err = tx.QueryRowContext(ctx, `SELECT status FROM pending_changes WHERE id = $1 FOR UPDATE`, pendingChange.ID).Scan(&status)
+
+	if err != nil {
+		return err
+	}
+
+	if status != helpers.PendingChangeStatusPending {
+		return sql.ErrNoRows
+	}
+
+	result, err := tx.ExecContext(ctx, pendingChangeStatements[pendingChange.ResourceType], pendingChange.ResourceID, []byte(pendingChange.Changes))
+
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return sql.ErrNoRows
+	}
+
+	err = insertResourceVersion(ctx, tx, pendingChange.ResourceType, pendingChange.ResourceID, pendingChange.ID, pendingChange.TransactionHash)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE pending_changes SET status = $2, applied_at = NOW(), updated_at = NOW() WHERE id = $1`, pendingChange.ID, helpers.PendingChangeStatusApplied)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// InsertResourceVersion records the current state of a resource, used when a new resource is confirmed
+func (r *VersionRepository) InsertResourceVersion(ctx context.Context, resourceType string, resourceID uuid.UUID, transactionHash string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = insertResourceVersion(ctx, tx, resourceType, resourceID, helpers.GetEmptyUUID(), transactionHash)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *VersionRepository) GetResourceVersionList(ctx context.Context, resourceType string, resourceID uuid.UUID, offset int, limit int) ([]models.ResourceVersion, error) {
+	var resourceVersions []models.ResourceVersion
+
+	sqlStatement := `SELECT id, resource_type, resource_id, version, data, change_id, COALESCE(transaction_hash, ''), created_at
+					FROM resource_versions
+					WHERE resource_type = $1 AND resource_id = $2
+					ORDER BY version DESC OFFSET $3 LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, resourceType, resourceID, offset, limit)
+
+	if err != nil {
+		return resourceVersions, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var resourceVersion models.ResourceVersion
+		var data []byte
+
+		err = rows.Scan(&resourceVersion.ID, &resourceVersion.ResourceType, &resourceVersion.ResourceID, &resourceVersion.Version, &data, &resourceVersion.ChangeID, &resourceVersion.TransactionHash, &resourceVersion.CreatedAt)
+
+		if err != nil {
+			return resourceVersions, err
+		}
+
+		resourceVersion.Data = data
+
+		resourceVersions = append(resourceVersions, resourceVersion)
+	}
+
+	return resourceVersions, nil
+}
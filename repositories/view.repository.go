@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"metaedu-marketplace/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ViewRepository struct {
+	db *sql.DB
+}
+
+func NewViewRepository(db *sql.DB) *ViewRepository {
+	return &ViewRepository{db}
+}
+
+func getViewTable(viewType string) string {
+	if viewType == utils.ViewTypeCollection {
+		return "collections"
+	}
+
+	return "tokens"
+}
+
+// Add a batch of deduplicated views to the counters and to the hourly buckets in a single transaction
+func (r *ViewRepository) FlushViews(ctx context.Context, viewType string, counts map[uuid.UUID]int) error {
+	var ids []string
+	var views []int64
+
+	for id, count := range counts {
+		ids = append(ids, id.String())
+		views = append(views, int64(count))
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET views = %s.views + batch.views
+					FROM (SELECT UNNEST($1::UUID[]) AS id, UNNEST($2::INTEGER[]) AS views) batch
+					WHERE %s.id = batch.id`, getViewTable(viewType), getViewTable(viewType), getViewTable(viewType)), pq.Array(ids), pq.Array(views))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO view_buckets (type, subject_id, bucket, views)
+					SELECT $1, batch.id, DATE_TRUNC('hour', $4::TIMESTAMP), batch.views
+					FROM (SELECT UNNEST($2::UUID[]) AS id, UNNEST($3::INTEGER[]) AS views) batch
+					ON CONFLICT (type, subject_id, bucket) DO UPDATE SET views = view_buckets.views + EXCLUDED.views`, viewType, pq.Array(ids), pq.Array(views), time.Now().UTC())
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Score the recent views and sales with an exponential decay
+func (r *ViewRepository) RecomputeTrending(ctx context.Context, viewType string) error {
+	subjectColumn := "token_id"
+
+	if viewType == utils.ViewTypeCollection {
+		subjectColumn = "collection_id"
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM trending WHERE type = $1`, viewType)
+
+	if err != nil {
+		return err
+	}
+
+	sqlStatement := `INSERT INTO trending (type, subject_id, score, views, sales, computed_at)
+					SELECT $1, activity.subject_id, SUM(activity.score), SUM(activity.views), SUM(activity.sales), NOW()
+					FROM (
+						SELECT subject_id, views * POWER(0.5, EXTRACT(EPOCH FROM (NOW() - bucket)) / $3) AS score, views, 0 AS sales
+						FROM view_buckets
+						WHERE type = $1 AND bucket >= $2
+						UNION ALL
+						SELECT ` + subjectColumn + `, $4 * POWER(0.5, EXTRACT(EPOCH FROM (NOW() - created_at)) / $3), 0, 1
+						FROM price_points
+						WHERE type = 'sale' AND created_at >= $2 AND ` + subjectColumn + ` IS NOT NULL
+					) activity
+					GROUP BY activity.subject_id
+					ORDER BY SUM(activity.score) DESC
+					LIMIT $5`
+
+	_, err = tx.ExecContext(ctx, sqlStatement, viewType, time.Now().UTC().Add(-helpers.TrendingWindow), helpers.TrendingHalfLife.Seconds(), helpers.TrendingSaleWeight, helpers.TrendingSize)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *ViewRepository) GetTrendingList(ctx context.Context, viewType string, limit int) ([]models.Trending, error) {
+	var trendings []models.Trending
+
+	sqlStatement := `SELECT trending.type, trending.subject_id, COALESCE(tokens.title, collections.title, ''), COALESCE(tokens.image, collections.thumbnail, ''),
+						trending.score, trending.views, trending.sales, trending.computed_at
+					FROM trending
+					LEFT JOIN tokens ON trending.type = 'token' AND tokens.id = trending.subject_id
+					LEFT JOIN collections ON trending.type = 'collection' AND collections.id = trending.subject_id
+					WHERE trending.type = $1 AND (tokens.id IS NULL OR ` + visibleTokenStatement + `) AND (collections.id IS NULL OR ` + visibleCollectionStatement + `)
+					ORDER BY trending.score DESC
+					LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, viewType, limit)
+
+	if err != nil {
+		return trendings, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var trending models.Trending
+		err = rows.Scan(&trending.Type, &trending.SubjectID, &trending.Title, &trending.Image, &trending.Score, &trending.Views, &trending.Sales, &trending.ComputedAt)
+
+		if err != nil {
+			return trendings, err
+		}
+
+		trendings = append(trendings, trending)
+	}
+
+	return trendings, nil
+}
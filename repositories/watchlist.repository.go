@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+
+	"github.com/google/uuid"
+)
+
+type WatchlistRepository struct {
+	db *sql.DB
+}
+
+func NewWatchlistRepository(db *sql.DB) *WatchlistRepository {
+	return &WatchlistRepository{db}
+}
+
+// Insert the watchlist or replace the alert settings of an existing one
+func (r *WatchlistRepository) UpsertWatchlist(ctx context.Context, watchlist models.Watchlist) (string, error) {
+	conflictTarget := `(user_id, token_id) WHERE token_id IS NOT NULL`
+
+	if watchlist.TokenID == helpers.GetEmptyUUID() {
+		conflictTarget = `(user_id, collection_id) WHERE collection_id IS NOT NULL`
+	}
+
+	sqlStatement := `INSERT INTO watchlists (
+		user_id,
+		token_id,
+		collection_id,
+		notify_sale,
+		notify_rent,
+		price_below,
+		notify_floor
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7
+	  )
+	  ON CONFLICT ` + conflictTarget + ` DO UPDATE
+	  SET notify_sale = EXCLUDED.notify_sale, notify_rent = EXCLUDED.notify_rent, price_below = EXCLUDED.price_below, notify_floor = EXCLUDED.notify_floor, updated_at = NOW()
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, watchlist.UserID, helpers.GetNullableUUIDParams(watchlist.TokenID), helpers.GetNullableUUIDParams(watchlist.CollectionID), watchlist.NotifySale, watchlist.NotifyRent, watchlist.PriceBelow, watchlist.NotifyFloor).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *WatchlistRepository) GetWatchlistList(ctx context.Context, offset int, limit int, userID *uuid.UUID, tokenID *uuid.UUID, collectionID *uuid.UUID) ([]models.Watchlist, error) {
+	var watchlists []models.Watchlist
+
+	sqlStatement := `SELECT id, user_id, token_id, collection_id, notify_sale, notify_rent, price_below, notify_floor, created_at, updated_at
+					FROM watchlists
+					WHERE (user_id = $1 OR $1 IS NULL) AND (token_id = $2 OR $2 IS NULL) AND (collection_id = $3 OR $3 IS NULL)
+					ORDER BY created_at DESC
+					OFFSET $4
+					LIMIT $5`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(userID), helpers.GetOptionalUUIDParams(tokenID), helpers.GetOptionalUUIDParams(collectionID), offset, limit)
+
+	if err != nil {
+		return watchlists, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var watchlist models.Watchlist
+		err = rows.Scan(&watchlist.ID, &watchlist.UserID, &watchlist.TokenID, &watchlist.CollectionID, &watchlist.NotifySale, &watchlist.NotifyRent, &watchlist.PriceBelow, &watchlist.NotifyFloor, &watchlist.CreatedAt, &watchlist.UpdatedAt)
+
+		if err != nil {
+			return watchlists, err
+		}
+
+		watchlists = append(watchlists, watchlist)
+	}
+
+	return watchlists, nil
+}
+
+func (r *WatchlistRepository) DeleteWatchlist(ctx context.Context, userID uuid.UUID, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM watchlists WHERE id = $1 AND user_id = $2`, id, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"metaedu-marketplace/helpers"
+	models "metaedu-marketplace/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db}
+}
+
+func (r *WebhookRepository) InsertWebhookEndpoint(ctx context.Context, webhookEndpoint models.WebhookEndpoint) (string, error) {
+	sqlStatement := `INSERT INTO webhook_endpoints (
+		user_id,
+		url,
+		secret,
+		event_types,
+		status,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, webhookEndpoint.UserID, webhookEndpoint.Url, webhookEndpoint.Secret, pq.Array(webhookEndpoint.EventTypes), webhookEndpoint.Status, webhookEndpoint.UpdatedAt, webhookEndpoint.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+func (r *WebhookRepository) GetWebhookEndpointList(ctx context.Context, offset int, limit int, userID *uuid.UUID) ([]models.WebhookEndpoint, error) {
+	var webhookEndpoints []models.WebhookEndpoint
+
+	sqlStatement := `SELECT id, user_id, url, event_types, status, updated_at, created_at
+					FROM webhook_endpoints
+					WHERE (user_id = $1 OR $1 IS NULL)
+					ORDER BY created_at DESC
+					OFFSET $2
+					LIMIT $3`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, helpers.GetOptionalUUIDParams(userID), offset, limit)
+
+	if err != nil {
+		return webhookEndpoints, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var webhookEndpoint models.WebhookEndpoint
+		err = rows.Scan(&webhookEndpoint.ID, &webhookEndpoint.UserID, &webhookEndpoint.Url, pq.Array(&webhookEndpoint.EventTypes), &webhookEndpoint.Status, &webhookEndpoint.UpdatedAt, &webhookEndpoint.CreatedAt)
+
+		if err != nil {
+			return webhookEndpoints, err
+		}
+
+		webhookEndpoints = append(webhookEndpoints, webhookEndpoint)
+	}
+
+	return webhookEndpoints, nil
+}
+
+// GetWebhookEndpointListByEventType returns the endpoints allowed to see the event: admin endpoints get everything,
+// partner endpoints only events where the partner is one of the users or created the token or its collection
+func (r *WebhookRepository) GetWebhookEndpointListByEventType(ctx context.Context, eventType string, tokenID uuid.UUID, userIDs []uuid.UUID) ([]models.WebhookEndpoint, error) {
+	var webhookEndpoints []models.WebhookEndpoint
+
+	sqlStatement := `SELECT e.id, e.user_id, e.url, e.secret, e.event_types, e.status, e.updated_at, e.created_at
+					FROM webhook_endpoints e
+					JOIN users u ON u.id = e.user_id
+					WHERE e.status = 'active' AND $1 = ANY(e.event_types)
+					AND (u.role = 'admin' OR (u.role = 'partner' AND (
+						e.user_id = ANY($2::uuid[])
+						OR e.user_id IN (SELECT t.creator_id FROM tokens t WHERE t.id = $3)
+						OR e.user_id IN (SELECT c.creator_id FROM tokens t JOIN collections c ON c.id = t.collection_id WHERE t.id = $3)
+					)))`
+
+	var userIDParams []string
+
+	for _, userID := range userIDs {
+		userIDParams = append(userIDParams, userID.String())
+	}
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, eventType, pq.Array(userIDParams), tokenID)
+
+	if err != nil {
+		return webhookEndpoints, err
+	}
+
+	defer rows.Close()
+	for rows.Next() {
+		var webhookEndpoint models.WebhookEndpoint
+		err = rows.Scan(&webhookEndpoint.ID, &webhookEndpoint.UserID, &webhookEndpoint.Url, &webhookEndpoint.Secret, pq.Array(&webhookEndpoint.EventTypes), &webhookEndpoint.Status, &webhookEndpoint.UpdatedAt, &webhookEndpoint.CreatedAt)
+
+		if err != nil {
+			return webhookEndpoints, err
+		}
+
+		webhookEndpoints = append(webhookEndpoints, webhookEndpoint)
+	}
+
+	return webhookEndpoints, nil
+}
+
+func (r *WebhookRepository) GetWebhookEndpointData(ctx context.Context, id uuid.UUID) (models.WebhookEndpoint, error) {
+	var webhookEndpoint models.WebhookEndpoint
+
+	sqlStatement := `SELECT id, user_id, url, secret, event_types, status, updated_at, created_at FROM webhook_endpoints WHERE id = $1`
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, id).Scan(&webhookEndpoint.ID, &webhookEndpoint.UserID, &webhookEndpoint.Url, &webhookEndpoint.Secret, pq.Array(&webhookEndpoint.EventTypes), &webhookEndpoint.Status, &webhookEndpoint.UpdatedAt, &webhookEndpoint.CreatedAt)
+
+	if err != nil && err != sql.ErrNoRows {
+		return webhookEndpoint, err
+	}
+
+	return webhookEndpoint, nil
+}
+
+func (r *WebhookRepository) UpdateWebhookEndpoint(ctx context.Context, id uuid.UUID, webhookEndpoint models.WebhookEndpoint) error {
+	sqlStatement := `UPDATE webhook_endpoints
+	SET url = $2, event_types = $3, status = $4, updated_at = $5
+	WHERE id = $1;`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, webhookEndpoint.Url, pq.Array(webhookEndpoint.EventTypes), webhookEndpoint.Status, webhookEndpoint.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) error {
+	sqlStatement := `DELETE FROM webhook_endpoints WHERE id = $1;`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) InsertWebhookDelivery(ctx context.Context, webhookDelivery models.WebhookDelivery) (string, error) {
+	sqlStatement := `INSERT INTO webhook_deliveries (
+		endpoint_id,
+		event_type,
+		payload,
+		status,
+		next_attempt_at,
+		updated_at,
+		created_at
+	  ) VALUES (
+		$1, $2, $3, $4, $5, $6, $7
+	  )
+	  RETURNING id`
+
+	var id string
+
+	err := r.db.QueryRowContext(ctx, sqlStatement, webhookDelivery.EndpointID, webhookDelivery.EventType, []byte(webhookDelivery.Payload), webhookDelivery.Status, webhookDelivery.NextAttemptAt, webhookDelivery.UpdatedAt, webhookDelivery.CreatedAt).Scan(&id)
+
+	if err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+const webhookDeliveryColumns = `id, endpoint_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, updated_at, created_at`
+
+func scanWebhookDeliveryRows(rows *sql.Rows) ([]models.WebhookDelivery, error) {
+	var webhookDeliveries []models.WebhookDelivery
+
+	defer rows.Close()
+	for rows.Next() {
+		var webhookDelivery models.WebhookDelivery
+		err := rows.Scan(&webhookDelivery.ID, &webhookDelivery.EndpointID, &webhookDelivery.EventType, &webhookDelivery.Payload, &webhookDelivery.Status, &webhookDelivery.Attempts, &webhookDelivery.ResponseStatus, &webhookDelivery.LastError, &webhookDelivery.NextAttemptAt, &webhookDelivery.DeliveredAt, &webhookDelivery.UpdatedAt, &webhookDelivery.CreatedAt)
+
+		if err != nil {
+			return webhookDeliveries, err
+		}
+
+		webhookDeliveries = append(webhookDeliveries, webhookDelivery)
+	}
+
+	return webhookDeliveries, nil
+}
+
+func (r *WebhookRepository) GetWebhookDeliveryList(ctx context.Context, offset int, limit int, endpointID uuid.UUID, status *string) ([]models.WebhookDelivery, error) {
+	sqlStatement := `SELECT ` + webhookDeliveryColumns + `
+					FROM webhook_deliveries
+					WHERE endpoint_id = $1 AND (status = $2 OR $2 IS NULL)
+					ORDER BY created_at DESC
+					OFFSET $3
+					LIMIT $4`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, endpointID, helpers.GetOptionalStringParams(status), offset, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookDeliveryRows(rows)
+}
+
+// GetDueWebhookDeliveryList returns pending and failed deliveries whose next attempt is due
+func (r *WebhookRepository) GetDueWebhookDeliveryList(ctx context.Context, at time.Time, limit int) ([]models.WebhookDelivery, error) {
+	sqlStatement := `SELECT ` + webhookDeliveryColumns + `
+					FROM webhook_deliveries
+					WHERE status IN ('pending', 'failed') AND next_attempt_at <= $1
+					ORDER BY next_attempt_at ASC
+					LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, at, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookDeliveryRows(rows)
+}
+
+func (r *WebhookRepository) GetWebhookDeliveryData(ctx context.Context, id uuid.UUID) (models.WebhookDelivery, error) {
+	sqlStatement := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+
+	rows, err := r.db.QueryContext(ctx, sqlStatement, id)
+
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+
+	webhookDeliveries, err := scanWebhookDeliveryRows(rows)
+
+	if err != nil || len(webhookDeliveries) == 0 {
+		return models.WebhookDelivery{}, err
+	}
+
+	return webhookDeliveries[0], nil
+}
+
+func (r *WebhookRepository) UpdateWebhookDelivery(ctx context.Context, id uuid.UUID, webhookDelivery models.WebhookDelivery) error {
+	sqlStatement := `UPDATE webhook_deliveries
+	SET status = $2, attempts = $3, response_status = $4, last_error = $5, next_attempt_at = $6, delivered_at = $7, updated_at = $8
+	WHERE id = $1;`
+
+	_, err := r.db.ExecContext(ctx, sqlStatement, id, webhookDelivery.Status, webhookDelivery.Attempts, webhookDelivery.ResponseStatus, webhookDelivery.LastError, webhookDelivery.NextAttemptAt, webhookDelivery.DeliveredAt, webhookDelivery.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	auditController         controllers.AuditController
+}
+
+func NewAuditRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, auditController controllers.AuditController) AuditRoutes {
+	return AuditRoutes{authorizationMiddleware, auditController}
+}
+
+func (rc *AuditRoutes) AuditRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/audit")
+
+	router.GET("/verify", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.auditController.VerifyAuditChain)
+	router.GET("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.auditController.GetAuditLogData)
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.auditController.GetAuditLogList)
+}
@@ -21,9 +21,10 @@ func (rc *CollectionRoutes) CollectionRoute(rg *gin.RouterGroup) {
 	router := rg.Group("/collection")
 
 	router.GET("/:id/transaction", rc.collectionController.GetCollectionTransactionList)
-	router.GET("/:id", rc.collectionController.GetCollectionData)
+	router.GET("/:id/stats", rc.collectionController.GetCollectionStats)
+	router.GET("/:id", rc.authorizationMiddleware.VerifyOptionalToken, rc.collectionController.GetCollectionData)
 	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.collectionController.UpdateCollection)
-	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.collectionController.DeleteCollection)
+	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.collectionController.DeleteCollection)
 	router.POST("/", rc.authorizationMiddleware.VerifyToken, rc.collectionController.InsertCollection)
 	router.GET("/", rc.collectionController.GetCollectionList)
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	eventController         controllers.EventController
+}
+
+func NewEventRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, eventController controllers.EventController) EventRoutes {
+	return EventRoutes{authorizationMiddleware, eventController}
+}
+
+func (rc *EventRoutes) EventRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/event")
+
+	router.GET("/stream", rc.authorizationMiddleware.VerifyOptionalToken, rc.eventController.StreamEvents)
+	router.GET("/ws", rc.authorizationMiddleware.VerifyOptionalToken, rc.eventController.SubscribeEvents)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FavoriteRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	favoriteController      controllers.FavoriteController
+}
+
+func NewFavoriteRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, favoriteController controllers.FavoriteController) FavoriteRoutes {
+	return FavoriteRoutes{authorizationMiddleware, favoriteController}
+}
+
+func (rc *FavoriteRoutes) FavoriteRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/favorite")
+
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.favoriteController.GetFavoriteList)
+	router.POST("/:type/:id", rc.authorizationMiddleware.VerifyToken, rc.favoriteController.InsertFavorite)
+	router.DELETE("/:type/:id", rc.authorizationMiddleware.VerifyToken, rc.favoriteController.DeleteFavorite)
+}
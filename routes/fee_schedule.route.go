@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeeScheduleRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	feeScheduleController   controllers.FeeScheduleController
+}
+
+func NewFeeScheduleRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, feeScheduleController controllers.FeeScheduleController) FeeScheduleRoutes {
+	return FeeScheduleRoutes{authorizationMiddleware, feeScheduleController}
+}
+
+func (rc *FeeScheduleRoutes) FeeScheduleRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/fee-schedule")
+	router.GET("/quote", rc.feeScheduleController.GetFeeQuote)
+	router.GET("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.feeScheduleController.GetFeeScheduleData)
+	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.feeScheduleController.UpdateFeeSchedule)
+	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.feeScheduleController.DeleteFeeSchedule)
+	router.POST("/", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.feeScheduleController.InsertFeeSchedule)
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.feeScheduleController.GetFeeScheduleList)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	followController        controllers.FollowController
+}
+
+func NewFollowRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, followController controllers.FollowController) FollowRoutes {
+	return FollowRoutes{authorizationMiddleware, followController}
+}
+
+func (rc *FollowRoutes) FollowRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/follow")
+
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.followController.GetFollowList)
+	router.POST("/:type/:id", rc.authorizationMiddleware.VerifyToken, rc.followController.InsertFollow)
+	router.DELETE("/:type/:id", rc.authorizationMiddleware.VerifyToken, rc.followController.DeleteFollow)
+
+	rg.GET("/feed", rc.authorizationMiddleware.VerifyToken, rc.followController.GetFeed)
+}
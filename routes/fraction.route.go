@@ -19,6 +19,10 @@ func NewFractionRoutes(authorizationMiddleware middlewares.AuthorizationMiddlewa
 func (rc *FractionRoutes) FractionRoute(rg *gin.RouterGroup) {
 
 	router := rg.Group("/fraction")
+	router.GET("/:id/shares", rc.fractionController.GetFractionShareList)
+	router.GET("/:id/buyouts", rc.fractionController.GetFractionBuyoutList)
+	router.POST("/:id/redeem", rc.authorizationMiddleware.VerifyToken, rc.fractionController.RedeemFraction)
+	router.POST("/:id/buyout", rc.authorizationMiddleware.VerifyToken, rc.fractionController.BuyoutFraction)
 	router.GET("/:id", rc.fractionController.GetFractionData)
 	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.fractionController.UpdateFraction)
 	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.fractionController.DeleteFraction)
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	moderationController    controllers.ModerationController
+}
+
+func NewModerationRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, moderationController controllers.ModerationController) ModerationRoutes {
+	return ModerationRoutes{authorizationMiddleware, moderationController}
+}
+
+func (rc *ModerationRoutes) ModerationRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/moderation")
+
+	router.POST("/report", rc.authorizationMiddleware.VerifyToken, rc.moderationController.InsertReport)
+	router.GET("/report", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.moderationController.GetReportList)
+	router.PUT("/report/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.moderationController.UpdateReport)
+	router.GET("/action", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.moderationController.GetModerationActionList)
+	router.PUT("/:type/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.moderationController.UpdateModerationStatus)
+}
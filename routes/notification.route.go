@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	notificationController  controllers.NotificationController
+}
+
+func NewNotificationRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, notificationController controllers.NotificationController) NotificationRoutes {
+	return NotificationRoutes{authorizationMiddleware, notificationController}
+}
+
+func (rc *NotificationRoutes) NotificationRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/notification")
+
+	router.GET("/preference", rc.authorizationMiddleware.VerifyToken, rc.notificationController.GetNotificationPreferenceList)
+	router.PUT("/preference", rc.authorizationMiddleware.VerifyToken, rc.notificationController.UpdateNotificationPreference)
+	router.PUT("/read", rc.authorizationMiddleware.VerifyToken, rc.notificationController.ReadAllNotifications)
+	router.PUT("/:id/read", rc.authorizationMiddleware.VerifyToken, rc.notificationController.ReadNotification)
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.notificationController.GetNotificationList)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RankingRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	rankingController       controllers.RankingController
+}
+
+func NewRankingRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, rankingController controllers.RankingController) RankingRoutes {
+	return RankingRoutes{authorizationMiddleware, rankingController}
+}
+
+func (rc *RankingRoutes) RankingRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/rankings")
+
+	router.GET("/:type", rc.rankingController.GetRankingList)
+}
@@ -19,6 +19,11 @@ func NewRentalRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware
 func (rc *RentalRoutes) RentalRoute(rg *gin.RouterGroup) {
 
 	router := rg.Group("/rental")
+	router.GET("/event", rc.authorizationMiddleware.VerifyToken, rc.rentalController.GetRentalEventList)
+	router.GET("/refund", rc.authorizationMiddleware.VerifyToken, rc.rentalController.GetRentalRefundList)
+	router.POST("/:id/extend", rc.authorizationMiddleware.VerifyToken, rc.rentalController.ExtendRental)
+	router.POST("/:id/return", rc.authorizationMiddleware.VerifyToken, rc.rentalController.ReturnRental)
+	router.POST("/:id/cancel", rc.authorizationMiddleware.VerifyToken, rc.rentalController.CancelRental)
 	router.GET("/:id", rc.rentalController.GetRentalData)
 	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.rentalController.UpdateRental)
 	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.rentalController.DeleteRental)
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatisticRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	statisticController     controllers.StatisticController
+}
+
+func NewStatisticRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, statisticController controllers.StatisticController) StatisticRoutes {
+	return StatisticRoutes{authorizationMiddleware, statisticController}
+}
+
+func (rc *StatisticRoutes) StatisticRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/statistic")
+
+	router.GET("/discrepancy", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.statisticController.GetStatisticDiscrepancyList)
+	router.POST("/reconcile", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.statisticController.ReconcileStatistics)
+}
@@ -21,9 +21,12 @@ func (rc *TokenRoutes) TokenRoute(rg *gin.RouterGroup) {
 	router := rg.Group("/token")
 
 	router.GET("/:id/transaction", rc.tokenController.GetTokenTransactionList)
-	router.GET("/:id", rc.tokenController.GetTokenData)
+	router.GET("/:id/price-history", rc.tokenController.GetTokenPriceHistory)
+	router.GET("/:id/voucher", rc.tokenController.GetMintVoucher)
+	router.POST("/:id/voucher", rc.authorizationMiddleware.VerifyToken, rc.tokenController.InsertMintVoucher)
+	router.GET("/:id", rc.authorizationMiddleware.VerifyOptionalToken, rc.tokenController.GetTokenData)
 	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.tokenController.UpdateToken)
-	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.tokenController.DeleteToken)
+	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.tokenController.DeleteToken)
 	router.POST("/", rc.authorizationMiddleware.VerifyToken, rc.tokenController.InsertToken)
 	router.GET("/", rc.tokenController.GetTokenList)
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	trashController         controllers.TrashController
+}
+
+func NewTrashRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, trashController controllers.TrashController) TrashRoutes {
+	return TrashRoutes{authorizationMiddleware, trashController}
+}
+
+func (rc *TrashRoutes) TrashRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/trash")
+
+	router.GET("/:type", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.trashController.GetDeletedList)
+	router.POST("/:type/:id/restore", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyAdmin, rc.trashController.Restore)
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrendingRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	trendingController      controllers.TrendingController
+}
+
+func NewTrendingRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, trendingController controllers.TrendingController) TrendingRoutes {
+	return TrendingRoutes{authorizationMiddleware, trendingController}
+}
+
+func (rc *TrendingRoutes) TrendingRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/trending")
+
+	router.GET("/:type", rc.trendingController.GetTrendingList)
+}
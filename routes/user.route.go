@@ -20,6 +20,11 @@ func (rc *UserRoutes) UserRoute(rg *gin.RouterGroup) {
 
 	router := rg.Group("/user")
 	router.GET("/me", rc.authorizationMiddleware.VerifyToken, rc.userController.GetMyUserData)
-	router.GET("/:id", rc.userController.GetUserData)
+	router.POST("/me/email/verification", rc.authorizationMiddleware.VerifyToken, rc.userController.SendEmailVerification)
+	router.GET("/me/address", rc.authorizationMiddleware.VerifyToken, rc.userController.GetMyAddressList)
+	router.POST("/me/address", rc.authorizationMiddleware.VerifyToken, rc.userController.LinkAddress)
+	router.DELETE("/me/address/:address", rc.authorizationMiddleware.VerifyToken, rc.userController.UnlinkAddress)
+	router.GET("/verify-email", rc.userController.VerifyEmail)
+	router.GET("/:id", rc.authorizationMiddleware.VerifyOptionalToken, rc.userController.GetUserData)
 	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.userController.UpdateUser)
 }
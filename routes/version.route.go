@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"fmt"
+
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/helpers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VersionRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	versionController       controllers.VersionController
+}
+
+func NewVersionRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, versionController controllers.VersionController) VersionRoutes {
+	return VersionRoutes{authorizationMiddleware, versionController}
+}
+
+func (rc *VersionRoutes) VersionRoute(rg *gin.RouterGroup) {
+
+	for resourceType := range helpers.VersionTables {
+		rg.GET(fmt.Sprintf("/%s/:id/history", resourceType), rc.authorizationMiddleware.VerifyOptionalToken, rc.versionController.GetResourceHistory)
+	}
+}
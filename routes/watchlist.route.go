@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WatchlistRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	watchlistController     controllers.WatchlistController
+}
+
+func NewWatchlistRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, watchlistController controllers.WatchlistController) WatchlistRoutes {
+	return WatchlistRoutes{authorizationMiddleware, watchlistController}
+}
+
+func (rc *WatchlistRoutes) WatchlistRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/watchlist")
+
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.watchlistController.GetWatchlistList)
+	router.POST("/", rc.authorizationMiddleware.VerifyToken, rc.watchlistController.UpsertWatchlist)
+	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.watchlistController.DeleteWatchlist)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"metaedu-marketplace/controllers"
+	"metaedu-marketplace/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookRoutes struct {
+	authorizationMiddleware middlewares.AuthorizationMiddleware
+	webhookController       controllers.WebhookController
+}
+
+func NewWebhookRoutes(authorizationMiddleware middlewares.AuthorizationMiddleware, webhookController controllers.WebhookController) WebhookRoutes {
+	return WebhookRoutes{authorizationMiddleware, webhookController}
+}
+
+func (rc *WebhookRoutes) WebhookRoute(rg *gin.RouterGroup) {
+
+	router := rg.Group("/webhook")
+
+	router.POST("/delivery/:id/replay", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.ReplayWebhookDelivery)
+	router.GET("/:id/delivery", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.GetWebhookDeliveryList)
+	router.PUT("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.UpdateWebhookEndpoint)
+	router.DELETE("/:id", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.DeleteWebhookEndpoint)
+	router.POST("/", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.InsertWebhookEndpoint)
+	router.GET("/", rc.authorizationMiddleware.VerifyToken, rc.authorizationMiddleware.VerifyPartner, rc.webhookController.GetWebhookEndpointList)
+}
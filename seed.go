@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	models "metaedu-marketplace/models"
+)
+
+// Token categories created on an empty marketplace, icons are uploaded later through the API
+var defaultTokenCategories = []models.TokenCategory{
+	{Title: "Courses", Description: "Full courses and learning paths"},
+	{Title: "Lessons", Description: "Single lessons and lectures"},
+	{Title: "Certificates", Description: "Certificates of completion and achievement"},
+	{Title: "Books", Description: "Books, notes and study guides"},
+	{Title: "Art", Description: "Illustrations and other artwork"},
+}
+
+func runSeed(args []string) int {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	file := flags.String("file", "", "JSON file with the token categories to insert instead of the defaults")
+	flags.Parse(args)
+
+	tokenCategories := defaultTokenCategories
+
+	if *file != "" {
+		content, err := os.ReadFile(*file)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+
+		tokenCategories = nil
+
+		err = json.Unmarshal(content, &tokenCategories)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	}
+
+	ctx := context.Background()
+	tokenCategoryRepository := newApp("seed").Repositories.TokenCategory
+
+	existingCategories, err := tokenCategoryRepository.GetTokenCategoryList(ctx, 0, 100000, "", nil, "created_at", "ASC")
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	existingTitles := map[string]bool{}
+
+	for _, existingCategory := range existingCategories {
+		existingTitles[existingCategory.Title] = true
+	}
+
+	// Seeding twice only inserts what is missing
+	for _, tokenCategory := range tokenCategories {
+		if existingTitles[tokenCategory.Title] {
+			continue
+		}
+
+		now := sql.NullTime{Time: time.Now(), Valid: true}
+
+		tokenCategory.Status = "active"
+		tokenCategory.UpdatedAt = now
+		tokenCategory.CreatedAt = now
+
+		id, err := tokenCategoryRepository.InsertTokenCategory(ctx, tokenCategory)
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+
+		existingTitles[tokenCategory.Title] = true
+
+		fmt.Println("Inserted token category", tokenCategory.Title, id)
+	}
+
+	return 0
+}
@@ -0,0 +1,40 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"strings"
+)
+
+//go:embed email/*.html
+var emailFS embed.FS
+
+type EmailData struct {
+	Name  string
+	Title string
+	Body  string
+	Link  string
+}
+
+// RenderEmail renders the subject and the HTML body of a notification email
+func RenderEmail(notificationType string, data EmailData) (string, string, error) {
+	tmpl, err := template.ParseFS(emailFS, "email/layout.html", "email/"+notificationType+".html")
+	if err != nil {
+		return "", "", err
+	}
+
+	var subject bytes.Buffer
+	err = tmpl.ExecuteTemplate(&subject, "subject", data)
+	if err != nil {
+		return "", "", err
+	}
+
+	var body bytes.Buffer
+	err = tmpl.ExecuteTemplate(&body, "layout", data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return strings.TrimSpace(subject.String()), body.String(), nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"metaedu-marketplace/helpers"
+)
+
+const userUsage = `Usage: metaedu-marketplace user <command>
+
+Commands:
+  promote-admin <address>     give the user linked to the address the admin role
+  promote-partner <address>   give the user linked to the address the partner role, partners can register webhooks`
+
+var promotionRoles = map[string]string{
+	"promote-admin":   "admin",
+	"promote-partner": "partner",
+}
+
+func runUser(args []string) int {
+	if len(args) == 0 || promotionRoles[args[0]] == "" {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+
+	role := promotionRoles[args[0]]
+
+	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+	flags.Parse(args[1:])
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, userUsage)
+		return 2
+	}
+
+	address := flags.Arg(0)
+	ctx := context.Background()
+	userRepository := newApp("user").Repositories.User
+
+	user := userRepository.GetUserByAddress(ctx, address)
+
+	if user.ID == helpers.GetEmptyUUID() {
+		fmt.Fprintln(os.Stderr, "No user is linked to", address)
+		return 1
+	}
+
+	if user.Role == role {
+		fmt.Println("User", user.ID, "is already a", role)
+		return 0
+	}
+
+	_, err := userRepository.UpdateUserRole(ctx, user.ID, role)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	fmt.Println("Promoted user", user.ID, "to", role)
+
+	return 0
+}
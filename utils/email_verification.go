@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidVerificationToken = errors.New("verification token is not valid or has expired")
+
+type EmailVerifier struct {
+	secret   []byte
+	baseUrl  string
+	duration time.Duration
+}
+
+func NewEmailVerifier(secret string, baseUrl string, duration time.Duration) *EmailVerifier {
+	return &EmailVerifier{[]byte("email-verification:" + secret), strings.TrimRight(baseUrl, "/"), duration}
+}
+
+// CreateLink returns a link carrying the user, the email and the expiry, signed with HMAC-SHA256
+func (v *EmailVerifier) CreateLink(userID uuid.UUID, email string) string {
+	payload := fmt.Sprintf("%s|%s|%d", userID.String(), email, time.Now().Add(v.duration).Unix())
+	encodedPayload := base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	return fmt.Sprintf("%s/api/v1/user/verify-email?token=%s.%s", v.baseUrl, encodedPayload, v.sign(encodedPayload))
+}
+
+func (v *EmailVerifier) Verify(token string) (uuid.UUID, string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(v.sign(parts[0]))) {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	fields := strings.Split(string(payload), "|")
+	if len(fields) != 3 {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	userID, err := uuid.Parse(fields[0])
+	if err != nil {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	expiresAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return uuid.Nil, "", ErrInvalidVerificationToken
+	}
+
+	return userID, fields[1], nil
+}
+
+func (v *EmailVerifier) sign(payload string) string {
+	mac := hmac.New(sha256.New, v.secret)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
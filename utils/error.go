@@ -3,10 +3,11 @@ package utils
 import "errors"
 
 var (
-	ErrUserNotExists  = errors.New("user does not exist")
-	ErrUserExists     = errors.New("user already exists")
-	ErrInvalidAddress = errors.New("invalid address")
-	ErrInvalidNonce   = errors.New("invalid nonce")
-	ErrMissingSig     = errors.New("signature is missing")
-	ErrAuthError      = errors.New("authentication error")
+	ErrUserNotExists    = errors.New("user does not exist")
+	ErrUserExists       = errors.New("user already exists")
+	ErrInvalidAddress   = errors.New("invalid address")
+	ErrInvalidNonce     = errors.New("invalid nonce")
+	ErrMissingSig       = errors.New("signature is missing")
+	ErrAuthError        = errors.New("authentication error")
+	ErrInvalidSignature = errors.New("invalid signature")
 )
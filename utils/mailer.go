@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+type SmtpMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSmtpMailer(host string, port string, username string, password string, from string) *SmtpMailer {
+	return &SmtpMailer{host, port, username, password, from}
+}
+
+// Send delivers an HTML email, authentication is skipped when no username is set
+func (m *SmtpMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	headers := []string{
+		fmt.Sprintf("From: %s", m.from),
+		fmt.Sprintf("To: %s", to),
+		fmt.Sprintf("Subject: %s", subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/html; charset=\"UTF-8\"",
+	}
+
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	return smtp.SendMail(m.host+":"+m.port, auth, m.from, []string{to}, []byte(message))
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/google/uuid"
+)
+
+const (
+	ViewTypeToken      = "token"
+	ViewTypeCollection = "collection"
+)
+
+// ViewTracker counts unique viewers per window with HyperLogLog and buffers the counts until they are flushed
+type ViewTracker struct {
+	redisClient *redis.Client
+	window      time.Duration
+}
+
+func NewViewTracker(redisClient *redis.Client, window time.Duration) *ViewTracker {
+	return &ViewTracker{redisClient, window}
+}
+
+// GetViewBucket numbers the window the time falls in, windows shorter than a second stay valid
+func GetViewBucket(now time.Time, window time.Duration) int64 {
+	if window <= 0 {
+		return 0
+	}
+
+	return now.UnixNano() / int64(window)
+}
+
+// Track records a view and returns whether the viewer is new in the current window
+func (v *ViewTracker) Track(viewType string, id uuid.UUID, viewer string) (bool, error) {
+	bucket := GetViewBucket(time.Now(), v.window)
+	hllKey := fmt.Sprintf("view-hll-%s-%s-%d", viewType, id, bucket)
+
+	isNew, err := v.redisClient.PFAdd(hllKey, viewer).Result()
+
+	if err != nil {
+		return false, err
+	}
+
+	if isNew == 0 {
+		return false, nil
+	}
+
+	pipeline := v.redisClient.TxPipeline()
+	pipeline.Expire(hllKey, 2*v.window)
+	pipeline.HIncrBy(fmt.Sprintf("view-pending-%s", viewType), id.String(), 1)
+	_, err = pipeline.Exec()
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Drain takes the buffered view counts of a type, the counts are removed from the buffer
+func (v *ViewTracker) Drain(viewType string) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int)
+
+	pendingKey := fmt.Sprintf("view-pending-%s", viewType)
+	drainingKey := fmt.Sprintf("view-draining-%s", viewType)
+
+	// A leftover draining hash means the previous flush failed, it is retried before new views
+	isDraining, err := v.redisClient.Exists(drainingKey).Result()
+
+	if err != nil {
+		return counts, err
+	}
+
+	if isDraining == 0 {
+		err = v.redisClient.Rename(pendingKey, drainingKey).Err()
+
+		if err != nil {
+			if err.Error() == "ERR no such key" {
+				return counts, nil
+			}
+
+			return counts, err
+		}
+	}
+
+	values, err := v.redisClient.HGetAll(drainingKey).Result()
+
+	if err != nil {
+		return counts, err
+	}
+
+	for key, value := range values {
+		id, err := uuid.Parse(key)
+
+		if err != nil {
+			continue
+		}
+
+		count, err := strconv.Atoi(value)
+
+		if err != nil {
+			continue
+		}
+
+		counts[id] = count
+	}
+
+	return counts, nil
+}
+
+// Ack removes the drained counts once they are stored
+func (v *ViewTracker) Ack(viewType string) error {
+	return v.redisClient.Del(fmt.Sprintf("view-draining-%s", viewType)).Err()
+}
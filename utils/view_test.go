@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetViewBucket(t *testing.T) {
+	now := time.Unix(3600, 0)
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		window time.Duration
+		want   int64
+	}{
+		{name: "thirty minutes", now: now, window: 30 * time.Minute, want: 2},
+		{name: "last nanosecond of a window", now: now.Add(-time.Nanosecond), window: 30 * time.Minute, want: 1},
+		{name: "sub second window", now: now, window: 500 * time.Millisecond, want: 7200},
+		{name: "zero window", now: now, window: 0, want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GetViewBucket(test.now, test.window); got != test.want {
+				t.Errorf("GetViewBucket() = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+type MintVoucherMessage struct {
+	TokenIndex int
+	Uri        string
+	Supply     int
+	Price      *big.Int
+	Royalty    int
+	Nonce      string
+}
+
+type VoucherVerifier struct {
+	chainID         int64
+	contractAddress string
+}
+
+func NewVoucherVerifier(chainID int64, contractAddress string) *VoucherVerifier {
+	return &VoucherVerifier{chainID, contractAddress}
+}
+
+// Verify checks that the EIP-712 mint voucher was signed by the given address
+func (v *VoucherVerifier) Verify(address string, voucher MintVoucherMessage, sigHex string) error {
+	sig, err := hexutil.Decode(sigHex)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return ErrInvalidSignature
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"MintVoucher": []apitypes.Type{
+				{Name: "tokenIndex", Type: "uint256"},
+				{Name: "uri", Type: "string"},
+				{Name: "supply", Type: "uint256"},
+				{Name: "price", Type: "uint256"},
+				{Name: "royalty", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "MintVoucher",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "MetaEduMarketplace",
+			Version:           "1",
+			ChainId:           math.NewHexOrDecimal256(v.chainID),
+			VerifyingContract: common.HexToAddress(v.contractAddress).Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"tokenIndex": strconv.Itoa(voucher.TokenIndex),
+			"uri":        voucher.Uri,
+			"supply":     strconv.Itoa(voucher.Supply),
+			"price":      voucher.Price.String(),
+			"royalty":    strconv.Itoa(voucher.Royalty),
+			"nonce":      voucher.Nonce,
+		},
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return err
+	}
+
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	// No key recovers from a malformed signature
+	recovered, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	recoveredAddr := crypto.PubkeyToAddress(*recovered)
+
+	if strings.ToLower(address) != strings.ToLower(recoveredAddr.Hex()) {
+		return ErrAuthError
+	}
+
+	return nil
+}
+
+// ToWei converts an ether amount to wei
+func ToWei(amount float64) *big.Int {
+	value, _ := new(big.Float).SetPrec(256).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	value.Mul(value, big.NewFloat(1e18))
+
+	wei, _ := value.Int(nil)
+
+	return wei
+}
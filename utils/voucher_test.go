@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+// Signed with eth_signTypedData_v4 by the first default Hardhat account on chain 31337
+const (
+	voucherSigner    = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	voucherContract  = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	voucherSignature = "0xf7fa15ee458e43417485ba62b56028955c83efeda776f0c69a70e0193a6e69d916fca2da6777a12393e86bb8148ee908c7e38bb7508accecd7912f29139dec201c"
+)
+
+func signedVoucher() MintVoucherMessage {
+	return MintVoucherMessage{
+		TokenIndex: 7,
+		Uri:        "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi/metadata.json",
+		Supply:     10,
+		Price:      ToWei(1.5),
+		Royalty:    500,
+		Nonce:      "42",
+	}
+}
+
+func TestVoucherVerifierVerify(t *testing.T) {
+	tampered := func(change func(voucher *MintVoucherMessage)) MintVoucherMessage {
+		voucher := signedVoucher()
+		change(&voucher)
+		return voucher
+	}
+
+	tests := []struct {
+		name      string
+		chainID   int64
+		address   string
+		voucher   MintVoucherMessage
+		signature string
+		wantErr   error
+	}{
+		{name: "valid", chainID: 31337, address: voucherSigner, voucher: signedVoucher(), signature: voucherSignature},
+		{name: "address case is ignored", chainID: 31337, address: "0xF39FD6E51AAD88F6F4CE6AB8827279CFFFB92266", voucher: signedVoucher(), signature: voucherSignature},
+		{name: "tampered price", chainID: 31337, address: voucherSigner, voucher: tampered(func(v *MintVoucherMessage) { v.Price = big.NewInt(1) }), signature: voucherSignature, wantErr: ErrAuthError},
+		{name: "tampered royalty", chainID: 31337, address: voucherSigner, voucher: tampered(func(v *MintVoucherMessage) { v.Royalty = 1000 }), signature: voucherSignature, wantErr: ErrAuthError},
+		{name: "tampered nonce", chainID: 31337, address: voucherSigner, voucher: tampered(func(v *MintVoucherMessage) { v.Nonce = "43" }), signature: voucherSignature, wantErr: ErrAuthError},
+		{name: "other chain", chainID: 1, address: voucherSigner, voucher: signedVoucher(), signature: voucherSignature, wantErr: ErrAuthError},
+		{name: "other signer", chainID: 31337, address: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", voucher: signedVoucher(), signature: voucherSignature, wantErr: ErrAuthError},
+		{name: "tampered signature", chainID: 31337, address: voucherSigner, voucher: signedVoucher(), signature: "0xf7fa15ee458e43417485ba62b56028955c83efeda776f0c69a70e0193a6e69d916fca2da6777a12393e86bb8148ee908c7e38bb7508accecd7912f29139dec211c", wantErr: ErrAuthError},
+		{name: "short signature", chainID: 31337, address: voucherSigner, voucher: signedVoucher(), signature: "0x1234", wantErr: ErrInvalidSignature},
+		{name: "not hex", chainID: 31337, address: voucherSigner, voucher: signedVoucher(), signature: "signature", wantErr: ErrInvalidSignature},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := NewVoucherVerifier(test.chainID, voucherContract).Verify(test.address, test.voucher, test.signature)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestToWei(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{amount: 1.5, want: "1500000000000000000"},
+		{amount: 0.1, want: "100000000000000000"},
+		{amount: 0, want: "0"},
+	}
+
+	for _, test := range tests {
+		if got := ToWei(test.amount).String(); got != test.want {
+			t.Errorf("ToWei(%v) = %s, want %s", test.amount, got, test.want)
+		}
+	}
+}
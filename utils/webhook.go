@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"metaedu-marketplace/helpers"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+func GetWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// SignWebhookPayload signs "timestamp.payload" with HMAC-SHA256 so receivers can reject replays
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%d.", timestamp)))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateWebhookUrl accepts http(s) urls whose host only resolves to public addresses
+func ValidateWebhookUrl(rawUrl string) error {
+	parsedUrl, err := url.ParseRequestURI(rawUrl)
+
+	if err != nil || (parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https") || parsedUrl.Hostname() == "" {
+		return fmt.Errorf("Url is not valid")
+	}
+
+	ips, err := net.LookupIP(parsedUrl.Hostname())
+
+	if err != nil || len(ips) == 0 {
+		return fmt.Errorf("Url host can not be resolved")
+	}
+
+	for _, ip := range ips {
+		if !helpers.IsPublicIP(ip) {
+			return fmt.Errorf("Url must point to a public address")
+		}
+	}
+
+	return nil
+}
+
+// NewWebhookClient checks every address it dials, so a host that later resolves to an internal address is still refused
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network string, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+
+			if err != nil {
+				return err
+			}
+
+			if !helpers.IsPublicIP(net.ParseIP(host)) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// A redirect is reported as the response instead of being followed to another host
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package utils
+
+import "testing"
+
+func TestSignWebhookPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		payload   string
+		want      string
+	}{
+		{
+			name:      "payload",
+			secret:    "whsec_test",
+			timestamp: 1700000000,
+			payload:   `{"event":"token.minted"}`,
+			want:      "41e65bbfacafcb53e32976fc5d2ae77090c4c4355df8b9c9edccb1c4c8086f3c",
+		},
+		{
+			name: "empty secret and payload",
+			want: "b849d5a581847b281957065739df36df2463d1977ea8d6e1e4e6cf33fadc68c3",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := SignWebhookPayload(test.secret, test.timestamp, []byte(test.payload)); got != test.want {
+				t.Errorf("SignWebhookPayload() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayloadCoversEveryInput(t *testing.T) {
+	signature := SignWebhookPayload("whsec_test", 1700000000, []byte(`{"event":"token.minted"}`))
+
+	changed := []string{
+		SignWebhookPayload("whsec_other", 1700000000, []byte(`{"event":"token.minted"}`)),
+		SignWebhookPayload("whsec_test", 1700000001, []byte(`{"event":"token.minted"}`)),
+		SignWebhookPayload("whsec_test", 1700000000, []byte(`{"event":"rental.started"}`)),
+	}
+
+	for i, other := range changed {
+		if other == signature {
+			t.Errorf("changed input %d kept the signature", i)
+		}
+	}
+}
+
+func TestValidateWebhookUrl(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "http://127.0.0.1/hook", wantErr: true},
+		{url: "http://10.0.0.8/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://[::1]/hook", wantErr: true},
+		{url: "ftp://93.184.216.34/hook", wantErr: true},
+		{url: "not a url", wantErr: true},
+		{url: "https://93.184.216.34/hook", wantErr: false},
+	}
+
+	for _, test := range tests {
+		err := ValidateWebhookUrl(test.url)
+
+		if (err != nil) != test.wantErr {
+			t.Errorf("ValidateWebhookUrl(%q) error = %v, want error %v", test.url, err, test.wantErr)
+		}
+	}
+}
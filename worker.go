@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"metaedu-marketplace/app"
+	"metaedu-marketplace/config"
+	"metaedu-marketplace/cronjobs"
+)
+
+func runWorker(args []string) int {
+	flags := flag.NewFlagSet("worker", flag.ExitOnError)
+	flags.String("rpc-url", "", "blockchain RPC endpoint, overrides RPC_URL")
+	flags.Parse(args)
+
+	config.ApplyFlags(flags, map[string]string{"rpc-url": "RPC_URL"})
+
+	application := newApp("worker")
+
+	// The worker takes no traffic, so there is no readiness to drain
+	lifecycle := app.NewLifecycle(application.Config.App.ShutdownTimeout, 0)
+
+	lifecycle.OnStop("redis and postgres", func(ctx context.Context) error {
+		return application.Close()
+	})
+
+	cronjobs.Setup(application)
+
+	err := cronjobs.Start(application.Config.Chain.RPCURL)
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		application.Close()
+		return 1
+	}
+
+	lifecycle.OnStop("jobs, leadership and blockchain client", cronjobs.Stop)
+
+	if err := lifecycle.Wait(cronjobs.Failed()); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	return 0
+}